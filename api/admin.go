@@ -0,0 +1,79 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/jinzhu/gorm"
+)
+
+// handleGetDeadLetters lists the images that failed inspection too many times
+func handleGetDeadLetters(w http.ResponseWriter, r *http.Request) {
+	pageNum, err := strconv.Atoi(mux.Vars(r)["page"])
+	if err != nil || pageNum < 1 {
+		pageNum = 1
+	}
+
+	deadLetters := db.GetDeadLetters(pageNum)
+
+	bytes, err := json.Marshal(deadLetters)
+	if err != nil {
+		log.Errorf("Error: %v", err)
+	}
+
+	w.Write([]byte(bytes))
+}
+
+// handlePurgeDeadLetters removes all the dead letters without inspecting them again
+func handlePurgeDeadLetters(w http.ResponseWriter, r *http.Request) {
+	count, err := db.PurgeDeadLetters()
+	if err != nil {
+		log.Errorf("Failed to purge dead letters: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Infof("Purged %d dead letters", count)
+	w.Write([]byte(`{"Purged":` + strconv.FormatInt(count, 10) + `}`))
+}
+
+// handleDeadLetter retries (POST) or purges (DELETE) a dead-lettered image
+func handleDeadLetter(w http.ResponseWriter, r *http.Request) {
+	ok, image, _ := getImageNameVars(r)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(constStatusNotFound))
+		return
+	}
+
+	err := db.PurgeDeadLetter(image)
+	if err == gorm.ErrRecordNotFound {
+		log.Debugf("Image %s is not dead-lettered", image)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(constStatusNotFound))
+		return
+	}
+
+	if err != nil {
+		log.Errorf("Failed to purge dead letter %s: %v", image, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if r.Method == "POST" {
+		err = inspectNewImage(image)
+		if err != nil {
+			log.Errorf("Failed to retry dead letter %s: %v", image, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		log.Infof("Retrying dead letter %s", image)
+	} else {
+		log.Infof("Purged dead letter %s", image)
+	}
+
+	w.Write([]byte(`{}`))
+}
@@ -0,0 +1,98 @@
+// +build dbrequired
+
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/microscaling/microbadger/database"
+	"github.com/microscaling/microbadger/queue"
+)
+
+func TestDeadLetters(t *testing.T) {
+	db = getDatabase(t)
+	emptyDatabase(db)
+	addThings(db)
+
+	os.Setenv("MB_API_USER", "admin")
+	os.Setenv("MB_API_PASSWORD", "secret")
+	defer os.Unsetenv("MB_API_USER")
+	defer os.Unsetenv("MB_API_PASSWORD")
+
+	qs = queue.NewMockService()
+	ts := httptest.NewServer(muxRoutes())
+	defer ts.Close()
+
+	db.DeadLetterImage(database.InspectionFailure{ImageName: "lizrice/childimage", Attempts: 5, LastError: "timeout"})
+	db.DeadLetterImage(database.InspectionFailure{ImageName: "lizrice/featured", Attempts: 5, LastError: "timeout"})
+
+	type test struct {
+		method  string
+		url     string
+		noAuth  bool
+		status  int
+		body    string
+		dlCount int
+	}
+
+	var tests = []test{
+		{method: "GET", url: `/v1/admin/dead-letters/`, noAuth: true, status: 401, dlCount: 2},
+		{method: "DELETE", url: `/v1/admin/dead-letters/`, noAuth: true, status: 401, dlCount: 2},
+		{method: "GET", url: `/v1/admin/dead-letters/`, status: 200, body: `"DeadLetterCount":2`, dlCount: 2},
+		{method: "GET", url: `/v1/admin/dead-letters/?page=2`, status: 200, body: `{"CurrentPage":2,"PageCount":1,"DeadLetterCount":2}`, dlCount: 2},
+		{method: "POST", url: `/v1/admin/dead-letters/lizrice/childimage/retry`, status: 200, body: `{}`, dlCount: 1},
+		{method: "POST", url: `/v1/admin/dead-letters/lizrice/childimage/retry`, status: 404, dlCount: 1},
+		{method: "DELETE", url: `/v1/admin/dead-letters/lizrice/blah`, status: 404, dlCount: 1},
+		{method: "DELETE", url: `/v1/admin/dead-letters/lizrice/featured`, status: 200, body: `{}`, dlCount: 0},
+		{method: "DELETE", url: `/v1/admin/dead-letters/`, status: 200, body: `{"Purged":0}`, dlCount: 0},
+	}
+
+	for id, test := range tests {
+		req, err := http.NewRequest(test.method, ts.URL+test.url, nil)
+		if err != nil {
+			t.Fatalf("#%d Failed to create request: %v", id, err)
+		}
+
+		if !test.noAuth {
+			req.SetBasicAuth("admin", "secret")
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("#%d Failed to send request %v", id, err)
+		}
+
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			t.Errorf("#%d Error getting body. %v", id, err)
+		}
+
+		if res.StatusCode != test.status {
+			t.Errorf("#%d Unexpected status code %d", id, res.StatusCode)
+		}
+
+		if !strings.Contains(string(body), test.body) {
+			t.Errorf("#%d Body is not as expected, have %s", id, body)
+		}
+
+		dl := db.GetDeadLetters(1)
+		if dl.DeadLetterCount != test.dlCount {
+			t.Errorf("#%d Expected %d dead letters, have %d", id, test.dlCount, dl.DeadLetterCount)
+		}
+	}
+
+	img, err := db.GetImage("lizrice/childimage")
+	if err != nil {
+		t.Fatalf("Error getting image: %v", err)
+	}
+
+	if img.Status != "SUBMITTED" || img.StatusReason != "" {
+		t.Errorf("Retried image has status %s reason %s", img.Status, img.StatusReason)
+	}
+}
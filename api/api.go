@@ -4,7 +4,9 @@ import (
 	"context"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/gorilla/sessions"
@@ -25,15 +27,38 @@ import (
 
 const (
 	constHealthCheckMessage     = "HEALTH OK"
-	constEllipsis               = "\u2026"
 	constRefreshParam           = "refresh"
 	constStatusNotFound         = "404 page not found"
 	constStatusMethodNotAllowed = "405 method not allowed"
+	constShutdownTimeout        = 30 * time.Second
 
 	gaProperty = "UA-62914780-6"
 	gaHost     = "http://microbadger.com"
 
 	imageURL = "hub.docker.com"
+
+	// Images in registries other than the Docker Hub start with the registry host,
+	// which must include a . or : to distinguish it from the namespace.
+	hostVar = "{host:[^/]+[.:][^/]+}"
+
+	// Versions to compare are separated by ... as in git
+	diffVars = "{from:[^/]+?}...{to}"
+
+	// Badges can be requested with an extension for the format
+	badgeFormatVar = "{format:svg|png|json}"
+
+	// Badges can be cached by clients and proxies for this many seconds
+	constDefaultBadgeMaxAge = 300
+
+	// Number of rendered badges we keep in memory
+	constDefaultBadgeCacheSize = 10000
+
+	// Parents of image versions are cached for this long
+	constParentCacheTTL = time.Hour
+
+	// Pulls and stars change without the image changing, so these badges are cached for
+	// less time
+	constHubCountBadgeMaxAge = 60 * time.Second
 )
 
 var (
@@ -47,16 +72,35 @@ var (
 	refreshCodeValue string
 	sessionStore     sessions.Store
 	webhookURL       string
+	badgeMaxAge      time.Duration
+	badges           *badgeCache
+	parents          *parentCache
 )
 
 func init() {
 	refreshCodeValue = os.Getenv("MB_REFRESH_CODE")
+
+	maxAge, err := strconv.Atoi(utils.GetEnvOrDefault("MB_BADGE_MAX_AGE", strconv.Itoa(constDefaultBadgeMaxAge)))
+	if err != nil || maxAge < 0 {
+		log.Errorf("Invalid badge max age %s, using %d", os.Getenv("MB_BADGE_MAX_AGE"), constDefaultBadgeMaxAge)
+		maxAge = constDefaultBadgeMaxAge
+	}
+	badgeMaxAge = time.Duration(maxAge) * time.Second
+
+	cacheSize, err := strconv.Atoi(utils.GetEnvOrDefault("MB_BADGE_CACHE_SIZE", strconv.Itoa(constDefaultBadgeCacheSize)))
+	if err != nil || cacheSize < 0 {
+		log.Errorf("Invalid badge cache size %s, using %d", os.Getenv("MB_BADGE_CACHE_SIZE"), constDefaultBadgeCacheSize)
+		cacheSize = constDefaultBadgeCacheSize
+	}
+	badges = newBadgeCache(cacheSize, badgeMaxAge)
+	parents = newParentCache(cacheSize, constParentCacheTTL)
 }
 
 func muxRoutes() *mux.Router {
 	r := mux.NewRouter()
 
 	r.HandleFunc("/healthcheck.txt", handleHealthCheck).Methods("GET")
+	r.HandleFunc("/images/"+hostVar+"/{org}/{image}/{authToken}", handleImageWebhook).Methods("POST")
 	r.HandleFunc("/images/{org}/{image}/{authToken}", handleImageWebhook).Methods("POST")
 	r.HandleFunc("/images/{image}/{authToken}", handleImageWebhook).Methods("POST")
 
@@ -65,22 +109,37 @@ func muxRoutes() *mux.Router {
 
 	// Badge image routes
 	br := mux.NewRouter().PathPrefix("/badges").Subrouter().StrictSlash(true)
-	br.HandleFunc("/{badgeType}/{org}/{image}:{tag}.svg", handleGetImageBadge).Methods("GET")
-	br.HandleFunc("/{badgeType}/{image}:{tag}.svg", handleGetImageBadge).Methods("GET")
-	br.HandleFunc("/{badgeType}/{org}/{image}.svg", handleGetImageBadge).Methods("GET")
-	br.HandleFunc("/{badgeType}/{image}.svg", handleGetImageBadge).Methods("GET")
+	// Without an extension the format comes from the Accept header
+	for _, ext := range []string{"." + badgeFormatVar, ""} {
+		br.HandleFunc("/{badgeType}/"+hostVar+"/{org}/{image}:{tag}"+ext, handleGetImageBadge).Methods("GET")
+		br.HandleFunc("/{badgeType}/"+hostVar+"/{org}/{image}"+ext, handleGetImageBadge).Methods("GET")
+		br.HandleFunc("/{badgeType}/{org}/{image}:{tag}"+ext, handleGetImageBadge).Methods("GET")
+		br.HandleFunc("/{badgeType}/{image}:{tag}"+ext, handleGetImageBadge).Methods("GET")
+		br.HandleFunc("/{badgeType}/{org}/{image}"+ext, handleGetImageBadge).Methods("GET")
+		br.HandleFunc("/{badgeType}/{image}"+ext, handleGetImageBadge).Methods("GET")
+	}
 
-	r.PathPrefix("/badges").Handler(negroni.New(
-		negroni.HandlerFunc(contentTypeSvgMw),
-		negroni.Wrap(br)))
+	r.PathPrefix("/badges").Handler(br)
 
 	// API routes
 	ar := mux.NewRouter().PathPrefix("/v1").Subrouter().StrictSlash(true)
 	ar.HandleFunc("/badges/counts", handleGetBadgeCounts).Methods("GET")
 	ar.HandleFunc("/images/search/{term}/{term2}", handleImageSearch).Methods("GET")
 	ar.HandleFunc("/images/search/{term}", handleImageSearch).Methods("GET")
+	ar.HandleFunc("/images/"+hostVar+"/{namespace}/{image}/version/{sha}/layers/{digest}/files", handleGetLayerFiles).Methods("GET")
+	ar.HandleFunc("/images/"+hostVar+"/{namespace}/{image}/version/{sha}/dockerfile", handleGetImageVersionDockerfile).Methods("GET")
+	ar.HandleFunc("/images/"+hostVar+"/{namespace}/{image}/version/{sha}", handleGetImageVersion).Methods("GET")
+	ar.HandleFunc("/images/"+hostVar+"/{namespace}/{image}/diff/"+diffVars, handleGetImageVersionDiff).Methods("GET")
+	ar.HandleFunc("/images/"+hostVar+"/{namespace}/{image}:{tag}", handleGetImage).Methods("GET")
+	ar.HandleFunc("/images/"+hostVar+"/{namespace}/{image}", handleGetImage).Methods("GET")
+	ar.HandleFunc("/images/{namespace}/{image}/version/{sha}/layers/{digest}/files", handleGetLayerFiles).Methods("GET")
+	ar.HandleFunc("/images/{namespace}/{image}/version/{sha}/dockerfile", handleGetImageVersionDockerfile).Methods("GET")
 	ar.HandleFunc("/images/{namespace}/{image}/version/{sha}", handleGetImageVersion).Methods("GET")
+	ar.HandleFunc("/images/{namespace}/{image}/diff/"+diffVars, handleGetImageVersionDiff).Methods("GET")
+	ar.HandleFunc("/images/{image}/version/{sha}/layers/{digest}/files", handleGetLayerFiles).Methods("GET")
+	ar.HandleFunc("/images/{image}/version/{sha}/dockerfile", handleGetImageVersionDockerfile).Methods("GET")
 	ar.HandleFunc("/images/{image}/version/{sha}", handleGetImageVersion).Methods("GET")
+	ar.HandleFunc("/images/{image}/diff/"+diffVars, handleGetImageVersionDiff).Methods("GET")
 	ar.HandleFunc("/images/{namespace}/{image}:{tag}", handleGetImage).Methods("GET")
 	ar.HandleFunc("/images/{namespace}/{image}", handleGetImage).Methods("GET")
 	ar.HandleFunc("/images/{image}:{tag}", handleGetImage).Methods("GET")
@@ -110,7 +169,10 @@ func muxRoutes() *mux.Router {
 	pir.HandleFunc("/{registry}/images/{namespace}/{image}", handleUserImagePermissions).Methods("DELETE", "PUT")
 	pir.HandleFunc("/{registry}/images/{namespace}/{image}:{tag}", handleGetImage).Methods("GET")
 	pir.HandleFunc("/{registry}/images/{namespace}/{image}", handleGetImage).Methods("GET")
+	pir.HandleFunc("/{registry}/images/{namespace}/{image}/version/{sha}/layers/{digest}/files", handleGetLayerFiles).Methods("GET")
+	pir.HandleFunc("/{registry}/images/{namespace}/{image}/version/{sha}/dockerfile", handleGetImageVersionDockerfile).Methods("GET")
 	pir.HandleFunc("/{registry}/images/{namespace}/{image}/version/{sha}", handleGetImageVersion).Methods("GET")
+	pir.HandleFunc("/{registry}/images/{namespace}/{image}/diff/"+diffVars, handleGetImageVersionDiff).Methods("GET")
 
 	ar.PathPrefix("/registry").Handler(negroni.New(
 		negroni.HandlerFunc(loginRequiredMw),
@@ -140,6 +202,23 @@ func muxRoutes() *mux.Router {
 		negroni.Wrap(nr),
 	))
 
+	// Admin API requires basic auth
+	adr := mux.NewRouter().PathPrefix("/v1/admin").Subrouter().StrictSlash(true)
+	adr.HandleFunc("/dead-letters/", handleGetDeadLetters).Methods("GET").Queries("page", "{page}")
+	adr.HandleFunc("/dead-letters/", handleGetDeadLetters).Methods("GET")
+	adr.HandleFunc("/dead-letters/", handlePurgeDeadLetters).Methods("DELETE")
+	adr.HandleFunc("/dead-letters/"+hostVar+"/{namespace}/{image}/retry", handleDeadLetter).Methods("POST")
+	adr.HandleFunc("/dead-letters/{namespace}/{image}/retry", handleDeadLetter).Methods("POST")
+	adr.HandleFunc("/dead-letters/{image}/retry", handleDeadLetter).Methods("POST")
+	adr.HandleFunc("/dead-letters/"+hostVar+"/{namespace}/{image}", handleDeadLetter).Methods("DELETE")
+	adr.HandleFunc("/dead-letters/{namespace}/{image}", handleDeadLetter).Methods("DELETE")
+	adr.HandleFunc("/dead-letters/{image}", handleDeadLetter).Methods("DELETE")
+
+	ar.PathPrefix("/admin").Handler(negroni.New(
+		negroni.HandlerFunc(basicAuthRequiredMw),
+		negroni.Wrap(adr),
+	))
+
 	debugCors := os.Getenv("MB_DEBUG_CORS")
 	c := cors.New(cors.Options{
 		AllowedOrigins:   []string{os.Getenv("MB_CORS_ORIGIN")},
@@ -166,11 +245,6 @@ func contentTypeJsMw(w http.ResponseWriter, r *http.Request, next http.HandlerFu
 	next(w, r)
 }
 
-func contentTypeSvgMw(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
-	w.Header().Set("Content-Type", "image/svg+xml")
-	next(w, r)
-}
-
 func loginRequiredMw(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
 	log.Debugf("Checking user is logged in")
 
@@ -204,6 +278,14 @@ func basicAuthRequiredMw(w http.ResponseWriter, r *http.Request, next http.Handl
 
 	user, pass, _ := r.BasicAuth()
 
+	// No credentials are configured, so nobody gets in
+	if os.Getenv("MB_API_USER") == "" {
+		log.Debugf("Basic auth is not configured")
+
+		http.Error(w, "Unauthorized.", 401)
+		return
+	}
+
 	if user != os.Getenv("MB_API_USER") || pass != os.Getenv("MB_API_PASSWORD") {
 		log.Debugf("Basic auth failed for user %s", user)
 
@@ -215,13 +297,19 @@ func basicAuthRequiredMw(w http.ResponseWriter, r *http.Request, next http.Handl
 	next(w, r)
 }
 
-// StartServer starts the REST API.
-func StartServer(dbpg database.PgDB, queueService queue.Service, registryService registry.Service, hubService hub.InfoService, encryptionService encryption.Service) {
+// StartServer starts the REST API, and returns once it has shut down after the stop channel is closed.
+func StartServer(dbpg database.PgDB, queueService queue.Service, registryService registry.Service, hubService hub.InfoService, encryptionService encryption.Service, stop <-chan struct{}) {
 	qs = queueService
 	rs = registryService
 	hs = hubService
 	es = encryptionService
 	db = dbpg
+	db.ImageChanged = badges.invalidate
+	err := db.ListenForImageChanges(badges.imageChanged)
+	if err != nil {
+		log.Errorf("Failed to listen for image changes, badges will be refreshed when they expire: %v", err)
+	}
+
 	gothic.Store = db.SessionStore
 	sessionStore = db.SessionStore
 	webhookURL = os.Getenv("MB_WEBHOOK_URL")
@@ -235,7 +323,29 @@ func StartServer(dbpg database.PgDB, queueService queue.Service, registryService
 
 	n := negroni.Classic()
 	n.UseHandler(r)
-	http.ListenAndServe(":8080", n)
+
+	srv := &http.Server{Addr: ":8080", Handler: n}
+	shutdown := make(chan struct{})
+	go func() {
+		<-stop
+		log.Info("Shutting down API, waiting for requests to finish")
+		ctx, cancel := context.WithTimeout(context.Background(), constShutdownTimeout)
+		defer cancel()
+
+		err := srv.Shutdown(ctx)
+		if err != nil {
+			log.Errorf("Error shutting down API: %v", err)
+		}
+		close(shutdown)
+	}()
+
+	err = srv.ListenAndServe()
+	if err != http.ErrServerClosed {
+		log.Errorf("API server failed: %v", err)
+		return
+	}
+
+	<-shutdown
 }
 
 // Where a version has several tags, we use the longest one
@@ -276,7 +386,7 @@ func handleImageWebhook(w http.ResponseWriter, r *http.Request) {
 		org = "library"
 	}
 
-	img, err := db.GetImage(org + "/" + image)
+	img, err := db.GetImage(getImageNameWithHost(vars, org+"/"+image))
 	var msg string
 
 	if err == nil {
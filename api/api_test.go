@@ -33,6 +33,7 @@ func getDatabase(t *testing.T) database.PgDB {
 }
 
 func emptyDatabase(db database.PgDB) {
+	db.Exec("DELETE FROM platforms")
 	db.Exec("DELETE FROM tags")
 	db.Exec("DELETE FROM image_versions")
 	db.Exec("DELETE FROM images")
@@ -44,9 +45,12 @@ func emptyDatabase(db database.PgDB) {
 	db.Exec("DELETE from user_image_permissions")
 	db.Exec("DELETE FROM user_registry_credentials")
 	db.Exec("DELETE FROM sessions")
+	db.Exec("DELETE FROM layer_contents")
+	db.Exec("DELETE FROM inspection_failures")
 	db.Exec("SELECT setval('users_id_seq', 1, false)")
 	db.Exec("SELECT setval('notifications_id_seq', 1, false)")
 	db.Exec("SELECT setval('notification_messages_id_seq', 1, false)")
+	badges.clear()
 }
 
 func addThings(db database.PgDB) {
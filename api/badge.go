@@ -1,27 +1,30 @@
 package api
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"code.cloudfoundry.org/bytefmt"
 	"github.com/gorilla/mux"
 
+	"github.com/microscaling/microbadger/badge"
 	"github.com/microscaling/microbadger/database"
 	"github.com/microscaling/microbadger/inspector"
 )
 
-func handleGetImageBadge(w http.ResponseWriter, r *http.Request) {
-	var labelValue, badgeSVG string
-	var imageVersion database.ImageVersion
+// Long enough for the architectures of most multi-architecture images
+const constMaxArchLength = 60
 
+func handleGetImageBadge(w http.ResponseWriter, r *http.Request) {
 	var image, org, tag, badgeType string
 	var ok bool
-	var license *database.License
-	var vcs *database.VersionControl
 
 	vars := mux.Vars(r)
 	image = vars["image"]
@@ -31,24 +34,101 @@ func handleGetImageBadge(w http.ResponseWriter, r *http.Request) {
 
 	badgeType = vars["badgeType"]
 	tag = vars["tag"]
+	format := getBadgeFormat(vars["format"], r.Header.Get("Accept"))
+	query := r.URL.Query()
 
-	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
-	w.Header().Set("Expires", time.Now().Format(http.TimeFormat))
+	options, err := badge.ParseOptions(query)
+	if err != nil {
+		log.Infof("Bad options for badge: %v", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// Label badges show the value of any label
+	key := query.Get("key")
+	if badgeType == "label" && key == "" {
+		log.Infof("No key for label badge")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
 
 	log.Debugf("Badge type: %s - image: %s - tag: %s", badgeType, org+"/"+image, tag)
 
-	img, err := db.GetImage(org + "/" + image)
+	name := getImageNameWithHost(vars, org+"/"+image)
+	cacheKey := strings.Join([]string{name, tag, badgeType, format, query.Encode()}, " ")
+
+	// Pulls and stars badges aren't kept in memory as we aren't told when the counts change
+	hubCounts := badgeType == "pulls" || badgeType == "stars"
+	maxAge := badgeMaxAge
+	if hubCounts && maxAge > constHubCountBadgeMaxAge {
+		maxAge = constHubCountBadgeMaxAge
+	}
+
+	var cb cachedBadge
+	if !hubCounts {
+		cb, ok = badges.get(cacheKey)
+	}
+
+	if hubCounts || !ok {
+		b, latest, status := getImageBadge(name, tag, badgeType, key)
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+
+		b.Customize(options)
+		cb = cachedBadge{
+			key:       cacheKey,
+			imageName: name,
+			etag:      getBadgeETag(latest, tag, badgeType, format, query, b),
+		}
+
+		cb.body, cb.contentType, err = renderBadge(b, format)
+		if err != nil {
+			log.Errorf("Error rendering %s badge: %v", format, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if !hubCounts {
+			badges.put(cb)
+		}
+	}
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+	w.Header().Set("ETag", cb.etag)
+	w.Header().Set("Vary", "Accept")
+
+	if etagMatches(r.Header.Get("If-None-Match"), cb.etag) {
+		w.WriteHeader(http.StatusNotModified)
+	} else {
+		w.Header().Set("Content-Type", cb.contentType)
+		w.Write(cb.body)
+	}
+
+	ga.ImageView("imageView", "imageView", "badge", badgeType, r)
+}
+
+// getImageBadge looks up the image to make the badge. It also returns the latest version of
+// the image, which changes whenever the image is updated.
+func getImageBadge(name string, tag string, badgeType string, key string) (b badge.Badge, latest string, status int) {
+	var labelValue string
+	var imageVersion database.ImageVersion
+	var license *database.License
+	var vcs *database.VersionControl
+
+	img, err := db.GetImage(name)
 	if err != nil || img.Status == "MISSING" || img.IsPrivate {
-		log.Infof("Image %s missing for badge", img.Name)
+		log.Infof("Image %s missing for badge", name)
 		badgeType = "imagemissing"
 	} else {
+		latest = img.Latest
 		if tag == "" {
 			imageVersion, err = db.GetImageVersionBySHA(img.Latest, img.Name, false)
 			if err != nil {
 				// Error as there should always be a latest SHA.
 				log.Errorf("Missing latest version for %s: %v", img.Name, err)
-				w.WriteHeader(http.StatusInternalServerError)
-				return
+				return b, latest, http.StatusInternalServerError
 			}
 		} else {
 			imageVersion, err = db.GetImageVersionByTag(img.Name, tag)
@@ -65,88 +145,290 @@ func handleGetImageBadge(w http.ResponseWriter, r *http.Request) {
 
 	switch badgeType {
 	case "image":
-		badgeSVG = generateImageBadge(imageVersion)
+		b = generateImageBadge(imageVersion)
 
 	case "commit":
 		if vcs == nil {
 			labelValue = "not given"
 		} else {
-			labelValue = formatLabel(vcs.Commit, 7)
+			labelValue = badge.Truncate(vcs.Commit, 7)
 		}
-		badgeSVG = generateBadge(badgeType, labelValue)
+		b = generateBadge(badgeType, labelValue)
 
 	case "version":
 		// Use a tag if it was specified
 		if tag != "" {
 			log.Debugf("Specified tag is %s", tag)
-			labelValue = formatLabel(tag, 10)
+			labelValue = badge.Truncate(tag, 10)
 		} else {
-			labelValue = formatLabel(getLongestTag(&imageVersion), 10)
+			labelValue = badge.Truncate(getLongestTag(&imageVersion), 10)
 		}
-		badgeSVG = generateBadge(badgeType, labelValue)
+		b = generateBadge(badgeType, labelValue)
+
+	case "arch":
+		platforms, err := db.GetPlatforms(&imageVersion)
+		if err != nil {
+			log.Errorf("Error getting platforms for %s: %v", img.Name, err)
+		}
+
+		labelValue = formatPlatforms(imageVersion, platforms)
+		b = generateBadge(badgeType, labelValue)
 
 	case "license":
 		if license == nil {
 			labelValue = "not given"
 		} else {
-			labelValue = formatLabel(license.Code, 10)
+			labelValue = badge.Truncate(license.Code, 10)
+		}
+		b = generateBadge(badgeType, labelValue)
+
+	case "created":
+		b = generateBadge(badgeType, formatAge(imageVersion.Created, time.Now()))
+
+	case "size":
+		b = generateBadge(badgeType, fmt.Sprintf("%sB", bytefmt.ByteSize(uint64(imageVersion.DownloadSize))))
+
+	case "layers":
+		b = generateBadge(badgeType, fmt.Sprintf("%d", imageVersion.LayerCount))
+
+	case "pulls":
+		b = generateBadge(badgeType, formatCount(img.PullCount))
+
+	case "stars":
+		b = generateBadge(badgeType, formatCount(img.StarCount))
+
+	case "base":
+		labelValue = "not found"
+		if parent, ok := getParent(imageVersion); ok {
+			labelValue = badge.Truncate(getParentReference(parent), 30)
+		}
+		b = generateBadge(badgeType, labelValue)
+
+	case "label":
+		labels, _ := getLabelMapFromLabels(&imageVersion)
+		if value, ok := labels[key]; ok && value != "" {
+			labelValue = badge.Truncate(value, 20)
+		} else {
+			labelValue = "not given"
 		}
-		badgeSVG = generateBadge(badgeType, labelValue)
+		b = generateBadge(getLabelBadgeName(key), labelValue)
 
 	case "imagemissing":
-		badgeSVG = generateBadge("Image", "not found")
+		b = generateBadge("Image", "not found")
 
 	case "tagmissing":
-		badgeSVG = generateBadge(formatLabel(tag, 7), "not found")
+		b = generateBadge(badge.Truncate(tag, 7), "not found")
 
 	default:
 		log.Infof("Bad badge type for badge %s", badgeType)
-		w.WriteHeader(http.StatusBadRequest)
-		return
+		return b, latest, http.StatusBadRequest
 	}
 
-	w.Write([]byte(badgeSVG))
+	return b, latest, http.StatusOK
+}
 
-	ga.ImageView("imageView", "imageView", "badge", badgeType, r)
+// badgeContentTypes maps the badge formats to their content types
+var badgeContentTypes = map[string]string{
+	"svg":  "image/svg+xml",
+	"png":  "image/png",
+	"json": "application/json",
 }
 
-func formatLabel(input string, length int) string {
-	var result string
+// getBadgeFormat uses the extension if there is one. Otherwise it's the format the client
+// prefers in the Accept header, which defaults to SVG.
+func getBadgeFormat(ext string, accept string) string {
+	if ext != "" {
+		return ext
+	}
 
-	if len(input) > length {
-		lastChar := length - 1
+	format := "svg"
+	best := 0.0
+	for _, part := range strings.Split(accept, ",") {
+		fields := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(fields[0]))
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+					q = v
+				}
+			}
+		}
 
-		result = input[0:lastChar] + constEllipsis
-	} else {
-		result = input
+		for f, contentType := range badgeContentTypes {
+			if mediaType == contentType && q > best {
+				format = f
+				best = q
+			}
+		}
 	}
 
-	return result
+	return format
 }
 
-func generateImageBadge(latest database.ImageVersion) (badgeSVG string) {
-	size := fmt.Sprintf("%sB", bytefmt.ByteSize(uint64(latest.DownloadSize)))
-	layers := fmt.Sprintf("%d layers", latest.LayerCount)
+// renderBadge renders the badge in the format and gets its content type
+func renderBadge(b badge.Badge, format string) (body []byte, contentType string, err error) {
+	switch format {
+	case "png":
+		body, err = b.PNG()
+	case "json":
+		body, err = b.JSON()
+	default:
+		format = "svg"
+		body = []byte(b.SVG())
+	}
 
-	badgeSVG = "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"130\" height=\"20\"><linearGradient id=\"b\" x2=\"0\" y2=\"100%\"><stop offset=\"0\" stop-color=\"#bbb\" stop-opacity=\".1\"/><stop offset=\"1\" stop-opacity=\".1\"/></linearGradient><mask id=\"a\"><rect width=\"130\" height=\"20\" rx=\"3\" fill=\"#fff\"/></mask><g mask=\"url(#a)\"><path fill=\"#555\" d=\"M0 0h61v20H0z\"/><path fill=\"#007ec6\" d=\"M61 0h69v20H61z\"/><path fill=\"url(#b)\" d=\"M0 0h130v20H0z\"/></g><g fill=\"#fff\" text-anchor=\"middle\" font-family=\"DejaVu Sans,Verdana,Geneva,sans-serif\" font-size=\"11\"><text x=\"30.5\" y=\"15\" fill=\"#010101\" fill-opacity=\".3\">SIZE</text><text x=\"30.5\" y=\"14\">SIZE</text><text x=\"94.5\" y=\"15\" fill=\"#010101\" fill-opacity=\".3\">LAYERS</text><text x=\"94.5\" y=\"14\">LAYERS</text></g></svg>"
+	return body, badgeContentTypes[format], err
+}
 
-	badgeSVG = strings.Replace(badgeSVG, "SIZE", size, 2)
-	badgeSVG = strings.Replace(badgeSVG, "LAYERS", layers, 2)
+// getBadgeETag identifies what's shown on the badge. As well as the latest version of the image
+// we include the text, as counts like pulls and stars change without the image changing.
+func getBadgeETag(latest string, tag string, badgeType string, format string, query url.Values, b badge.Badge) string {
+	h := sha256.New()
+	for _, s := range []string{latest, tag, badgeType, format, query.Encode(), b.Label, b.Value} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
 
-	return badgeSVG
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
 }
 
-func generateBadge(label string, value string) (badgeSVG string) {
-	if len(value) > 7 {
-		badgeSVG = "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"122\" height=\"20\"><linearGradient id=\"b\" x2=\"0\" y2=\"100%\"><stop offset=\"0\" stop-color=\"#bbb\" stop-opacity=\".1\"/><stop offset=\"1\" stop-opacity=\".1\"/></linearGradient><mask id=\"a\"><rect width=\"122\" height=\"20\" rx=\"3\" fill=\"#fff\"/></mask><g mask=\"url(#a)\"><path fill=\"#555\" d=\"M0 0h47v20H0z\"/><path fill=\"#007ec6\" d=\"M47 0h75v20H47z\"/><path fill=\"url(#b)\" d=\"M0 0h122v20H0z\"/></g><g fill=\"#fff\" text-anchor=\"middle\" font-family=\"DejaVu Sans,Verdana,Geneva,sans-serif\" font-size=\"11\"><text x=\"23.5\" y=\"15\" fill=\"#010101\" fill-opacity=\".3\">LABEL</text><text x=\"23.5\" y=\"14\">LABEL</text><text x=\"83.5\" y=\"15\" fill=\"#010101\" fill-opacity=\".3\">VALUE</text><text x=\"83.5\" y=\"14\">VALUE</text></g></svg>"
-	} else {
-		badgeSVG = "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"116\" height=\"20\"><linearGradient id=\"b\" x2=\"0\" y2=\"100%\"><stop offset=\"0\" stop-color=\"#bbb\" stop-opacity=\".1\"/><stop offset=\"1\" stop-opacity=\".1\"/></linearGradient><mask id=\"a\"><rect width=\"116\" height=\"20\" rx=\"3\" fill=\"#fff\"/></mask><g mask=\"url(#a)\"><path fill=\"#555\" d=\"M0 0h51v20H0z\"/><path fill=\"#007ec6\" d=\"M51 0h65v20H51z\"/><path fill=\"url(#b)\" d=\"M0 0h116v20H0z\"/></g><g fill=\"#fff\" text-anchor=\"middle\" font-family=\"DejaVu Sans,Verdana,Geneva,sans-serif\" font-size=\"11\"><text x=\"25.5\" y=\"15\" fill=\"#010101\" fill-opacity=\".3\">LABEL</text><text x=\"25.5\" y=\"14\">LABEL</text><text x=\"82.5\" y=\"15\" fill=\"#010101\" fill-opacity=\".3\">VALUE</text><text x=\"82.5\" y=\"14\">VALUE</text></g></svg>"
+// etagMatches checks the If-None-Match header. We only serve the one representation
+// so weak and strong ETags are treated the same.
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getLabelBadgeName is the last part of the label key, e.g. version for org.opencontainers.image.version
+func getLabelBadgeName(key string) string {
+	name := key[strings.LastIndexAny(key, "./")+1:]
+	if name == "" {
+		name = key
+	}
+
+	return badge.Truncate(name, 20)
+}
+
+// formatAge says how long ago the image version was created
+func formatAge(created time.Time, now time.Time) string {
+	if created.IsZero() {
+		return "not given"
+	}
+
+	age := now.Sub(created)
+	days := int(age.Hours() / 24)
+
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return formatAgeUnits(int(age.Minutes()), "minute")
+	case age < 24*time.Hour:
+		return formatAgeUnits(int(age.Hours()), "hour")
+	case days < 30:
+		return formatAgeUnits(days, "day")
+	case days < 365:
+		return formatAgeUnits(days/30, "month")
+	default:
+		return formatAgeUnits(days/365, "year")
+	}
+}
+
+func formatAgeUnits(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit + " ago"
+	}
+
+	return fmt.Sprintf("%d %ss ago", n, unit)
+}
+
+// formatCount abbreviates large numbers e.g. 1.2k or 35M
+func formatCount(n int) string {
+	if n < 1000 {
+		return fmt.Sprintf("%d", n)
+	}
+
+	value := float64(n)
+	for _, unit := range []string{"k", "M", "B"} {
+		value /= 1000
+
+		// Move on to the next unit if rounding would give e.g. 1000k
+		if value < 999.95 || unit == "B" {
+			return strings.TrimSuffix(fmt.Sprintf("%.1f", value), ".0") + unit
+		}
+	}
+
+	return ""
+}
+
+// getParent finds the image this version is built on, if we know about it
+func getParent(iv database.ImageVersion) (parent database.ImageVersion, ok bool) {
+	var layers []database.ImageLayer
+	if iv.Layers == "" {
+		return
+	}
+
+	key := iv.ImageName + "@" + iv.SHA
+	if p, found, cached := parents.get(key); cached {
+		return p, found
+	}
+
+	err := json.Unmarshal([]byte(iv.Layers), &layers)
+	if err != nil {
+		log.Errorf("Error unmarshalling layers %s: %v", iv.Layers, err)
+		return
+	}
+
+	matches := getParentsFromLayers(&layers, iv.SHA, iv.ImageName, nil)
+	if len(matches) > 0 {
+		parent, ok = matches[0], true
 	}
 
-	badgeSVG = strings.Replace(badgeSVG, "LABEL", label, 2)
-	badgeSVG = strings.Replace(badgeSVG, "VALUE", value, 2)
+	parents.put(key, parent, ok)
+	return parent, ok
+}
+
+// formatPlatforms lists the architectures for a multi-architecture tag, or the architecture
+// of the image version if it only has one.
+func formatPlatforms(iv database.ImageVersion, platforms []database.Platform) string {
+	if len(platforms) == 0 {
+		if iv.Architecture == "" {
+			return "not given"
+		}
+		platforms = []database.Platform{{OS: iv.OS, Architecture: iv.Architecture, Variant: iv.Variant}}
+	}
+
+	archs := make([]string, len(platforms))
+	for i, p := range platforms {
+		// Linux is assumed so only show the OS for other platforms
+		if p.OS == "linux" {
+			archs[i] = strings.TrimPrefix(p.String(), "linux/")
+		} else {
+			archs[i] = p.String()
+		}
+	}
+
+	return badge.Truncate(strings.Join(archs, " | "), constMaxArchLength)
+}
+
+func generateImageBadge(latest database.ImageVersion) badge.Badge {
+	size := fmt.Sprintf("%sB", bytefmt.ByteSize(uint64(latest.DownloadSize)))
+	layers := fmt.Sprintf("%d layers", latest.LayerCount)
+
+	return badge.New(size, layers)
+}
 
-	return badgeSVG
+func generateBadge(label string, value string) badge.Badge {
+	return badge.New(label, value)
 }
 
 func handleGetBadgeCounts(w http.ResponseWriter, r *http.Request) {
@@ -103,7 +103,7 @@ func TestGetBadges(t *testing.T) {
 	// or     /badges/<badgeType>/<image>:<tag>.svg for a specific tag
 	// or     /badges/<badgeType>/<image>.svg for library images
 
-	// <badgeType> is one of image, commit, version, license
+	// <badgeType> is one of image, commit, version, license, arch
 
 	// At the command line Docker insists that the image name is lower case
 	// However, tags are case sensitive, e.g
@@ -154,7 +154,7 @@ func TestGetBadges(t *testing.T) {
 	for id, test := range tests {
 		log.Debugf("-test %d----------", id)
 
-		for _, bt := range []string{"image", "commit", "version", "license"} {
+		for _, bt := range []string{"image", "commit", "version", "license", "arch", "created", "size", "layers", "pulls", "stars", "base"} {
 			log.Debugf("-- badge type %s----------", bt)
 			url := strings.Replace(test.url, "<badgeType>", bt, 1)
 			res, err := http.Get(ts.URL + url)
@@ -207,12 +207,24 @@ func TestGetBadges(t *testing.T) {
 							t.Errorf("#%d version badge doesn't show correct tag: (%s)", id, body)
 						}
 
+					case "arch":
+						// Arch badge shows e.g. "arch amd64 | arm64"
+						if !strings.Contains(string(body), "arch") {
+							t.Errorf("#%d arch badge doesn't show arch: (%s)", id, body)
+						}
+
 					case "license":
 						// License badge shows e.g. "license MIT"
 						// TODO! Check license is correct
 						if !strings.Contains(string(body), "license") {
 							t.Errorf("#%d license badge doesn't show license: (%s)", id, body)
 						}
+
+					default:
+						// The other badges show the badge type as the label
+						if !strings.Contains(string(body), ">"+bt+"<") {
+							t.Errorf("#%d %s badge doesn't show %s: (%s)", id, bt, bt, body)
+						}
 					}
 				}
 			}
@@ -220,6 +232,218 @@ func TestGetBadges(t *testing.T) {
 	}
 }
 
+func TestGetBadgeOptions(t *testing.T) {
+	type test struct {
+		url      string
+		status   int
+		contains string
+	}
+
+	var tests = []test{
+		{url: `/badges/version/lizrice/childimage.svg`, status: 200, contains: `rx="3"`},
+		{url: `/badges/version/lizrice/childimage.svg?style=flat`, status: 200, contains: `rx="3"`},
+		{url: `/badges/version/lizrice/childimage.svg?style=flat-square`, status: 200, contains: `shape-rendering="crispEdges"`},
+		{url: `/badges/license/lizrice/childimage.svg?style=plastic`, status: 200, contains: `height="18"`},
+		{url: `/badges/image/lizrice/childimage.svg?style=for-the-badge`, status: 200, contains: `LAYERS`},
+		{url: `/badges/commit/lizrice/childimage.svg?style=social`, status: 200, contains: `fill="#fafafa"`},
+		{url: `/badges/version/lizrice/blah.svg?style=for-the-badge`, status: 200, contains: `NOT FOUND`},
+
+		// Custom labels, colours and logos
+		{url: `/badges/version/lizrice/childimage.svg?label=tag`, status: 200, contains: `>tag</text>`},
+		{url: `/badges/version/lizrice/childimage.svg?color=green&labelColor=%23ABC`, status: 200, contains: `<path fill="#abc" d="M0 0h51v20H0z"/><path fill="#97ca00"`},
+		{url: `/badges/commit/lizrice/childimage.svg?logo=git`, status: 200, contains: `fill-rule="evenodd"`},
+		{url: `/badges/license/lizrice/childimage.svg?label=%3Cscript%3E`, status: 200, contains: `&lt;script&gt;`},
+
+		// Invalid options
+		{url: `/badges/version/lizrice/childimage.svg?style=blah`, status: 400},
+		{url: `/badges/version/lizrice/childimage.svg?color=blah`, status: 400},
+		{url: `/badges/version/lizrice/childimage.svg?labelColor=%22%3E`, status: 400},
+		{url: `/badges/version/lizrice/childimage.svg?logo=blah`, status: 400},
+		{url: `/badges/label/lizrice/childimage.svg`, status: 400},
+	}
+
+	db = getDatabase(t)
+	emptyDatabase(db)
+	addBadgeThings(db)
+
+	ts := httptest.NewServer(muxRoutes())
+	defer ts.Close()
+
+	for id, test := range tests {
+		res, err := http.Get(ts.URL + test.url)
+		if err != nil {
+			t.Fatalf("Failed to send request #%d (%s) %v", id, test.url, err)
+		}
+
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			t.Fatalf("Error getting body. %v", err)
+		}
+
+		if res.StatusCode != test.status {
+			t.Errorf("#%d Unexpected status code %d", id, res.StatusCode)
+		}
+
+		if !strings.Contains(string(body), test.contains) {
+			t.Errorf("#%d Badge doesn't contain %s: (%s)", id, test.contains, body)
+		}
+	}
+}
+
+func TestGetBadgeValues(t *testing.T) {
+	type test struct {
+		url   string
+		value string
+	}
+
+	var tests = []test{
+		{url: `/badges/created/lizrice/nolatest:earlier.svg`, value: "1 day ago"},
+		{url: `/badges/created/lizrice/nolatest:recent.svg`, value: "just now"},
+		{url: `/badges/created/lizrice/childimage.svg`, value: "not given"},
+		{url: `/badges/size/lizrice/childimage.svg`, value: "0B"},
+		{url: `/badges/layers/lizrice/childimage.svg`, value: "5"},
+		{url: `/badges/pulls/lizrice/nolatest.svg`, value: "1k"},
+		{url: `/badges/pulls/lizrice/childimage.svg`, value: "2"},
+		{url: `/badges/stars/lizrice/childimage.svg`, value: "0"},
+		{url: `/badges/base/lizrice/childimage:specific.svg`, value: "another/parentimage:latest"},
+		{url: `/badges/base/another/parentimage.svg`, value: "not found"},
+		{url: `/badges/label/lizrice/childimage:specific.svg?key=com.lizrice.test`, value: "olé"},
+		{url: `/badges/label/lizrice/childimage:specific.svg?key=com.lizrice.test`, value: "test"},
+		{url: `/badges/label/lizrice/childimage.svg?key=com.lizrice.test&label=Test%20label`, value: "Test label"},
+		{url: `/badges/label/lizrice/childimage.svg?key=org.opencontainers.image.version`, value: "not given"},
+		{url: `/badges/label/lizrice/childimage.svg?key=org.opencontainers.image.version`, value: "version"},
+	}
+
+	db = getDatabase(t)
+	emptyDatabase(db)
+	addBadgeThings(db)
+	addImageVersionDetails(db)
+	db.Exec("UPDATE image_versions SET layer_count = 5 WHERE image_name='lizrice/childimage'")
+
+	ts := httptest.NewServer(muxRoutes())
+	defer ts.Close()
+
+	for id, test := range tests {
+		res, err := http.Get(ts.URL + test.url)
+		if err != nil {
+			t.Fatalf("Failed to send request #%d (%s) %v", id, test.url, err)
+		}
+
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			t.Fatalf("Error getting body. %v", err)
+		}
+
+		if res.StatusCode != 200 {
+			t.Errorf("#%d Unexpected status code %d", id, res.StatusCode)
+		}
+
+		if !strings.Contains(string(body), ">"+test.value+"<") {
+			t.Errorf("#%d Badge doesn't show %s: (%s)", id, test.value, body)
+		}
+	}
+}
+
+func TestGetLabelBadgeName(t *testing.T) {
+	var tests = []struct {
+		key  string
+		name string
+	}{
+		{key: "org.opencontainers.image.version", name: "version"},
+		{key: "com.ourco.build-number", name: "build-number"},
+		{key: "maintainer", name: "maintainer"},
+		{key: "com.ourco/team", name: "team"},
+		{key: "trailing.", name: "trailing."},
+	}
+
+	for _, test := range tests {
+		name := getLabelBadgeName(test.key)
+		if name != test.name {
+			t.Errorf("Name for %s is %s, expected %s", test.key, name, test.name)
+		}
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	var tests = []struct {
+		created time.Time
+		age     string
+	}{
+		{created: time.Time{}, age: "not given"},
+		{created: now.Add(-10 * time.Second), age: "just now"},
+		{created: now.Add(-1 * time.Minute), age: "1 minute ago"},
+		{created: now.Add(-59 * time.Minute), age: "59 minutes ago"},
+		{created: now.Add(-3 * time.Hour), age: "3 hours ago"},
+		{created: now.Add(-36 * time.Hour), age: "1 day ago"},
+		{created: now.AddDate(0, 0, -45), age: "1 month ago"},
+		{created: now.AddDate(0, -11, 0), age: "11 months ago"},
+		{created: now.AddDate(-3, 0, 0), age: "3 years ago"},
+	}
+
+	for _, test := range tests {
+		age := formatAge(test.created, now)
+		if age != test.age {
+			t.Errorf("Age of %v is %s, expected %s", test.created, age, test.age)
+		}
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	var tests = []struct {
+		n     int
+		count string
+	}{
+		{n: 0, count: "0"},
+		{n: 999, count: "999"},
+		{n: 1000, count: "1k"},
+		{n: 1260, count: "1.3k"},
+		{n: 35000, count: "35k"},
+		{n: 999960, count: "1M"},
+		{n: 1500000, count: "1.5M"},
+		{n: 2000000000, count: "2B"},
+	}
+
+	for _, test := range tests {
+		count := formatCount(test.n)
+		if count != test.count {
+			t.Errorf("Count %d formatted as %s, expected %s", test.n, count, test.count)
+		}
+	}
+}
+
+func TestFormatPlatforms(t *testing.T) {
+	iv := database.ImageVersion{OS: "linux", Architecture: "amd64"}
+	platform := func(os string, arch string, variant string) database.Platform {
+		return database.Platform{OS: os, Architecture: arch, Variant: variant}
+	}
+
+	var tests = []struct {
+		iv        database.ImageVersion
+		platforms []database.Platform
+		archs     string
+	}{
+		{iv: database.ImageVersion{}, archs: "not given"},
+		{iv: iv, archs: "amd64"},
+		{iv: iv, platforms: []database.Platform{platform("linux", "amd64", ""), platform("windows", "amd64", "")}, archs: "amd64 | windows/amd64"},
+		{iv: iv, platforms: []database.Platform{
+			platform("linux", "amd64", ""), platform("linux", "arm", "v6"), platform("linux", "arm", "v7"),
+			platform("linux", "arm64", "v8"), platform("linux", "386", ""), platform("linux", "ppc64le", ""),
+			platform("linux", "s390x", ""),
+		}, archs: "amd64 | arm/v6 | arm/v7 | arm64/v8 | 386 | ppc64le | s390x"},
+	}
+
+	for _, test := range tests {
+		archs := formatPlatforms(test.iv, test.platforms)
+		if archs != test.archs {
+			t.Errorf("Architectures for %v are %s, expected %s", test.platforms, archs, test.archs)
+		}
+	}
+}
+
 func TestGetBadgeLoggedIn(t *testing.T) {
 	// TODO!!
 	// t.Errorf("Add test to make sure badges aren't visible even if you're logged in and have access to that image")
@@ -278,3 +502,235 @@ func TestGetBadgeCounts(t *testing.T) {
 		}
 	}
 }
+
+func TestGetBadgeFormats(t *testing.T) {
+	type test struct {
+		url         string
+		accept      string
+		contentType string
+		contains    string
+	}
+
+	var tests = []test{
+		{url: `/badges/version/lizrice/childimage.svg`, contentType: "image/svg+xml", contains: `<svg`},
+		{url: `/badges/version/lizrice/childimage.png`, contentType: "image/png", contains: "\x89PNG"},
+		{url: `/badges/version/lizrice/childimage.json`, contentType: "application/json", contains: `"schemaVersion":1,"label":"version"`},
+		{url: `/badges/version/lizrice/childimage:specific.png`, contentType: "image/png", contains: "\x89PNG"},
+		{url: `/badges/version/lizrice/blah.json`, contentType: "application/json", contains: `"message":"not found"`},
+
+		// The extension wins over the Accept header
+		{url: `/badges/version/lizrice/childimage.svg`, accept: "image/png", contentType: "image/svg+xml", contains: `<svg`},
+
+		// Without an extension the Accept header is used
+		{url: `/badges/version/lizrice/childimage`, contentType: "image/svg+xml", contains: `<svg`},
+		{url: `/badges/version/lizrice/childimage`, accept: "image/png", contentType: "image/png", contains: "\x89PNG"},
+		{url: `/badges/version/lizrice/childimage:specific`, accept: "text/html, application/json;q=0.9, image/svg+xml;q=0.5", contentType: "application/json", contains: `"message":"specific"`},
+		{url: `/badges/version/lizrice/childimage`, accept: "*/*", contentType: "image/svg+xml", contains: `<svg`},
+	}
+
+	db = getDatabase(t)
+	emptyDatabase(db)
+	addBadgeThings(db)
+
+	ts := httptest.NewServer(muxRoutes())
+	defer ts.Close()
+
+	for id, test := range tests {
+		req, _ := http.NewRequest("GET", ts.URL+test.url, nil)
+		if test.accept != "" {
+			req.Header.Set("Accept", test.accept)
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send request #%d (%s) %v", id, test.url, err)
+		}
+
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			t.Fatalf("Error getting body. %v", err)
+		}
+
+		if res.StatusCode != 200 {
+			t.Errorf("#%d Unexpected status code %d", id, res.StatusCode)
+		}
+
+		if res.Header.Get("Content-Type") != test.contentType {
+			t.Errorf("#%d Content type is %s, expected %s", id, res.Header.Get("Content-Type"), test.contentType)
+		}
+
+		if !strings.Contains(string(body), test.contains) {
+			t.Errorf("#%d Badge doesn't contain %q", id, test.contains)
+		}
+	}
+}
+
+func TestGetBadgeCaching(t *testing.T) {
+	db = getDatabase(t)
+	emptyDatabase(db)
+	addBadgeThings(db)
+	db.ImageChanged = badges.invalidate
+
+	ts := httptest.NewServer(muxRoutes())
+	defer ts.Close()
+
+	get := func(url string, etag string) *http.Response {
+		req, _ := http.NewRequest("GET", ts.URL+url, nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send request (%s) %v", url, err)
+		}
+		res.Body.Close()
+		return res
+	}
+
+	res := get(`/badges/version/lizrice/childimage.svg`, "")
+	etag := res.Header.Get("ETag")
+	if res.StatusCode != 200 || etag == "" {
+		t.Fatalf("Expected badge with ETag, got %d %s", res.StatusCode, etag)
+	}
+
+	if res.Header.Get("Cache-Control") != "public, max-age=300" {
+		t.Errorf("Unexpected Cache-Control %s", res.Header.Get("Cache-Control"))
+	}
+
+	// Conditional requests get 304 until the image changes
+	res = get(`/badges/version/lizrice/childimage.svg`, etag)
+	if res.StatusCode != http.StatusNotModified {
+		t.Errorf("Expected 304, got %d", res.StatusCode)
+	}
+
+	// Different parameters and formats have different ETags
+	for _, url := range []string{`/badges/version/lizrice/childimage.svg?style=social`, `/badges/version/lizrice/childimage.png`, `/badges/version/lizrice/childimage:specific.svg`} {
+		res = get(url, etag)
+		if res.StatusCode != 200 || res.Header.Get("ETag") == etag {
+			t.Errorf("Expected a different badge for %s, got %d %s", url, res.StatusCode, res.Header.Get("ETag"))
+		}
+	}
+
+	img, err := db.GetImage("lizrice/childimage")
+	if err != nil {
+		t.Fatalf("Failed to get image: %v", err)
+	}
+
+	img.Latest = "10001"
+	_, err = db.PutImage(img)
+	if err != nil {
+		t.Fatalf("Failed to put image: %v", err)
+	}
+
+	res = get(`/badges/version/lizrice/childimage.svg`, etag)
+	if res.StatusCode != 200 || res.Header.Get("ETag") == etag {
+		t.Errorf("Expected a new badge after the image changed, got %d %s", res.StatusCode, res.Header.Get("ETag"))
+	}
+
+	// Pull counts change without the image changing
+	res = get(`/badges/pulls/lizrice/childimage.svg`, "")
+	etag = res.Header.Get("ETag")
+	if res.Header.Get("Cache-Control") != "public, max-age=60" {
+		t.Errorf("Unexpected Cache-Control %s for pulls", res.Header.Get("Cache-Control"))
+	}
+
+	db.Exec("UPDATE images SET pull_count = 12345 WHERE name = 'lizrice/childimage'")
+	res = get(`/badges/pulls/lizrice/childimage.svg`, etag)
+	if res.StatusCode != 200 || res.Header.Get("ETag") == etag {
+		t.Errorf("Expected a new pulls badge after the count changed, got %d %s", res.StatusCode, res.Header.Get("ETag"))
+	}
+}
+
+// The inspectors run in other processes with their own database connections, so badges are
+// invalidated by database notifications
+func TestGetBadgeInvalidatedByInspector(t *testing.T) {
+	db = getDatabase(t)
+	emptyDatabase(db)
+	addBadgeThings(db)
+	db.ImageChanged = nil
+	badges.clear()
+
+	err := db.ListenForImageChanges(badges.imageChanged)
+	if err != nil {
+		t.Fatalf("Failed to listen for image changes: %v", err)
+	}
+
+	ts := httptest.NewServer(muxRoutes())
+	defer ts.Close()
+
+	get := func() string {
+		res, err := http.Get(ts.URL + `/badges/version/lizrice/childimage.svg`)
+		if err != nil {
+			t.Fatalf("Failed to send request %v", err)
+		}
+		res.Body.Close()
+		return res.Header.Get("ETag")
+	}
+
+	etag := get()
+	if etag == "" {
+		t.Fatalf("Expected a badge with an ETag")
+	}
+
+	// Save the image the way the inspector does, on its own connection
+	inspectorDB := getDatabase(t)
+	img, err := inspectorDB.GetImage("lizrice/childimage")
+	if err != nil {
+		t.Fatalf("Failed to get image: %v", err)
+	}
+
+	img.Latest = "10001"
+	_, err = inspectorDB.PutImage(img)
+	if err != nil {
+		t.Fatalf("Failed to put image: %v", err)
+	}
+
+	// Notifications are asynchronous
+	for i := 0; i < 50 && get() == etag; i++ {
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if get() == etag {
+		t.Errorf("Expected a new badge after the inspector changed the image")
+	}
+}
+
+func TestGetBadgeOCILabels(t *testing.T) {
+	type test struct {
+		url   string
+		value string
+	}
+
+	var tests = []test{
+		{url: `/badges/license/lizrice/childimage.svg`, value: "Apache-2.0"},
+		{url: `/badges/commit/lizrice/childimage.svg`, value: "abcdef1"},
+	}
+
+	db = getDatabase(t)
+	emptyDatabase(db)
+	addBadgeThings(db)
+	db.Exec("UPDATE image_versions SET labels = $1 WHERE image_name='lizrice/childimage' AND sha='10000'",
+		`{"org.opencontainers.image.licenses":"Apache-2.0","org.opencontainers.image.source":"https://github.com/lizrice/childimage","org.opencontainers.image.revision":"abcdef123456"}`)
+
+	ts := httptest.NewServer(muxRoutes())
+	defer ts.Close()
+
+	for id, test := range tests {
+		res, err := http.Get(ts.URL + test.url)
+		if err != nil {
+			t.Fatalf("Failed to send request #%d (%s) %v", id, test.url, err)
+		}
+
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			t.Fatalf("Error getting body. %v", err)
+		}
+
+		if !strings.Contains(string(body), ">"+test.value+"<") {
+			t.Errorf("#%d Badge doesn't show %s: (%s)", id, test.value, body)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package api
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/microscaling/microbadger/database"
+)
+
+// cachedBadge is a rendered badge ready to serve
+type cachedBadge struct {
+	key         string
+	imageName   string
+	body        []byte
+	contentType string
+	etag        string
+	expires     time.Time
+}
+
+// badgeCache keeps the most recently used badges so we don't need to go to the database
+// for each request. Badges are removed when their image changes, including changes made by
+// the inspectors, and expire after the max-age in case any notifications are lost.
+type badgeCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	order   *list.List
+	entries map[string]*list.Element
+	// The keys of the badges for each image, so they can be removed without a scan
+	images map[string]map[string]*list.Element
+}
+
+func newBadgeCache(size int, ttl time.Duration) *badgeCache {
+	return &badgeCache{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+		images:  make(map[string]map[string]*list.Element),
+	}
+}
+
+// get returns the badge for the key if it hasn't expired
+func (c *badgeCache) get(key string) (cachedBadge, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return cachedBadge{}, false
+	}
+
+	cb := el.Value.(cachedBadge)
+	if !time.Now().Before(cb.expires) {
+		c.remove(el)
+		return cachedBadge{}, false
+	}
+
+	c.order.MoveToFront(el)
+	return cb, true
+}
+
+// put adds the badge, removing the least recently used one if the cache is full
+func (c *badgeCache) put(cb cachedBadge) {
+	if c.size <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cb.expires = time.Now().Add(c.ttl)
+	if el, ok := c.entries[cb.key]; ok {
+		c.remove(el)
+	}
+
+	el := c.order.PushFront(cb)
+	c.entries[cb.key] = el
+	if c.images[cb.imageName] == nil {
+		c.images[cb.imageName] = make(map[string]*list.Element)
+	}
+	c.images[cb.imageName][cb.key] = el
+
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+// remove the badge from the cache. The lock must be held.
+func (c *badgeCache) remove(el *list.Element) {
+	cb := el.Value.(cachedBadge)
+	c.order.Remove(el)
+	delete(c.entries, cb.key)
+
+	delete(c.images[cb.imageName], cb.key)
+	if len(c.images[cb.imageName]) == 0 {
+		delete(c.images, cb.imageName)
+	}
+}
+
+// invalidate removes all the badges for the image
+func (c *badgeCache) invalidate(imageName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, el := range c.images[imageName] {
+		c.remove(el)
+	}
+}
+
+// imageChanged invalidates the badges for the image, or all of them if the name is empty
+// because changes might have been missed
+func (c *badgeCache) imageChanged(imageName string) {
+	if imageName == "" {
+		c.clear()
+		return
+	}
+
+	c.invalidate(imageName)
+}
+
+// clear removes all the badges
+func (c *badgeCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.entries = make(map[string]*list.Element)
+	c.images = make(map[string]map[string]*list.Element)
+}
+
+// parentCache keeps the parent we found for each image version, as finding it takes a
+// database query for each layer. New images can become parents, so entries expire.
+type parentCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	entries map[string]cachedParent
+}
+
+type cachedParent struct {
+	parent  database.ImageVersion
+	found   bool
+	expires time.Time
+}
+
+func newParentCache(size int, ttl time.Duration) *parentCache {
+	return &parentCache{
+		size:    size,
+		ttl:     ttl,
+		entries: make(map[string]cachedParent),
+	}
+}
+
+// get returns the parent for the key, and whether the key is cached
+func (c *parentCache) get(key string) (parent database.ImageVersion, found bool, cached bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cp, ok := c.entries[key]
+	if !ok || !time.Now().Before(cp.expires) {
+		return parent, false, false
+	}
+
+	return cp.parent, cp.found, true
+}
+
+// put adds the parent. If the cache is full, expired entries are removed, and if that
+// isn't enough an arbitrary one is.
+func (c *parentCache) put(key string, parent database.ImageVersion, found bool) {
+	if c.size <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.size {
+		for k, cp := range c.entries {
+			if !now.Before(cp.expires) {
+				delete(c.entries, k)
+			}
+		}
+
+		for k := range c.entries {
+			if len(c.entries) < c.size {
+				break
+			}
+			delete(c.entries, k)
+		}
+	}
+
+	c.entries[key] = cachedParent{parent: parent, found: found, expires: now.Add(c.ttl)}
+}
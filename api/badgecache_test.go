@@ -0,0 +1,119 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/microscaling/microbadger/database"
+)
+
+func TestBadgeCache(t *testing.T) {
+	c := newBadgeCache(2, time.Minute)
+
+	c.put(cachedBadge{key: "a", imageName: "lizrice/one", etag: "1"})
+	c.put(cachedBadge{key: "b", imageName: "lizrice/two", etag: "2"})
+
+	// Using a makes b the least recently used, so it's removed when c is added
+	if _, ok := c.get("a"); !ok {
+		t.Errorf("Expected a to be cached")
+	}
+	c.put(cachedBadge{key: "c", imageName: "lizrice/one", etag: "3"})
+
+	if _, ok := c.get("b"); ok {
+		t.Errorf("Expected b to be removed")
+	}
+
+	cb, ok := c.get("c")
+	if !ok || cb.etag != "3" {
+		t.Errorf("Expected c to be cached, got %v", cb)
+	}
+
+	c.invalidate("lizrice/one")
+	if _, ok := c.get("a"); ok {
+		t.Errorf("Expected a to be invalidated")
+	}
+	if _, ok := c.get("c"); ok {
+		t.Errorf("Expected c to be invalidated")
+	}
+
+	// Badges are only indexed by image while they're cached
+	c.put(cachedBadge{key: "d", imageName: "lizrice/one"})
+	c.put(cachedBadge{key: "d", imageName: "lizrice/two"})
+	if len(c.images) != 1 || len(c.images["lizrice/two"]) != 1 {
+		t.Errorf("Unexpected image index %v", c.images)
+	}
+
+	c.invalidate("lizrice/two")
+	if len(c.images) != 0 || len(c.entries) != 0 || c.order.Len() != 0 {
+		t.Errorf("Expected an empty cache, got %v %v", c.images, c.entries)
+	}
+}
+
+func TestBadgeCacheExpiry(t *testing.T) {
+	c := newBadgeCache(10, -time.Second)
+	c.put(cachedBadge{key: "a", imageName: "lizrice/one"})
+
+	if _, ok := c.get("a"); ok {
+		t.Errorf("Expected a to have expired")
+	}
+
+	c = newBadgeCache(0, time.Minute)
+	c.put(cachedBadge{key: "a", imageName: "lizrice/one"})
+
+	if _, ok := c.get("a"); ok {
+		t.Errorf("Expected nothing to be cached when the size is 0")
+	}
+}
+
+func TestParentCache(t *testing.T) {
+	c := newParentCache(2, time.Minute)
+
+	c.put("lizrice/one@1", database.ImageVersion{ImageName: "library/alpine"}, true)
+	c.put("lizrice/two@2", database.ImageVersion{}, false)
+
+	parent, found, cached := c.get("lizrice/one@1")
+	if !cached || !found || parent.ImageName != "library/alpine" {
+		t.Errorf("Expected the parent to be cached, got %v %t %t", parent, found, cached)
+	}
+
+	// Versions without a parent are cached too
+	if _, found, cached = c.get("lizrice/two@2"); !cached || found {
+		t.Errorf("Expected no parent to be cached, got %t %t", found, cached)
+	}
+
+	if _, _, cached = c.get("lizrice/three@3"); cached {
+		t.Errorf("Expected nothing cached for an unknown version")
+	}
+
+	c.put("lizrice/three@3", database.ImageVersion{}, false)
+	if len(c.entries) != 2 {
+		t.Errorf("Expected the cache to stay at its size, got %d entries", len(c.entries))
+	}
+
+	c = newParentCache(10, -time.Second)
+	c.put("lizrice/one@1", database.ImageVersion{ImageName: "library/alpine"}, true)
+	if _, _, cached = c.get("lizrice/one@1"); cached {
+		t.Errorf("Expected the parent to have expired")
+	}
+}
+
+func TestEtagMatches(t *testing.T) {
+	var tests = []struct {
+		header string
+		match  bool
+	}{
+		{header: "", match: false},
+		{header: `"abc"`, match: true},
+		{header: `W/"abc"`, match: true},
+		{header: `"xyz", "abc"`, match: true},
+		{header: `"xyz"`, match: false},
+		{header: `abc`, match: false},
+		{header: `*`, match: true},
+	}
+
+	for _, test := range tests {
+		if etagMatches(test.header, `"abc"`) != test.match {
+			t.Errorf("If-None-Match %s should match: %t", test.header, test.match)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/microscaling/microbadger/database"
+	"github.com/microscaling/microbadger/inspector"
+)
+
+// ImageVersionDiff compares two versions of an image
+type ImageVersionDiff struct {
+	ImageName string
+	From      DiffVersion
+	To        DiffVersion
+	Identical bool // Both versions have the same layers
+	SizeDelta int64
+	Layers    LayerDiff
+	Labels    LabelDiff
+	Tags      TagDiff
+}
+
+// DiffVersion summarises one of the versions being compared
+type DiffVersion struct {
+	SHA          string
+	Tags         []string `json:",omitempty"`
+	Created      time.Time
+	DownloadSize int64
+	LayerCount   int
+}
+
+// LayerDiff lists the layers that are different. Layers are matched by their command.
+type LayerDiff struct {
+	Unchanged int
+	Added     []database.ImageLayer `json:",omitempty"`
+	Removed   []database.ImageLayer `json:",omitempty"`
+	Changed   []LayerChange         `json:",omitempty"`
+}
+
+// LayerChange is a layer with the same command in both versions but different contents
+type LayerChange struct {
+	Command   string
+	FromSize  int64
+	ToSize    int64
+	SizeDelta int64
+}
+
+// LabelDiff lists the labels that are different
+type LabelDiff struct {
+	Added   map[string]string      `json:",omitempty"`
+	Removed map[string]string      `json:",omitempty"`
+	Changed map[string]LabelChange `json:",omitempty"`
+}
+
+// LabelChange is a label with different values in each version
+type LabelChange struct {
+	From string
+	To   string
+}
+
+// TagDiff lists the tags that are on one version but not the other
+type TagDiff struct {
+	Added   []string `json:",omitempty"`
+	Removed []string `json:",omitempty"`
+}
+
+func handleGetImageVersionDiff(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	img, _, ok := getRequestedImage(w, r)
+	if !ok {
+		return
+	}
+
+	from, err := getImageVersionByTagOrSHA(img, vars["from"])
+	if err != nil {
+		log.Debugf("Version %s of %s not found: %v", vars["from"], img.Name, err)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(constStatusNotFound))
+		return
+	}
+
+	to, err := getImageVersionByTagOrSHA(img, vars["to"])
+	if err != nil {
+		log.Debugf("Version %s of %s not found: %v", vars["to"], img.Name, err)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(constStatusNotFound))
+		return
+	}
+
+	diff := diffImageVersions(from, to)
+	diff.ImageName = strings.TrimPrefix(img.Name, "library/")
+
+	bytes, err := json.Marshal(diff)
+	if err != nil {
+		log.Errorf("Error: %v", err)
+	}
+
+	w.Write([]byte(bytes))
+}
+
+// getImageVersionByTagOrSHA gets the version with all its layers, labels and tags. Unlike
+// getImageVersionBySHA the layers from any parent image are kept.
+func getImageVersionByTagOrSHA(img database.Image, ref string) (iv database.ImageVersion, err error) {
+	iv, err = db.GetImageVersionByTag(img.Name, ref)
+	if err != nil {
+		iv, err = db.GetImageVersionBySHA(strings.TrimPrefix(ref, "sha256:"), img.Name, img.IsPrivate)
+		if err != nil {
+			return
+		}
+	}
+
+	iv.LabelMap, err = getLabelMapFromLabels(&iv)
+	if err != nil {
+		return
+	}
+
+	if len(iv.Layers) > 0 {
+		err = json.Unmarshal([]byte(iv.Layers), &iv.LayersArray)
+		if err != nil {
+			log.Errorf("Error unmarshalling layers %s: %v", iv.Layers, err)
+			return
+		}
+	}
+
+	iv.Tags, err = db.GetTagsList(&iv)
+	return
+}
+
+func diffImageVersions(from database.ImageVersion, to database.ImageVersion) (diff ImageVersionDiff) {
+	diff.From = getDiffVersion(from)
+	diff.To = getDiffVersion(to)
+	diff.SizeDelta = to.DownloadSize - from.DownloadSize
+	diff.Identical = inspector.GetHashFromLayers(from.LayersArray) == inspector.GetHashFromLayers(to.LayersArray)
+	diff.Layers = diffLayers(from.LayersArray, to.LayersArray)
+	diff.Labels = diffLabels(from.LabelMap, to.LabelMap)
+	diff.Tags.Added, diff.Tags.Removed = diffStrings(diff.From.Tags, diff.To.Tags)
+	return
+}
+
+func getDiffVersion(iv database.ImageVersion) DiffVersion {
+	dv := DiffVersion{
+		SHA:          iv.SHA,
+		Created:      iv.Created,
+		DownloadSize: iv.DownloadSize,
+		LayerCount:   iv.LayerCount,
+	}
+
+	for _, t := range iv.Tags {
+		dv.Tags = append(dv.Tags, t.Tag)
+	}
+
+	return dv
+}
+
+// diffLayers skips any layers that the versions have in common at the start, as they're
+// usually built on the same base image. The rest of the layers are matched by their command.
+func diffLayers(from []database.ImageLayer, to []database.ImageLayer) (diff LayerDiff) {
+	common := 0
+	for common < len(from) && common < len(to) && from[common] == to[common] {
+		common++
+	}
+
+	diff.Unchanged = common
+	from = from[common:]
+	to = to[common:]
+
+	matched := make([]bool, len(from))
+	for _, tl := range to {
+		found := false
+		for ii, fl := range from {
+			if matched[ii] || fl.Command == "" || fl.Command != tl.Command {
+				continue
+			}
+
+			matched[ii] = true
+			found = true
+			if fl == tl {
+				diff.Unchanged++
+			} else {
+				diff.Changed = append(diff.Changed, LayerChange{
+					Command:   tl.Command,
+					FromSize:  fl.DownloadSize,
+					ToSize:    tl.DownloadSize,
+					SizeDelta: tl.DownloadSize - fl.DownloadSize,
+				})
+			}
+			break
+		}
+
+		if !found {
+			diff.Added = append(diff.Added, tl)
+		}
+	}
+
+	for ii, fl := range from {
+		if !matched[ii] {
+			diff.Removed = append(diff.Removed, fl)
+		}
+	}
+
+	return
+}
+
+func diffLabels(from map[string]string, to map[string]string) (diff LabelDiff) {
+	for key, value := range to {
+		fromValue, ok := from[key]
+		if !ok {
+			if diff.Added == nil {
+				diff.Added = make(map[string]string)
+			}
+			diff.Added[key] = value
+		} else if fromValue != value {
+			if diff.Changed == nil {
+				diff.Changed = make(map[string]LabelChange)
+			}
+			diff.Changed[key] = LabelChange{From: fromValue, To: value}
+		}
+	}
+
+	for key, value := range from {
+		if _, ok := to[key]; !ok {
+			if diff.Removed == nil {
+				diff.Removed = make(map[string]string)
+			}
+			diff.Removed[key] = value
+		}
+	}
+
+	return
+}
+
+// diffStrings returns the sorted strings that are only in to, and only in from
+func diffStrings(from []string, to []string) (added []string, removed []string) {
+	inFrom := make(map[string]bool, len(from))
+	for _, s := range from {
+		inFrom[s] = true
+	}
+
+	inTo := make(map[string]bool, len(to))
+	for _, s := range to {
+		inTo[s] = true
+		if !inFrom[s] {
+			added = append(added, s)
+		}
+	}
+
+	for _, s := range from {
+		if !inTo[s] {
+			removed = append(removed, s)
+		}
+	}
+
+	sort.Strings(added)
+	sort.Strings(removed)
+	return
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/microscaling/microbadger/database"
+)
+
+const constContinuation = " \\\n    "
+
+func handleGetImageVersionDockerfile(w http.ResponseWriter, r *http.Request) {
+	iv, ok := getRequestedImageVersion(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte(buildDockerfile(iv)))
+}
+
+// buildDockerfile makes a best guess at the Dockerfile for an image version from the commands
+// in its layers. If it's built on another image we know about, the parent's layers have
+// already been removed and become the FROM line.
+func buildDockerfile(iv database.ImageVersion) string {
+	var lines []string
+
+	name := strings.TrimPrefix(iv.ImageName, "library/")
+	lines = append(lines, fmt.Sprintf("# Dockerfile for %s version %s reconstructed from the image history", name, iv.SHA))
+
+	if len(iv.Parents) > 0 {
+		lines = append(lines, "FROM "+getParentReference(iv.Parents[0]))
+	} else {
+		lines = append(lines, "FROM scratch")
+	}
+
+	for _, layer := range iv.LayersArray {
+		cmd := strings.TrimSpace(layer.Command)
+		if cmd == "" {
+			continue
+		}
+
+		lines = append(lines, formatDockerfileCommand(cmd))
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// getParentReference is the image name and tag for the FROM line, using the longest tag
+// as that's likely to be the most specific.
+func getParentReference(parent database.ImageVersion) string {
+	var tag string
+	for _, t := range parent.Tags {
+		if t.Tag != "latest" && len(t.Tag) > len(tag) {
+			tag = t.Tag
+		}
+	}
+
+	if tag == "" {
+		tag = "latest"
+	}
+
+	return strings.TrimPrefix(parent.ImageName, "library/") + ":" + tag
+}
+
+// Long RUN commands are split onto a line for each command so they're easier to read
+func formatDockerfileCommand(cmd string) string {
+	if !strings.HasPrefix(cmd, "RUN ") {
+		return cmd
+	}
+
+	return strings.Join(strings.Split(cmd, " && "), constContinuation+"&& ")
+}
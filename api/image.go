@@ -70,29 +70,46 @@ func getImageNameVars(r *http.Request) (ok bool, image string, registry string)
 	namespace := vars["namespace"]
 	image = vars["image"]
 
-	// Only the Docker registry is supported for the first release
 	registry, regSpecified := vars["registry"]
-	if regSpecified && registry != "docker" {
-		return
-	}
 
 	// If a registry is specified there has to also be both namespace and image name
 	if regSpecified {
 		if namespace == "" || image == "" {
 			return
 		}
-	} else {
-		// IF it's a public official image the namespace doesn't get specified in the URL
-		if namespace == "" {
-			namespace = "library"
+
+		reg, err := db.GetRegistry(registry)
+		if err != nil {
+			log.Debugf("Registry %s not found: %v", registry, err)
+			return
 		}
+
+		image = reg.ImageName(namespace + "/" + image)
+		ok = true
+		return
 	}
 
-	image = namespace + "/" + image
+	// IF it's a public official image the namespace doesn't get specified in the URL
+	if namespace == "" {
+		namespace = "library"
+	}
+
+	image = getImageNameWithHost(vars, namespace+"/"+image)
 	ok = true
 	return
 }
 
+// getImageNameWithHost adds the registry host to the image name if it's in the URL.
+// Docker Hub images are stored without the host.
+func getImageNameWithHost(vars map[string]string, name string) string {
+	host, ok := vars["host"]
+	if !ok {
+		return name
+	}
+
+	return utils.NormalizeImageName(host + "/" + name)
+}
+
 func handleGetImage(w http.ResponseWriter, r *http.Request) {
 	var err error
 	var bytes []byte
@@ -221,26 +238,39 @@ func handleGetImage(w http.ResponseWriter, r *http.Request) {
 
 func handleGetImageVersion(w http.ResponseWriter, r *http.Request) {
 	var bytes []byte
-	vars := mux.Vars(r)
-	u := userFromContext(r.Context())
 
-	ok, image, _ := getImageNameVars(r)
+	iv, ok := getRequestedImageVersion(w, r)
 	if !ok {
-		w.WriteHeader(http.StatusNotFound)
-		w.Write([]byte(constStatusNotFound))
 		return
 	}
 
-	sha := vars["sha"]
-	if sha == "" {
+	// We will return an imageName that doesn't include library/ for official images
+	iv.ImageName = strings.TrimPrefix(iv.ImageName, "library/")
+
+	bytes, err := json.Marshal(iv)
+	if err != nil {
+		log.Errorf("Error: %v", err)
+	}
+
+	w.Write([]byte(bytes))
+}
+
+// getRequestedImage gets the image in the URL if the user has permission to see it and it has
+// been inspected. If not ok the response status has already been written.
+func getRequestedImage(w http.ResponseWriter, r *http.Request) (img database.Image, u *database.User, ok bool) {
+	u = userFromContext(r.Context())
+
+	ok, image, _ := getImageNameVars(r)
+	if !ok {
 		w.WriteHeader(http.StatusNotFound)
 		w.Write([]byte(constStatusNotFound))
 		return
 	}
 
-	img, permission, err := db.GetImageForUser(image, u)
+	ok = false
+	img, permission, _ := db.GetImageForUser(image, u)
 	if !permission {
-		log.Debugf("Error for user %d getting access to image %s", u.ID, image)
+		log.Debugf("Error for user %v getting access to image %s", u, image)
 		w.WriteHeader(http.StatusNotFound)
 		w.Write([]byte(constStatusNotFound))
 		return
@@ -252,21 +282,34 @@ func handleGetImageVersion(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	iv, err := getImageVersionBySHA(sha, img.Name, img.IsPrivate, u)
-	if err != nil {
+	ok = true
+	return
+}
+
+// getRequestedImageVersion gets the image version for the SHA in the URL if the user has
+// permission to see it. If not ok the response status has already been written.
+func getRequestedImageVersion(w http.ResponseWriter, r *http.Request) (iv database.ImageVersion, ok bool) {
+	vars := mux.Vars(r)
+
+	sha := vars["sha"]
+	if sha == "" {
 		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(constStatusNotFound))
 		return
 	}
 
-	// We will return an imageName that doesn't include library/ for official images
-	iv.ImageName = strings.TrimPrefix(iv.ImageName, "library/")
+	img, u, ok := getRequestedImage(w, r)
+	if !ok {
+		return
+	}
 
-	bytes, err = json.Marshal(iv)
+	iv, err := getImageVersionBySHA(sha, img.Name, img.IsPrivate, u)
 	if err != nil {
-		log.Errorf("Error: %v", err)
+		w.WriteHeader(http.StatusNotFound)
+		return iv, false
 	}
 
-	w.Write([]byte(bytes))
+	return
 }
 
 func handleImageSearch(w http.ResponseWriter, r *http.Request) {
@@ -428,6 +471,12 @@ func getLatestFromVersion(img *database.Image, iv *database.ImageVersion) (err e
 		}
 
 		img.LatestTag = getLongestTag(iv)
+
+		img.Platforms, err = db.GetPlatforms(iv)
+		if err != nil {
+			log.Errorf("Error getting platforms for %s: %v", iv.SHA, err)
+			return
+		}
 	}
 	return
 }
@@ -436,7 +485,11 @@ func updateInspectedImage(img database.Image, version database.ImageVersion) (da
 	var err error
 
 	// Set Docker Hub URL. Use the version that has library in it for official images
-	img.ImageURL = fmt.Sprintf("https://%s/r/%s/", imageURL, img.Name)
+	if utils.IsDockerHubImage(img.Name) {
+		img.ImageURL = fmt.Sprintf("https://%s/r/%s/", imageURL, img.Name)
+	} else {
+		img.ImageURL = fmt.Sprintf("https://%s", img.Name)
+	}
 
 	// Get all versions for this image
 	img.Versions, err = db.GetImageVersions(img)
@@ -466,10 +519,17 @@ func updateInspectedImage(img database.Image, version database.ImageVersion) (da
 		}
 		img.Versions[key].ImageName = img.ImageName
 
+		img.Versions[key].Platforms, err = db.GetPlatforms(&iv)
+		if err != nil {
+			log.Errorf("Error getting platforms for %s: %v", img.Name, err)
+			return img, err
+		}
+
 		// Parse JSON only fields from the labels data
 		_, lic, vcs := inspector.ParseLabels(&iv)
 		img.Versions[key].License = lic
 		img.Versions[key].VersionControl = vcs
+		img.Versions[key].Metadata = inspector.ParseMetadata(&iv)
 	}
 
 	// Populate image JSON from either the latest or the tagged version
@@ -108,6 +108,10 @@ func TestGetImageJSON(t *testing.T) {
 		{url: `/v1/images/lizrice/childimage`, status: 200, imageName: "lizrice/childimage", tag: "same", layercount: 4},
 		{url: `/v1/images/official`, status: 200, imageName: "official", tag: "latest"},
 
+		// Docker Hub images can include the registry host
+		{url: `/v1/images/docker.io/lizrice/childimage`, status: 200, imageName: "lizrice/childimage", tag: "same", layercount: 4},
+		{url: `/v1/images/docker.io/lizrice/childimage:specific`, status: 200, imageName: "lizrice/childimage", tag: "specific", layercount: 4},
+
 		// Image still under size inspection
 		{url: `/v1/images/rossf7/size`, status: 200, imageName: "rossf7/size", tag: "latest"},
 
@@ -416,6 +420,279 @@ func TestGetImageVersionJSON(t *testing.T) {
 	}
 }
 
+func TestGetImageVersionDockerfile(t *testing.T) {
+	db = getDatabase(t)
+	emptyDatabase(db)
+	addBadgeThings(db)
+	addImageVersionDetails(db)
+
+	qs = queue.NewMockService()
+	rs = registry.NewService()
+	es = encryption.NewService()
+	ts := httptest.NewServer(muxRoutes())
+	defer ts.Close()
+
+	type test struct {
+		url    string
+		status int
+		body   string
+	}
+
+	var tests = []test{
+		// The parent image layers are replaced by FROM
+		{url: `/v1/images/lizrice/childimage/version/10001/dockerfile`, status: 200,
+			body: "# Dockerfile for lizrice/childimage version 10001 reconstructed from the image history\n" +
+				"FROM another/parentimage:latest\n" +
+				"LABEL com.lizrice.test=olé\n" +
+				"MAINTAINER liz@lizrice.com\n" +
+				"LABEL com.label-schema.license=Apache2.0\n"},
+		{url: `/v1/images/another/parentimage/version/80000/dockerfile`, status: 200,
+			body: "# Dockerfile for another/parentimage version 80000 reconstructed from the image history\n" +
+				"FROM scratch\n" +
+				"ADD file:86864edb9037700501e6e016262c29922e0c67762b4525901ca5a8194a078bfb in /\n" +
+				"MAINTAINER liz@lizrice.com\n"},
+		{url: `/v1/images/rossf7/size/version/60000/dockerfile`, status: 202},
+
+		// Missing images and versions
+		{url: `/v1/images/lizrice/blah/version/10001/dockerfile`, status: 404},
+		{url: `/v1/images/lizrice/childimage/version/abcde/dockerfile`, status: 404},
+
+		// Private images that we shouldn't have access to
+		{url: `/v1/images/otheruser/private/version/50000/dockerfile`, status: 404},
+	}
+
+	for id, test := range tests {
+		res, err := http.Get(ts.URL + test.url)
+		if err != nil {
+			t.Fatalf("Failed to send request #%d (%s) %v", id, test.url, err)
+		}
+
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			t.Errorf("Error getting body. %v", err)
+		}
+
+		if res.StatusCode != test.status {
+			t.Errorf("#%d Unexpected status code %d, wanted %d", id, res.StatusCode, test.status)
+		}
+
+		if test.status == 200 {
+			if string(body) != test.body {
+				t.Errorf("#%d Body is not as expected, have %s\n expected %s", id, body, test.body)
+			}
+
+			ct := res.Header.Get("Content-Type")
+			if ct != "text/plain; charset=utf-8" {
+				t.Errorf("#%d Content type is not as expected, have %s", id, ct)
+			}
+		}
+	}
+}
+
+func TestFormatDockerfileCommand(t *testing.T) {
+	cmd := formatDockerfileCommand("RUN apk update && apk add curl")
+	if cmd != "RUN apk update \\\n    && apk add curl" {
+		t.Errorf("Unexpected command %s", cmd)
+	}
+
+	cmd = formatDockerfileCommand("LABEL a=b && c")
+	if cmd != "LABEL a=b && c" {
+		t.Errorf("Unexpected command %s", cmd)
+	}
+}
+
+func TestGetLayerFiles(t *testing.T) {
+	db = getDatabase(t)
+	emptyDatabase(db)
+	addBadgeThings(db)
+	addImageVersionDetails(db)
+
+	db.PutLayerContents(database.LayerContents{
+		BlobSum:          "sha256:6c123565ed5e79b6c944d6da64bd785ad3ec03c6e853dcb733254aebb215ae55",
+		UncompressedSize: 5120,
+		FileCount:        1,
+		Files:            database.PostgresJSON{RawMessage: json.RawMessage(`{"Name":"/","Type":"dir","Size":6,"Children":[{"Name":"app","Size":6}]}`)},
+	})
+
+	qs = queue.NewMockService()
+	rs = registry.NewService()
+	es = encryption.NewService()
+	ts := httptest.NewServer(muxRoutes())
+	defer ts.Close()
+
+	type test struct {
+		url    string
+		status int
+	}
+
+	var tests = []test{
+		{url: `/v1/images/lizrice/childimage/version/10001/layers/sha256:6c123565ed5e79b6c944d6da64bd785ad3ec03c6e853dcb733254aebb215ae55/files`, status: 200},
+
+		// Layer is in the image but hasn't been inspected
+		{url: `/v1/images/lizrice/childimage/version/10001/layers/sha256:a3ed95caeb02ffe68cdd9fd84406680ae93d633cb16422d00e8a7c22955b46d4/files`, status: 404},
+
+		// Layer isn't in this image
+		{url: `/v1/images/lizrice/childimage/version/10001/layers/sha256:abcde/files`, status: 404},
+		{url: `/v1/images/lizrice/childimage/version/abcde/layers/sha256:6c123565ed5e79b6c944d6da64bd785ad3ec03c6e853dcb733254aebb215ae55/files`, status: 404},
+
+		// Private images that we shouldn't have access to
+		{url: `/v1/images/otheruser/private/version/50000/layers/sha256:6c123565ed5e79b6c944d6da64bd785ad3ec03c6e853dcb733254aebb215ae55/files`, status: 404},
+	}
+
+	for id, test := range tests {
+		res, err := http.Get(ts.URL + test.url)
+		if err != nil {
+			t.Fatalf("Failed to send request #%d (%s) %v", id, test.url, err)
+		}
+
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			t.Errorf("Error getting body. %v", err)
+		}
+
+		if res.StatusCode != test.status {
+			t.Errorf("#%d Unexpected status code %d, wanted %d", id, res.StatusCode, test.status)
+		}
+
+		if test.status == 200 {
+			var lc struct {
+				UncompressedSize int64
+				FileCount        int
+				Files            database.FileNode
+			}
+
+			err = json.Unmarshal(body, &lc)
+			if err != nil {
+				t.Errorf("#%d Error unmarshalling layer files: %v", id, err)
+			}
+
+			if lc.UncompressedSize != 5120 || lc.FileCount != 1 || len(lc.Files.Children) != 1 || lc.Files.Children[0].Name != "app" {
+				t.Errorf("#%d Unexpected layer files %s", id, body)
+			}
+		}
+	}
+}
+
+func TestGetImageVersionDiff(t *testing.T) {
+	db = getDatabase(t)
+	emptyDatabase(db)
+	addBadgeThings(db)
+	addImageVersionDetails(db)
+
+	qs = queue.NewMockService()
+	rs = registry.NewService()
+	es = encryption.NewService()
+	ts := httptest.NewServer(muxRoutes())
+	defer ts.Close()
+
+	type test struct {
+		url    string
+		status int
+		from   string
+		to     string
+	}
+
+	var tests = []test{
+		// Tags and SHAs can be used
+		{url: `/v1/images/lizrice/childimage/diff/latest...specific`, status: 200, from: "10000", to: "10001"},
+		{url: `/v1/images/lizrice/childimage/diff/10000...specific`, status: 200, from: "10000", to: "10001"},
+		{url: `/v1/images/lizrice/childimage/diff/same...10001`, status: 200, from: "10000", to: "10001"},
+		{url: `/v1/images/rossf7/size/diff/latest...latest`, status: 202},
+
+		// Missing images and versions
+		{url: `/v1/images/lizrice/blah/diff/latest...specific`, status: 404},
+		{url: `/v1/images/lizrice/childimage/diff/latest...blah`, status: 404},
+		{url: `/v1/images/lizrice/childimage/diff/blah...latest`, status: 404},
+
+		// Private images that we shouldn't have access to
+		{url: `/v1/images/otheruser/private/diff/latest...latest`, status: 404},
+	}
+
+	for id, test := range tests {
+		res, err := http.Get(ts.URL + test.url)
+		if err != nil {
+			t.Fatalf("Failed to send request #%d (%s) %v", id, test.url, err)
+		}
+
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			t.Errorf("Error getting body. %v", err)
+		}
+
+		if res.StatusCode != test.status {
+			t.Errorf("#%d Unexpected status code %d, wanted %d", id, res.StatusCode, test.status)
+		}
+
+		if test.status == 200 {
+			var diff ImageVersionDiff
+			err = json.Unmarshal(body, &diff)
+			if err != nil {
+				t.Errorf("#%d Error unmarshalling diff: %v", id, err)
+			}
+
+			if diff.ImageName != "lizrice/childimage" || diff.From.SHA != test.from || diff.To.SHA != test.to {
+				t.Errorf("#%d Unexpected diff %v", id, diff)
+			}
+
+			// The versions have the same layers and labels but different tags
+			if !diff.Identical || diff.Layers.Unchanged != 5 || len(diff.Labels.Changed) != 0 {
+				t.Errorf("#%d Unexpected diff %v", id, diff)
+			}
+
+			if len(diff.Tags.Added) != 1 || diff.Tags.Added[0] != "specific" || len(diff.Tags.Removed) != 2 {
+				t.Errorf("#%d Unexpected tags %v", id, diff.Tags)
+			}
+		}
+	}
+}
+
+func TestDiffImageVersions(t *testing.T) {
+	base := database.ImageLayer{BlobSum: "sha256:base", Command: "ADD file:abc in /", DownloadSize: 1000}
+
+	from := database.ImageVersion{
+		SHA:          "1",
+		DownloadSize: 1600,
+		LabelMap:     map[string]string{"a": "1", "b": "2", "c": "3"},
+		LayersArray: []database.ImageLayer{
+			base,
+			{BlobSum: "sha256:apk1", Command: "RUN apk add curl", DownloadSize: 500},
+			{BlobSum: "sha256:copy", Command: "COPY app /app", DownloadSize: 100},
+		},
+	}
+
+	to := database.ImageVersion{
+		SHA:          "2",
+		DownloadSize: 1750,
+		LabelMap:     map[string]string{"a": "1", "b": "20", "d": "4"},
+		LayersArray: []database.ImageLayer{
+			base,
+			{BlobSum: "sha256:copy", Command: "COPY app /app", DownloadSize: 100},
+			{BlobSum: "sha256:apk2", Command: "RUN apk add curl", DownloadSize: 550},
+			{BlobSum: "sha256:user", Command: "USER app", DownloadSize: 0},
+		},
+	}
+
+	diff := diffImageVersions(from, to)
+	if diff.Identical || diff.SizeDelta != 150 {
+		t.Errorf("Unexpected diff %v", diff)
+	}
+
+	if diff.Layers.Unchanged != 2 || len(diff.Layers.Added) != 1 || diff.Layers.Added[0].Command != "USER app" || len(diff.Layers.Removed) != 0 {
+		t.Errorf("Unexpected layers %v", diff.Layers)
+	}
+
+	if len(diff.Layers.Changed) != 1 || diff.Layers.Changed[0].SizeDelta != 50 {
+		t.Errorf("Unexpected changed layers %v", diff.Layers.Changed)
+	}
+
+	if diff.Labels.Added["d"] != "4" || diff.Labels.Removed["c"] != "3" || diff.Labels.Changed["b"].To != "20" || len(diff.Labels.Changed) != 1 {
+		t.Errorf("Unexpected labels %v", diff.Labels)
+	}
+}
+
 func TestImageSearch(t *testing.T) {
 	os.Setenv("MB_CORS_ORIGIN", "http://mydomain")
 
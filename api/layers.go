@@ -0,0 +1,61 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/microscaling/microbadger/database"
+)
+
+// handleGetLayerFiles returns the file tree for a layer of an image version, if the layer contents have been inspected
+func handleGetLayerFiles(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	digest := vars["digest"]
+
+	iv, ok := getRequestedImageVersion(w, r)
+	if !ok {
+		return
+	}
+
+	// Only layers in this version can be requested, so private layers stay private
+	var layers []database.ImageLayer
+	if len(iv.Layers) > 0 {
+		err := json.Unmarshal([]byte(iv.Layers), &layers)
+		if err != nil {
+			log.Errorf("Error unmarshalling layers %s: %v", iv.Layers, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	found := false
+	for _, l := range layers {
+		if l.BlobSum == digest {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(constStatusNotFound))
+		return
+	}
+
+	lc, err := db.GetLayerContents(digest)
+	if err != nil {
+		log.Debugf("No contents for layer %s: %v", digest, err)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(constStatusNotFound))
+		return
+	}
+
+	bytes, err := json.Marshal(lc)
+	if err != nil {
+		log.Errorf("Error: %v", err)
+	}
+
+	w.Write([]byte(bytes))
+}
@@ -329,8 +329,15 @@ func handleUserImagePermissions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	reg, err := db.GetRegistry(regID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(constStatusNotFound))
+		return
+	}
+
 	u := userFromContext(r.Context())
-	image = namespace + "/" + image
+	image = reg.ImageName(namespace + "/" + image)
 
 	switch r.Method {
 	case "PUT":
@@ -849,6 +856,7 @@ func inspectNewImage(image string) (err error) {
 		img, err := db.GetOrCreateImage(image)
 		if err == nil {
 			img.Status = "SUBMITTED"
+			img.StatusReason = ""
 			err = db.PutImageOnly(img)
 		}
 	}
@@ -0,0 +1,260 @@
+// Package badge renders the SVG badges for images.
+package badge
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Default colours for the two halves of the badge
+const (
+	DefaultLabelColor = "#555"
+	DefaultColor      = "#007ec6"
+)
+
+const (
+	constHeight = 20
+
+	// Space either side of the text in each half of the badge
+	constPadding = 5
+
+	constFontFamily = "Verdana,Geneva,DejaVu Sans,sans-serif"
+)
+
+// Badge has a label on the left and a value on the right
+type Badge struct {
+	Label      string
+	Value      string
+	LabelColor string
+	Color      string
+	Logo       Logo
+	Style      Style
+}
+
+// New creates a badge with the default colours and style
+func New(label string, value string) Badge {
+	return Badge{
+		Label:      label,
+		Value:      value,
+		LabelColor: DefaultLabelColor,
+		Color:      DefaultColor,
+		Style:      StyleFlat,
+	}
+}
+
+// measureFunc is the width in pixels of text in the font that will draw it
+type measureFunc func(s string, fontSize float64, letterSpacing float64) float64
+
+// SVG renders the badge in its style. Each half is sized to fit its text.
+func (b Badge) SVG() string {
+	return b.render(textWidth)
+}
+
+// render lays out the badge with text measured by the measure function
+func (b Badge) render(measure measureFunc) string {
+	switch b.Style {
+	case StyleFlatSquare:
+		return b.flatSquare(measure)
+	case StylePlastic:
+		return b.plastic(measure)
+	case StyleForTheBadge:
+		return b.forTheBadge(measure)
+	case StyleSocial:
+		return b.social(measure)
+	default:
+		return b.flat(measure)
+	}
+}
+
+func (b Badge) flat(measure measureFunc) string {
+	labelWidth, labelX := b.labelLayout(b.Label, constFontSize, 0, constPadding, measure)
+	valueWidth := segmentWidth(b.Value, constFontSize, 0, constPadding, measure)
+	width := labelWidth + valueWidth
+
+	var sb strings.Builder
+	writeHeader(&sb, width, constHeight)
+	sb.WriteString(`<linearGradient id="b" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`)
+	fmt.Fprintf(&sb, `<mask id="a"><rect width="%d" height="%d" rx="3" fill="#fff"/></mask>`, width, constHeight)
+	sb.WriteString(`<g mask="url(#a)">`)
+	writeSegments(&sb, b, labelWidth, valueWidth, constHeight)
+	fmt.Fprintf(&sb, `<path fill="url(#b)" d="M0 0h%dv%dH0z"/></g>`, width, constHeight)
+	writeLogo(&sb, b.Logo, constPadding, 3, "#fff")
+	writeTextGroup(&sb, "#fff", constFontSize, 0)
+	writeShadowText(&sb, labelX, 15, b.Label)
+	writeShadowText(&sb, float64(labelWidth)+float64(valueWidth)/2, 15, b.Value)
+	sb.WriteString(`</g></svg>`)
+
+	return sb.String()
+}
+
+// flatSquare is flat without the gradient or rounded corners
+func (b Badge) flatSquare(measure measureFunc) string {
+	labelWidth, labelX := b.labelLayout(b.Label, constFontSize, 0, constPadding, measure)
+	valueWidth := segmentWidth(b.Value, constFontSize, 0, constPadding, measure)
+	width := labelWidth + valueWidth
+
+	var sb strings.Builder
+	writeHeader(&sb, width, constHeight)
+	sb.WriteString(`<g shape-rendering="crispEdges">`)
+	writeSegments(&sb, b, labelWidth, valueWidth, constHeight)
+	sb.WriteString(`</g>`)
+	writeLogo(&sb, b.Logo, constPadding, 3, "#fff")
+	writeTextGroup(&sb, "#fff", constFontSize, 0)
+	writeText(&sb, labelX, 14, b.Label, "")
+	writeText(&sb, float64(labelWidth)+float64(valueWidth)/2, 14, b.Value, "")
+	sb.WriteString(`</g></svg>`)
+
+	return sb.String()
+}
+
+// plastic is shorter than flat and has a glossier gradient
+func (b Badge) plastic(measure measureFunc) string {
+	const height = 18
+
+	labelWidth, labelX := b.labelLayout(b.Label, constFontSize, 0, constPadding, measure)
+	valueWidth := segmentWidth(b.Value, constFontSize, 0, constPadding, measure)
+	width := labelWidth + valueWidth
+
+	var sb strings.Builder
+	writeHeader(&sb, width, height)
+	sb.WriteString(`<linearGradient id="b" x2="0" y2="100%"><stop offset="0" stop-color="#fff" stop-opacity=".7"/><stop offset=".1" stop-color="#aaa" stop-opacity=".1"/><stop offset=".9" stop-opacity=".3"/><stop offset="1" stop-opacity=".5"/></linearGradient>`)
+	fmt.Fprintf(&sb, `<mask id="a"><rect width="%d" height="%d" rx="4" fill="#fff"/></mask>`, width, height)
+	sb.WriteString(`<g mask="url(#a)">`)
+	writeSegments(&sb, b, labelWidth, valueWidth, height)
+	fmt.Fprintf(&sb, `<path fill="url(#b)" d="M0 0h%dv%dH0z"/></g>`, width, height)
+	writeLogo(&sb, b.Logo, constPadding, 2, "#fff")
+	writeTextGroup(&sb, "#fff", constFontSize, 0)
+	writeShadowText(&sb, labelX, 14, b.Label)
+	writeShadowText(&sb, float64(labelWidth)+float64(valueWidth)/2, 14, b.Value)
+	sb.WriteString(`</g></svg>`)
+
+	return sb.String()
+}
+
+// forTheBadge is taller with square corners and spaced out capitals
+func (b Badge) forTheBadge(measure measureFunc) string {
+	const (
+		height        = 28
+		fontSize      = 10
+		letterSpacing = 1
+		padding       = 10
+	)
+
+	label := strings.ToUpper(b.Label)
+	value := strings.ToUpper(b.Value)
+
+	labelWidth, labelX := b.labelLayout(label, fontSize, letterSpacing, padding, measure)
+	valueWidth := segmentWidth(value, fontSize, letterSpacing, padding, measure)
+	width := labelWidth + valueWidth
+
+	var sb strings.Builder
+	writeHeader(&sb, width, height)
+	sb.WriteString(`<g shape-rendering="crispEdges">`)
+	writeSegments(&sb, b, labelWidth, valueWidth, height)
+	sb.WriteString(`</g>`)
+	writeLogo(&sb, b.Logo, padding, 7, "#fff")
+	writeTextGroup(&sb, "#fff", fontSize, letterSpacing)
+	writeText(&sb, labelX, 18, label, "")
+	writeText(&sb, float64(labelWidth)+float64(valueWidth)/2, 18, value, "")
+	sb.WriteString(`</g></svg>`)
+
+	return sb.String()
+}
+
+// social looks like a GitHub button, with the value in a speech bubble. The colours are
+// fixed so they aren't used.
+func (b Badge) social(measure measureFunc) string {
+	const arrow = 6
+
+	labelWidth, labelX := b.labelLayout(b.Label, constFontSize, 0, constPadding, measure)
+	valueWidth := segmentWidth(b.Value, constFontSize, 0, constPadding, measure)
+	bubbleX := labelWidth + arrow - 1
+	width := bubbleX + valueWidth + 1
+
+	var sb strings.Builder
+	writeHeader(&sb, width, constHeight)
+	sb.WriteString(`<linearGradient id="b" x2="0" y2="100%"><stop offset="0" stop-color="#fcfcfc" stop-opacity="0"/><stop offset="1" stop-opacity=".1"/></linearGradient>`)
+	sb.WriteString(`<g stroke="#d5d5d5">`)
+	fmt.Fprintf(&sb, `<rect stroke="none" fill="#fcfcfc" x=".5" y=".5" width="%d" height="19" rx="2"/>`, labelWidth-1)
+	fmt.Fprintf(&sb, `<rect fill="url(#b)" x=".5" y=".5" width="%d" height="19" rx="2"/>`, labelWidth-1)
+	fmt.Fprintf(&sb, `<rect fill="#fafafa" x="%d.5" y=".5" width="%d" height="19" rx="2"/>`, bubbleX, valueWidth)
+	fmt.Fprintf(&sb, `<path fill="#fafafa" d="M%d.5 6.5l-3 3v1l3 3"/>`, bubbleX)
+	fmt.Fprintf(&sb, `<path stroke="#fafafa" fill="#fafafa" d="M%d.5 7.5l-3 3 3 3"/>`, bubbleX)
+	sb.WriteString(`</g>`)
+	writeLogo(&sb, b.Logo, constPadding, 3, "#333")
+	writeTextGroup(&sb, "#333", constFontSize, 0)
+	writeText(&sb, labelX, 15, b.Label, ` fill="#fff"`)
+	writeText(&sb, labelX, 14, b.Label, "")
+	writeText(&sb, float64(bubbleX)+float64(valueWidth)/2+.5, 15, b.Value, ` fill="#fff"`)
+	writeText(&sb, float64(bubbleX)+float64(valueWidth)/2+.5, 14, b.Value, "")
+	sb.WriteString(`</g></svg>`)
+
+	return sb.String()
+}
+
+// labelLayout works out the width of the left half of the badge and where to centre the label.
+// The logo goes before the label. If there's no label or logo there's no left half at all.
+func (b Badge) labelLayout(label string, fontSize float64, letterSpacing float64, padding int, measure measureFunc) (width int, textX float64) {
+	_, hasLogo := logoPaths[b.Logo]
+	if label == "" && !hasLogo {
+		return 0, 0
+	}
+
+	var logoWidth, labelWidth int
+	if hasLogo {
+		logoWidth = constLogoSize
+		if label != "" {
+			logoWidth += constLogoGap
+		}
+	}
+
+	if label != "" {
+		labelWidth = int(math.Ceil(measure(label, fontSize, letterSpacing)))
+	}
+
+	width = padding + logoWidth + labelWidth + padding
+	textX = float64(padding+logoWidth) + float64(labelWidth)/2
+	return
+}
+
+// segmentWidth is the width of half of the badge including the padding
+func segmentWidth(text string, fontSize float64, letterSpacing float64, padding int, measure measureFunc) int {
+	return int(math.Ceil(measure(text, fontSize, letterSpacing))) + 2*padding
+}
+
+func writeHeader(sb *strings.Builder, width int, height int) {
+	fmt.Fprintf(sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d">`, width, height)
+}
+
+// writeSegments writes the coloured background for each half of the badge
+func writeSegments(sb *strings.Builder, b Badge, labelWidth int, valueWidth int, height int) {
+	if labelWidth > 0 {
+		fmt.Fprintf(sb, `<path fill="%s" d="M0 0h%dv%dH0z"/>`, escape(b.LabelColor), labelWidth, height)
+	}
+	fmt.Fprintf(sb, `<path fill="%s" d="M%d 0h%dv%dH%dz"/>`, escape(b.Color), labelWidth, valueWidth, height, labelWidth)
+}
+
+func writeTextGroup(sb *strings.Builder, fill string, fontSize int, letterSpacing int) {
+	fmt.Fprintf(sb, `<g fill="%s" text-anchor="middle" font-family="%s" font-size="%d"`, fill, constFontFamily, fontSize)
+	if letterSpacing != 0 {
+		fmt.Fprintf(sb, ` letter-spacing="%d"`, letterSpacing)
+	}
+	sb.WriteString(">")
+}
+
+// writeShadowText writes the text with a shadow underneath it
+func writeShadowText(sb *strings.Builder, x float64, y int, text string) {
+	writeText(sb, x, y, text, ` fill="#010101" fill-opacity=".3"`)
+	writeText(sb, x, y-1, text, "")
+}
+
+func writeText(sb *strings.Builder, x float64, y int, text string, attrs string) {
+	if text == "" {
+		return
+	}
+
+	pos := strconv.FormatFloat(x, 'f', -1, 64)
+	fmt.Fprintf(sb, `<text x="%s" y="%d"%s>%s</text>`, pos, y, attrs, escape(text))
+}
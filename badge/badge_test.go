@@ -0,0 +1,354 @@
+package badge
+
+import (
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"image/png"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// checkGolden compares the output with the file in testdata, or updates the file if -update is set
+func checkGolden(t *testing.T, name string, got string) {
+	t.Helper()
+
+	golden := filepath.Join("testdata", name)
+	if *update {
+		err := ioutil.WriteFile(golden, []byte(got), 0644)
+		if err != nil {
+			t.Fatalf("Failed to update %s: %v", golden, err)
+		}
+	}
+
+	want, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", golden, err)
+	}
+
+	if got != string(want) {
+		t.Errorf("%s doesn't match:\n got %s\nwant %s", name, got, want)
+	}
+}
+
+func TestBadgeSVG(t *testing.T) {
+	var tests = []struct {
+		name  string
+		badge Badge
+	}{
+		// The badge types we serve
+		{name: "image.svg", badge: New("18.6MB", "9 layers")},
+		{name: "commit.svg", badge: New("commit", "eebf408")},
+		{name: "version.svg", badge: New("version", "latest")},
+		{name: "license.svg", badge: New("license", "Apache-2.0")},
+		{name: "arch.svg", badge: New("arch", "amd64 | arm64 | arm/v7")},
+		{name: "imagemissing.svg", badge: New("Image", "not found")},
+		{name: "tagmissing.svg", badge: New("1.0", "not found")},
+
+		// Multi-byte characters and text that must be escaped
+		{name: "unicode.svg", badge: New("version", "olé-ñ…")},
+		{name: "cjk.svg", badge: New("version", "日本語")},
+		{name: "escaped.svg", badge: New("<label>", `"a" & 'b'`)},
+	}
+
+	for _, test := range tests {
+		checkGolden(t, test.name, test.badge.SVG())
+	}
+}
+
+func TestBadgeStyles(t *testing.T) {
+	for _, style := range styles {
+		for _, name := range []string{"version", "image"} {
+			b := New("version", "1.2.3-ñ")
+			if name == "image" {
+				b = New("18.6MB", "9 layers")
+			}
+
+			b.Style = style
+			checkGolden(t, "style-"+string(style)+"-"+name+".svg", b.SVG())
+		}
+	}
+}
+
+func TestBadgeLogos(t *testing.T) {
+	var tests = []struct {
+		name  string
+		badge Badge
+	}{
+		{name: "logo-docker.svg", badge: Badge{Label: "image", Value: "9 layers", LabelColor: "#555", Color: "#4c1", Logo: LogoDocker}},
+		{name: "logo-git.svg", badge: Badge{Label: "commit", Value: "eebf408", LabelColor: "#24292e", Color: "#e05d44", Logo: LogoGit, Style: StylePlastic}},
+		{name: "logo-license.svg", badge: Badge{Label: "license", Value: "MIT", LabelColor: "#555", Color: "#007ec6", Logo: LogoLicense, Style: StyleForTheBadge}},
+		{name: "logo-only.svg", badge: Badge{Value: "latest", LabelColor: "#555", Color: "#007ec6", Logo: LogoDocker, Style: StyleFlatSquare}},
+		{name: "logo-social.svg", badge: Badge{Label: "stars", Value: "42", Logo: LogoGit, Style: StyleSocial}},
+		{name: "no-label.svg", badge: Badge{Value: "latest", LabelColor: "#555", Color: "#007ec6"}},
+	}
+
+	for _, test := range tests {
+		checkGolden(t, test.name, test.badge.SVG())
+	}
+}
+
+func TestParseStyle(t *testing.T) {
+	var tests = []struct {
+		name  string
+		style Style
+		err   bool
+	}{
+		{name: "", style: StyleFlat},
+		{name: "flat", style: StyleFlat},
+		{name: "flat-square", style: StyleFlatSquare},
+		{name: "plastic", style: StylePlastic},
+		{name: "for-the-badge", style: StyleForTheBadge},
+		{name: "social", style: StyleSocial},
+		{name: "Flat", err: true},
+		{name: "popout", err: true},
+	}
+
+	for _, test := range tests {
+		style, err := ParseStyle(test.name)
+		if (err != nil) != test.err {
+			t.Errorf("Unexpected error for style %s: %v", test.name, err)
+		}
+
+		if style != test.style {
+			t.Errorf("Style %s parsed as %s, expected %s", test.name, style, test.style)
+		}
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	var tests = []struct {
+		text  string
+		width float64
+	}{
+		{text: "", width: 0},
+		{text: "i", width: 562.0 * 11 / 2048},
+		{text: "version", width: 7473.0 * 11 / 2048},
+		// é isn't in the Verdana table so comes from DejaVu Sans
+		{text: "é", width: 1260.0 * 11 / 2048},
+		// Combining acute accent takes no space
+		{text: "é", width: 1220.0 * 11 / 2048},
+		{text: "日", width: 11},
+	}
+
+	for _, test := range tests {
+		w := TextWidth(test.text)
+		if w != test.width {
+			t.Errorf("Width of %q is %f, expected %f", test.text, w, test.width)
+		}
+	}
+
+	if TextWidth("WWW") <= TextWidth("iii") {
+		t.Errorf("Wide letters should be wider than narrow ones")
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	var tests = []struct {
+		text   string
+		length int
+		result string
+	}{
+		{text: "latest", length: 10, result: "latest"},
+		{text: "1234567890", length: 10, result: "1234567890"},
+		{text: "12345678901", length: 10, result: "123456789…"},
+		{text: "ñññññññññññ", length: 10, result: "ñññññññññ…"},
+		{text: "日本語のタグ", length: 4, result: "日本語…"},
+	}
+
+	for _, test := range tests {
+		result := Truncate(test.text, test.length)
+		if result != test.result {
+			t.Errorf("Truncate(%q, %d) is %q, expected %q", test.text, test.length, result, test.result)
+		}
+
+		if !strings.HasPrefix(test.text, strings.TrimSuffix(result, constEllipsis)) {
+			t.Errorf("Truncated text %q isn't a prefix of %q", result, test.text)
+		}
+	}
+}
+
+func TestBadgeJSON(t *testing.T) {
+	b := New("version", "1.2.3")
+	b.Logo = LogoDocker
+
+	got, err := b.JSON()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := `{"schemaVersion":1,"label":"version","message":"1.2.3","color":"007ec6","labelColor":"555","namedLogo":"docker","style":"flat"}`
+	if string(got) != want {
+		t.Errorf("JSON is %s, expected %s", got, want)
+	}
+}
+
+func TestBadgePNG(t *testing.T) {
+	for _, style := range styles {
+		b := New("version", "1.2.3-ñ")
+		b.Style = style
+		b.Logo = LogoGit
+
+		data, err := b.PNG()
+		if err != nil {
+			t.Fatalf("Unexpected error for style %s: %v", style, err)
+		}
+
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("Can't decode PNG for style %s: %v", style, err)
+		}
+
+		svg := b.render(pngTextWidth)
+		var size struct {
+			Width  int `xml:"width,attr"`
+			Height int `xml:"height,attr"`
+		}
+		xml.Unmarshal([]byte(svg), &size)
+
+		bounds := img.Bounds()
+		if bounds.Dx() != size.Width || bounds.Dy() != size.Height {
+			t.Errorf("PNG for style %s is %dx%d, expected %dx%d", style, bounds.Dx(), bounds.Dy(), size.Width, size.Height)
+		}
+
+		// The value half of the badge should be drawn in the badge colour
+		if style != StyleSocial {
+			r, g, b, a := img.At(bounds.Dx()-3, bounds.Dy()/2).RGBA()
+			if a>>8 != 0xff || r>>8 > 0x20 || g>>8 < 0x60 || b>>8 < 0xa0 {
+				t.Errorf("PNG for style %s has colour %x %x %x %x in the value half", style, r>>8, g>>8, b>>8, a>>8)
+			}
+		}
+	}
+}
+
+func TestBadgePNGGolden(t *testing.T) {
+	var tests = []struct {
+		name  string
+		badge Badge
+	}{
+		{name: "version.png", badge: New("version", "latest")},
+		{name: "arch.png", badge: New("arch", "amd64 | arm64 | arm/v7")},
+		{name: "style-for-the-badge-version.png", badge: Badge{Label: "version", Value: "1.2.3-ñ", LabelColor: "#555", Color: "#007ec6", Style: StyleForTheBadge}},
+	}
+
+	for _, test := range tests {
+		data, err := test.badge.PNG()
+		if err != nil {
+			t.Fatalf("Unexpected error for %s: %v", test.name, err)
+		}
+
+		golden := filepath.Join("testdata", test.name)
+		if *update {
+			err = ioutil.WriteFile(golden, data, 0644)
+			if err != nil {
+				t.Fatalf("Failed to update %s: %v", golden, err)
+			}
+		}
+
+		want, err := ioutil.ReadFile(golden)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", golden, err)
+		}
+
+		if !bytes.Equal(data, want) {
+			t.Errorf("%s doesn't match", test.name)
+		}
+	}
+}
+
+func TestBadgePNGTextCentred(t *testing.T) {
+	b := New("WWWWWW", "iiiiii")
+	data, err := b.PNG()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Can't decode PNG: %v", err)
+	}
+
+	labelWidth, _ := b.labelLayout(b.Label, constFontSize, 0, constPadding, pngTextWidth)
+	bounds := img.Bounds()
+
+	// The white text in each half should have about the same space either side of it
+	for _, half := range [][2]int{{0, labelWidth}, {labelWidth, bounds.Dx()}} {
+		left, right := -1, -1
+		for x := half[0]; x < half[1]; x++ {
+			for y := 0; y < bounds.Dy(); y++ {
+				if r, _, _, _ := img.At(x, y).RGBA(); r>>8 > 0xc0 {
+					if left < 0 {
+						left = x
+					}
+					right = x
+					break
+				}
+			}
+		}
+
+		if left < 0 {
+			t.Fatalf("No text between %d and %d", half[0], half[1])
+		}
+
+		before, after := left-half[0], half[1]-1-right
+		if before < 2 || after < 2 || before-after > 2 || after-before > 2 {
+			t.Errorf("Text between %d and %d is at %d to %d, not centred", half[0], half[1], left, right)
+		}
+	}
+}
+
+func TestBadgePNGConcurrent(t *testing.T) {
+	want, err := New("version", "1.2.3").PNG()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Run with -race to check that renders don't share font state
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, err := New("version", "1.2.3").PNG()
+			if err != nil || !bytes.Equal(data, want) {
+				t.Errorf("Concurrent render is different, error %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestParsePath(t *testing.T) {
+	var tests = []struct {
+		d    string
+		path []subpath
+		err  bool
+	}{
+		{d: "M0 0h2v3H0z", path: []subpath{{{'M', []float64{0, 0}}, {'L', []float64{2, 0}}, {'L', []float64{2, 3}}, {'L', []float64{0, 3}}}}},
+		{d: "M7 .5L13.5 7 7 13.5.5 7z", path: []subpath{{{'M', []float64{7, .5}}, {'L', []float64{13.5, 7}}, {'L', []float64{7, 13.5}}, {'L', []float64{.5, 7}}}}},
+		{d: "M1 1c.5-.5 1 0 1 1zm1 0l1 1", path: []subpath{
+			{{'M', []float64{1, 1}}, {'C', []float64{1.5, .5, 2, 1, 2, 2}}},
+			{{'M', []float64{2, 1}}, {'L', []float64{3, 2}}},
+		}},
+		{d: "M0 0a1 1 0 1 1 2 0", err: true},
+		{d: "0 0", err: true},
+		{d: "M0", err: true},
+	}
+
+	for _, test := range tests {
+		path, err := parsePath(test.d)
+		if (err != nil) != test.err {
+			t.Errorf("Unexpected error for %q: %v", test.d, err)
+		}
+
+		if !reflect.DeepEqual(path, test.path) {
+			t.Errorf("Path %q parsed as %v, expected %v", test.d, path, test.path)
+		}
+	}
+}
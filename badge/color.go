@@ -0,0 +1,44 @@
+package badge
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Named colours are the same as shields.io so badges match
+var namedColors = map[string]string{
+	"brightgreen":   "#4c1",
+	"green":         "#97ca00",
+	"yellowgreen":   "#a4a61d",
+	"yellow":        "#dfb317",
+	"orange":        "#fe7d37",
+	"red":           "#e05d44",
+	"blue":          "#007ec6",
+	"grey":          "#555",
+	"gray":          "#555",
+	"lightgrey":     "#9f9f9f",
+	"lightgray":     "#9f9f9f",
+	"success":       "#4c1",
+	"important":     "#fe7d37",
+	"critical":      "#e05d44",
+	"informational": "#007ec6",
+	"inactive":      "#9f9f9f",
+}
+
+// Hex colours can have 3 or 6 digits, and the # is optional as it has to be escaped in URLs
+var hexColorRe = regexp.MustCompile(`^#?([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// ParseColor converts a colour name or hex value to the hex value to use in the badge
+func ParseColor(color string) (string, error) {
+	if hex, ok := namedColors[strings.ToLower(color)]; ok {
+		return hex, nil
+	}
+
+	m := hexColorRe.FindStringSubmatch(color)
+	if m == nil {
+		return "", fmt.Errorf("Invalid color %s", color)
+	}
+
+	return "#" + strings.ToLower(m[1]), nil
+}
@@ -0,0 +1,39 @@
+package badge
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// constEndpointSchemaVersion is the version of the shields.io endpoint schema we produce
+const constEndpointSchemaVersion = 1
+
+// Endpoint is the badge in the shields.io endpoint schema, so shields.io can render it
+// in any of its styles. See https://shields.io/endpoint
+type Endpoint struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color,omitempty"`
+	LabelColor    string `json:"labelColor,omitempty"`
+	NamedLogo     string `json:"namedLogo,omitempty"`
+	Style         string `json:"style,omitempty"`
+}
+
+// Endpoint converts the badge to the shields.io endpoint schema
+func (b Badge) Endpoint() Endpoint {
+	return Endpoint{
+		SchemaVersion: constEndpointSchemaVersion,
+		Label:         b.Label,
+		Message:       b.Value,
+		Color:         strings.TrimPrefix(b.Color, "#"),
+		LabelColor:    strings.TrimPrefix(b.LabelColor, "#"),
+		NamedLogo:     string(b.Logo),
+		Style:         string(b.Style),
+	}
+}
+
+// JSON renders the badge in the shields.io endpoint schema
+func (b Badge) JSON() ([]byte, error) {
+	return json.Marshal(b.Endpoint())
+}
@@ -0,0 +1,133 @@
+//go:build ignore
+// +build ignore
+
+// gen_widths.go writes the DejaVu Sans width table from the font file. The table was
+// generated from DejaVuSans.ttf version 2.37, from https://dejavu-fonts.github.io/ or the
+// fonts-dejavu-core package on Debian and Ubuntu, which installs it at the default path.
+// Run it with go generate, or go run gen_widths.go -font <file> to use another copy.
+//
+// Verdana can't be redistributed, so the Verdana widths in widths.go were copied from
+// verdana.ttf in Microsoft's Core fonts for the Web. Pass that file with -verdana to print
+// its widths, to check them against the table.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// Unicode blocks we have widths for. Anything else is measured as a wide character.
+var blocks = []struct {
+	first, last rune
+	name        string
+}{
+	{0x0020, 0x007e, "Basic Latin"},
+	{0x00a0, 0x024f, "Latin-1 Supplement, Latin Extended-A and B"},
+	{0x0370, 0x03ff, "Greek"},
+	{0x0400, 0x04ff, "Cyrillic"},
+	{0x2000, 0x206f, "General Punctuation"},
+	{0x20a0, 0x20bf, "Currency Symbols"},
+	{0x2100, 0x214f, "Letterlike Symbols"},
+	{0x2190, 0x21ff, "Arrows"},
+}
+
+func main() {
+	fontFile := flag.String("font", "/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf", "DejaVu Sans font file")
+	verdanaFile := flag.String("verdana", "", "Verdana font file, to print its widths instead")
+	out := flag.String("out", "widths_dejavu.go", "output file")
+	flag.Parse()
+
+	if *verdanaFile != "" {
+		f, _ := parseFont(*verdanaFile)
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, "unitsPerEm: %d,\n", f.UnitsPerEm())
+		writeWidths(&buf, f, blocks[0].first, blocks[0].last)
+		fmt.Print(buf.String())
+		return
+	}
+
+	f, version := parseFont(*fontFile)
+
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, "// Code generated by gen_widths.go; DO NOT EDIT.")
+	fmt.Fprintf(&buf, "// From DejaVu Sans %s.\n", version)
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, "package badge")
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, "// dejaVuSansWidths are the advance widths of DejaVu Sans glyphs in font units")
+	fmt.Fprintln(&buf, "var dejaVuSansWidths = widthTable{")
+	fmt.Fprintf(&buf, "unitsPerEm: %d,\n", f.UnitsPerEm())
+	fmt.Fprintln(&buf, "ranges: []widthRange{")
+
+	for _, block := range blocks {
+		fmt.Fprintf(&buf, "// %s\n", block.name)
+		writeWidths(&buf, f, block.first, block.last)
+	}
+
+	fmt.Fprintln(&buf, "},")
+	fmt.Fprintln(&buf, "}")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("Error formatting source: %v", err)
+	}
+
+	err = ioutil.WriteFile(*out, src, 0644)
+	if err != nil {
+		log.Fatalf("Error writing %s: %v", *out, err)
+	}
+}
+
+// parseFont reads the font file and returns the font and its version
+func parseFont(fontFile string) (*sfnt.Font, string) {
+	data, err := ioutil.ReadFile(fontFile)
+	if err != nil {
+		log.Fatalf("Error reading font: %v", err)
+	}
+
+	f, err := sfnt.Parse(data)
+	if err != nil {
+		log.Fatalf("Error parsing font: %v", err)
+	}
+
+	version, err := f.Name(nil, sfnt.NameIDVersion)
+	if err != nil {
+		log.Fatalf("Error getting font version: %v", err)
+	}
+
+	return f, version
+}
+
+// writeWidths writes the widthRange for the runes
+func writeWidths(buf *bytes.Buffer, f *sfnt.Font, first rune, last rune) {
+	var b sfnt.Buffer
+	unitsPerEm := f.UnitsPerEm()
+
+	fmt.Fprintf(buf, "{first: 0x%04x, widths: []uint16{", first)
+	for r := first; r <= last; r++ {
+		if (r-first)%16 == 0 {
+			fmt.Fprintln(buf)
+		}
+
+		// Zero means there's no glyph for this rune
+		var width fixed.Int26_6
+		gi, err := f.GlyphIndex(&b, r)
+		if err == nil && gi != 0 {
+			width, err = f.GlyphAdvance(&b, gi, fixed.Int26_6(unitsPerEm), font.HintingNone)
+			if err != nil {
+				log.Fatalf("Error getting advance for %U: %v", r, err)
+			}
+		}
+
+		fmt.Fprintf(buf, "%d, ", width)
+	}
+	fmt.Fprintln(buf, "\n}},")
+}
@@ -0,0 +1,64 @@
+package badge
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Logo is one of the built-in icons that can go before the label
+type Logo string
+
+// The logos we have icons for
+const (
+	LogoNone    Logo = ""
+	LogoDocker  Logo = "docker"
+	LogoGit     Logo = "git"
+	LogoLicense Logo = "license"
+)
+
+const (
+	// Icons are drawn in a square this size
+	constLogoSize = 14
+
+	// Space between the logo and the label
+	constLogoGap = 3
+)
+
+// logoPaths are the icons as SVG path data in a 14x14 box. They're drawn with the
+// even-odd fill rule so they only need a single colour. Only the path commands that
+// the PNG rasterizer understands can be used.
+var logoPaths = map[Logo]string{
+	LogoDocker: "M1 6h2v2H1zM3.5 6h2v2h-2zM6 6h2v2H6zM8.5 6h2v2h-2zM3.5 3.5h2v2h-2zM6 3.5h2v2H6zM6 1h2v2H6z" +
+		"M0 8.5h12.2c.5-.9 1.3-1.4 1.8-1.2-.2 3-2.7 5.7-7 5.7C3 13 .6 11.3 0 8.5z",
+	LogoGit: "M7 .5L13.5 7 7 13.5.5 7z" +
+		"M6.1 4.2c0 .61-.49 1.1-1.1 1.1c-.61 0-1.1-.49-1.1-1.1c0-.61.49-1.1 1.1-1.1c.61 0 1.1.49 1.1 1.1z" +
+		"M6.1 9.8c0 .61-.49 1.1-1.1 1.1c-.61 0-1.1-.49-1.1-1.1c0-.61.49-1.1 1.1-1.1c.61 0 1.1.49 1.1 1.1z" +
+		"M10.1 7c0 .61-.49 1.1-1.1 1.1c-.61 0-1.1-.49-1.1-1.1c0-.61.49-1.1 1.1-1.1c.61 0 1.1.49 1.1 1.1z" +
+		"M4.6 5.6h.8v2.8h-.8z",
+	LogoLicense: "M2.5 .5h6l3 3v10h-9z" +
+		"M4.5 5h5v1h-5zM4.5 7.5h5v1h-5zM4.5 10h3v1h-3z",
+}
+
+// ParseLogo checks we have an icon for the logo name. An empty name means no logo.
+func ParseLogo(name string) (Logo, error) {
+	logo := Logo(strings.ToLower(name))
+	if logo == LogoNone {
+		return logo, nil
+	}
+
+	if _, ok := logoPaths[logo]; !ok {
+		return LogoNone, fmt.Errorf("Unknown logo %s", name)
+	}
+
+	return logo, nil
+}
+
+// writeLogo draws the logo with its top left corner at x, y
+func writeLogo(sb *strings.Builder, logo Logo, x int, y int, fill string) {
+	path, ok := logoPaths[logo]
+	if !ok {
+		return
+	}
+
+	fmt.Fprintf(sb, `<path transform="translate(%d %d)" fill="%s" fill-rule="evenodd" d="%s"/>`, x, y, fill, path)
+}
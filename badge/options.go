@@ -0,0 +1,66 @@
+package badge
+
+import (
+	"net/url"
+)
+
+// Labels from the query are cut short so badges stay a sensible size
+const constMaxLabelLength = 40
+
+// Options customize a badge using the URL query parameters label, labelColor, color, logo and style
+type Options struct {
+	Label      string
+	SetLabel   bool // Replace the label, even with an empty one
+	LabelColor string
+	Color      string
+	Logo       Logo
+	Style      Style
+}
+
+// ParseOptions validates the query parameters for a badge
+func ParseOptions(query url.Values) (o Options, err error) {
+	if label, ok := query["label"]; ok {
+		o.Label = Truncate(label[0], constMaxLabelLength)
+		o.SetLabel = true
+	}
+
+	if c := query.Get("labelColor"); c != "" {
+		o.LabelColor, err = ParseColor(c)
+		if err != nil {
+			return
+		}
+	}
+
+	if c := query.Get("color"); c != "" {
+		o.Color, err = ParseColor(c)
+		if err != nil {
+			return
+		}
+	}
+
+	o.Logo, err = ParseLogo(query.Get("logo"))
+	if err != nil {
+		return
+	}
+
+	o.Style, err = ParseStyle(query.Get("style"))
+	return
+}
+
+// Customize changes the badge to use the options
+func (b *Badge) Customize(o Options) {
+	if o.SetLabel {
+		b.Label = o.Label
+	}
+
+	if o.LabelColor != "" {
+		b.LabelColor = o.LabelColor
+	}
+
+	if o.Color != "" {
+		b.Color = o.Color
+	}
+
+	b.Logo = o.Logo
+	b.Style = o.Style
+}
@@ -0,0 +1,100 @@
+package badge
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	var tests = []struct {
+		color  string
+		result string
+		err    bool
+	}{
+		{color: "brightgreen", result: "#4c1"},
+		{color: "Red", result: "#e05d44"},
+		{color: "lightgray", result: "#9f9f9f"},
+		{color: "#ABCDEF", result: "#abcdef"},
+		{color: "abc", result: "#abc"},
+		{color: "#fff", result: "#fff"},
+		{color: "#ffff", err: true},
+		{color: "ggg", err: true},
+		{color: "purple", err: true},
+		{color: `"/><script>`, err: true},
+	}
+
+	for _, test := range tests {
+		result, err := ParseColor(test.color)
+		if (err != nil) != test.err {
+			t.Errorf("Unexpected error for color %s: %v", test.color, err)
+		}
+
+		if result != test.result {
+			t.Errorf("Color %s parsed as %s, expected %s", test.color, result, test.result)
+		}
+	}
+}
+
+func TestParseLogo(t *testing.T) {
+	var tests = []struct {
+		name string
+		logo Logo
+		err  bool
+	}{
+		{name: "", logo: LogoNone},
+		{name: "docker", logo: LogoDocker},
+		{name: "Git", logo: LogoGit},
+		{name: "license", logo: LogoLicense},
+		{name: "github", err: true},
+	}
+
+	for _, test := range tests {
+		logo, err := ParseLogo(test.name)
+		if (err != nil) != test.err {
+			t.Errorf("Unexpected error for logo %s: %v", test.name, err)
+		}
+
+		if logo != test.logo {
+			t.Errorf("Logo %s parsed as %s, expected %s", test.name, logo, test.logo)
+		}
+	}
+}
+
+func TestParseOptions(t *testing.T) {
+	var tests = []struct {
+		query string
+		badge Badge
+		err   bool
+	}{
+		{query: "", badge: New("version", "latest")},
+		{query: "label=tag&color=green&labelColor=%23333", badge: Badge{Label: "tag", Value: "latest", LabelColor: "#333", Color: "#97ca00", Style: StyleFlat}},
+		{query: "label=&logo=docker&style=flat-square", badge: Badge{Label: "", Value: "latest", LabelColor: DefaultLabelColor, Color: DefaultColor, Logo: LogoDocker, Style: StyleFlatSquare}},
+		{query: "label=abcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyz", badge: Badge{Label: "abcdefghijklmnopqrstuvwxyzabcdefghijklm…", Value: "latest", LabelColor: DefaultLabelColor, Color: DefaultColor, Style: StyleFlat}},
+		{query: "color=blah", err: true},
+		{query: "labelColor=%23abcd", err: true},
+		{query: "logo=blah", err: true},
+		{query: "style=blah", err: true},
+	}
+
+	for _, test := range tests {
+		query, err := url.ParseQuery(test.query)
+		if err != nil {
+			t.Fatalf("Bad query %s: %v", test.query, err)
+		}
+
+		o, err := ParseOptions(query)
+		if (err != nil) != test.err {
+			t.Errorf("Unexpected error for %s: %v", test.query, err)
+		}
+
+		if err != nil {
+			continue
+		}
+
+		b := New("version", "latest")
+		b.Customize(o)
+		if b != test.badge {
+			t.Errorf("Options %s gave badge %v, expected %v", test.query, b, test.badge)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package badge
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Circular arcs are drawn as cubic curves with control points this fraction of the radius
+// from the ends
+const constArcKappa = 0.5522847498
+
+// pathOp is a move (M), line (L) or cubic curve (C) to absolute coordinates
+type pathOp struct {
+	kind byte
+	pts  []float64
+}
+
+// subpath starts with a move and is implicitly closed
+type subpath []pathOp
+
+// parsePath converts SVG path data into subpaths. We only understand the commands we use
+// in badges and logos: M, L, H, V, C and Z in absolute and relative forms.
+func parsePath(d string) (path []subpath, err error) {
+	p := pathParser{d: d}
+	var cmd byte
+	var x, y, startX, startY float64
+	var current subpath
+
+	for {
+		p.skipSeparators()
+		if p.pos >= len(p.d) {
+			break
+		}
+
+		c := p.d[p.pos]
+		if isPathCommand(c) {
+			cmd = c
+			p.pos++
+		} else if cmd == 0 {
+			return nil, fmt.Errorf("Path data %q doesn't start with a command", d)
+		}
+
+		relative := cmd >= 'a'
+		var dx, dy float64
+		if relative {
+			dx, dy = x, y
+		}
+
+		switch cmd {
+		case 'M', 'm':
+			var pts []float64
+			pts, err = p.numbers(2)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(current) > 0 {
+				path = append(path, current)
+			}
+			x, y = pts[0]+dx, pts[1]+dy
+			startX, startY = x, y
+			current = subpath{{kind: 'M', pts: []float64{x, y}}}
+
+			// Any more coordinates after a move are lines
+			if cmd == 'M' {
+				cmd = 'L'
+			} else {
+				cmd = 'l'
+			}
+			continue
+
+		case 'Z', 'z':
+			if len(current) > 0 {
+				path = append(path, current)
+				current = nil
+			}
+			x, y = startX, startY
+			continue
+		}
+
+		// Drawing after a close starts a new subpath from the same point
+		if len(current) == 0 {
+			current = subpath{{kind: 'M', pts: []float64{x, y}}}
+		}
+
+		switch cmd {
+		case 'L', 'l':
+			var pts []float64
+			pts, err = p.numbers(2)
+			if err != nil {
+				return nil, err
+			}
+			x, y = pts[0]+dx, pts[1]+dy
+			current = append(current, pathOp{kind: 'L', pts: []float64{x, y}})
+
+		case 'H', 'h':
+			var pts []float64
+			pts, err = p.numbers(1)
+			if err != nil {
+				return nil, err
+			}
+			x = pts[0] + dx
+			current = append(current, pathOp{kind: 'L', pts: []float64{x, y}})
+
+		case 'V', 'v':
+			var pts []float64
+			pts, err = p.numbers(1)
+			if err != nil {
+				return nil, err
+			}
+			y = pts[0] + dy
+			current = append(current, pathOp{kind: 'L', pts: []float64{x, y}})
+
+		case 'C', 'c':
+			var pts []float64
+			pts, err = p.numbers(6)
+			if err != nil {
+				return nil, err
+			}
+			for i := 0; i < 6; i += 2 {
+				pts[i] += dx
+				pts[i+1] += dy
+			}
+			x, y = pts[4], pts[5]
+			current = append(current, pathOp{kind: 'C', pts: pts})
+
+		default:
+			return nil, fmt.Errorf("Unsupported path command %c", cmd)
+		}
+	}
+
+	if len(current) > 0 {
+		path = append(path, current)
+	}
+
+	return path, nil
+}
+
+func isPathCommand(c byte) bool {
+	switch c {
+	case 'M', 'm', 'L', 'l', 'H', 'h', 'V', 'v', 'C', 'c', 'Z', 'z':
+		return true
+	}
+	// Check for commands we don't support so they're reported rather than treated as numbers
+	return (c >= 'A' && c <= 'Z' && c != 'E') || (c >= 'a' && c <= 'z' && c != 'e')
+}
+
+// pathParser reads numbers from path data, where they can be separated by whitespace,
+// commas, a sign, or a second decimal point e.g. "1.5.5-2"
+type pathParser struct {
+	d   string
+	pos int
+}
+
+func (p *pathParser) skipSeparators() {
+	for p.pos < len(p.d) {
+		switch p.d[p.pos] {
+		case ' ', '\t', '\n', '\r', ',':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *pathParser) numbers(n int) ([]float64, error) {
+	pts := make([]float64, n)
+	for i := range pts {
+		p.skipSeparators()
+		start := p.pos
+		if p.pos < len(p.d) && (p.d[p.pos] == '-' || p.d[p.pos] == '+') {
+			p.pos++
+		}
+
+		seenDot, seenExp := false, false
+		for p.pos < len(p.d) {
+			c := p.d[p.pos]
+			if c >= '0' && c <= '9' {
+				p.pos++
+			} else if c == '.' && !seenDot && !seenExp {
+				seenDot = true
+				p.pos++
+			} else if (c == 'e' || c == 'E') && !seenExp {
+				seenExp = true
+				p.pos++
+				if p.pos < len(p.d) && (p.d[p.pos] == '-' || p.d[p.pos] == '+') {
+					p.pos++
+				}
+			} else {
+				break
+			}
+		}
+
+		v, err := strconv.ParseFloat(p.d[start:p.pos], 64)
+		if err != nil {
+			return nil, fmt.Errorf("Bad number in path data %q at %d", p.d, start)
+		}
+		pts[i] = v
+	}
+
+	return pts, nil
+}
+
+// roundedRect is a rect with circular corners of radius rx
+func roundedRect(x float64, y float64, width float64, height float64, rx float64) []subpath {
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+
+	rx = math.Min(rx, math.Min(width, height)/2)
+	if rx <= 0 {
+		return []subpath{{
+			{kind: 'M', pts: []float64{x, y}},
+			{kind: 'L', pts: []float64{x + width, y}},
+			{kind: 'L', pts: []float64{x + width, y + height}},
+			{kind: 'L', pts: []float64{x, y + height}},
+		}}
+	}
+
+	k := rx * (1 - constArcKappa)
+	right, bottom := x+width, y+height
+	return []subpath{{
+		{kind: 'M', pts: []float64{x + rx, y}},
+		{kind: 'L', pts: []float64{right - rx, y}},
+		{kind: 'C', pts: []float64{right - k, y, right, y + k, right, y + rx}},
+		{kind: 'L', pts: []float64{right, bottom - rx}},
+		{kind: 'C', pts: []float64{right, bottom - k, right - k, bottom, right - rx, bottom}},
+		{kind: 'L', pts: []float64{x + rx, bottom}},
+		{kind: 'C', pts: []float64{x + k, bottom, x, bottom - k, x, bottom - rx}},
+		{kind: 'L', pts: []float64{x, y + rx}},
+		{kind: 'C', pts: []float64{x, y + k, x + k, y, x + rx, y}},
+	}}
+}
+
+// translatePath moves every point in the path by dx, dy
+func translatePath(path []subpath, dx float64, dy float64) []subpath {
+	moved := make([]subpath, len(path))
+	for i, sp := range path {
+		moved[i] = make(subpath, len(sp))
+		for j, op := range sp {
+			pts := make([]float64, len(op.pts))
+			for k := 0; k < len(pts); k += 2 {
+				pts[k] = op.pts[k] + dx
+				pts[k+1] = op.pts[k+1] + dy
+			}
+			moved[i][j] = pathOp{kind: op.kind, pts: pts}
+		}
+	}
+
+	return moved
+}
+
+// pathYBounds is the top and bottom of the path. Curves are within their control points
+// so this may be slightly too big, but that's fine for gradients.
+func pathYBounds(path []subpath) (y0 float64, y1 float64) {
+	y0, y1 = math.Inf(1), math.Inf(-1)
+	for _, sp := range path {
+		for _, op := range sp {
+			for k := 1; k < len(op.pts); k += 2 {
+				y0 = math.Min(y0, op.pts[k])
+				y1 = math.Max(y1, op.pts[k])
+			}
+		}
+	}
+
+	if y0 > y1 {
+		return 0, 0
+	}
+
+	return y0, y1
+}
@@ -0,0 +1,520 @@
+package badge
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// PNG rasterizes the badge SVG. The SVG is laid out for the font we draw the text with,
+// rather than the fonts the browser would use.
+func (b Badge) PNG() ([]byte, error) {
+	img, err := rasterize(b.render(pngTextWidth))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	err = png.Encode(&buf, img)
+	return buf.Bytes(), err
+}
+
+// drawState holds the presentation attributes that are inherited from parent elements
+type drawState struct {
+	fill          string
+	fillOpacity   float64
+	fillRule      string
+	stroke        string
+	fontSize      float64
+	letterSpacing float64
+	anchor        string
+	clip          *image.Alpha
+	dx, dy        float64
+}
+
+type gradientStop struct {
+	offset float64
+	color  color.NRGBA
+}
+
+type pendingText struct {
+	x, y  float64
+	state drawState
+	text  strings.Builder
+}
+
+// rasterizer draws the subset of SVG that we generate for badges: paths and rects
+// with solid or vertical gradient fills, rect masks and centred text
+type rasterizer struct {
+	canvas     *image.RGBA
+	gradients  map[string][]gradientStop
+	masks      map[string]*image.Alpha
+	stack      []drawState
+	gradientID string
+	maskID     string
+	text       *pendingText
+	faces      map[float64]font.Face
+}
+
+func rasterize(svg string) (img *image.RGBA, err error) {
+	r := rasterizer{
+		gradients: make(map[string][]gradientStop),
+		masks:     make(map[string]*image.Alpha),
+		faces:     make(map[float64]font.Face),
+		stack: []drawState{{
+			fill:        "#000",
+			fillOpacity: 1,
+			fillRule:    "nonzero",
+			fontSize:    constFontSize,
+			anchor:      "start",
+		}},
+	}
+
+	d := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing SVG: %v", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			err = r.start(t)
+			if err != nil {
+				return nil, err
+			}
+		case xml.CharData:
+			if r.text != nil {
+				r.text.text.Write(t)
+			}
+		case xml.EndElement:
+			err = r.end(t)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if r.canvas == nil {
+		return nil, fmt.Errorf("No svg element")
+	}
+
+	return r.canvas, nil
+}
+
+func (r *rasterizer) start(el xml.StartElement) (err error) {
+	attrs := make(map[string]string, len(el.Attr))
+	for _, a := range el.Attr {
+		attrs[a.Name.Local] = a.Value
+	}
+
+	state := r.stack[len(r.stack)-1]
+	state.apply(attrs, r.masks)
+	r.stack = append(r.stack, state)
+
+	if el.Name.Local != "svg" && r.canvas == nil {
+		return fmt.Errorf("Element %s is outside the svg element", el.Name.Local)
+	}
+
+	switch el.Name.Local {
+	case "svg":
+		width, _ := strconv.Atoi(attrs["width"])
+		height, _ := strconv.Atoi(attrs["height"])
+		if width <= 0 || height <= 0 {
+			return fmt.Errorf("Bad SVG size %s x %s", attrs["width"], attrs["height"])
+		}
+		r.canvas = image.NewRGBA(image.Rect(0, 0, width, height))
+
+	case "linearGradient":
+		r.gradientID = attrs["id"]
+
+	case "stop":
+		c, ok := parseColor(attrs["stop-color"], parseFloat(attrs["stop-opacity"], 1))
+		if !ok {
+			c, _ = parseColor("#000", parseFloat(attrs["stop-opacity"], 1))
+		}
+		r.gradients[r.gradientID] = append(r.gradients[r.gradientID], gradientStop{offset: parseFloat(attrs["offset"], 0), color: c})
+
+	case "mask":
+		r.maskID = attrs["id"]
+
+	case "rect":
+		x, y := parseFloat(attrs["x"], 0), parseFloat(attrs["y"], 0)
+		width, height := parseFloat(attrs["width"], 0), parseFloat(attrs["height"], 0)
+		rx := parseFloat(attrs["rx"], 0)
+
+		if r.maskID != "" {
+			r.masks[r.maskID] = r.coverage(roundedRect(x, y, width, height, rx), "nonzero", nil)
+			return
+		}
+
+		r.fill(state, roundedRect(x, y, width, height, rx))
+
+		// Strokes are 1px wide and centred on the edge of the rect
+		if state.stroke != "" && state.stroke != "none" {
+			outline := append(roundedRect(x-.5, y-.5, width+1, height+1, rx+.5), roundedRect(x+.5, y+.5, width-1, height-1, rx-.5)...)
+			stroke := state
+			stroke.fill = state.stroke
+			stroke.fillRule = "evenodd"
+			r.fill(stroke, outline)
+		}
+
+	case "path":
+		if r.maskID != "" {
+			return
+		}
+
+		var path []subpath
+		path, err = parsePath(attrs["d"])
+		if err != nil {
+			return err
+		}
+		r.fill(state, path)
+
+	case "text":
+		r.text = &pendingText{
+			x:     parseFloat(attrs["x"], 0),
+			y:     parseFloat(attrs["y"], 0),
+			state: state,
+		}
+	}
+
+	return nil
+}
+
+func (r *rasterizer) end(el xml.EndElement) (err error) {
+	switch el.Name.Local {
+	case "linearGradient":
+		r.gradientID = ""
+	case "mask":
+		r.maskID = ""
+	case "text":
+		if r.text != nil && r.maskID == "" {
+			err = r.drawText(r.text)
+		}
+		r.text = nil
+	}
+
+	if len(r.stack) > 1 {
+		r.stack = r.stack[:len(r.stack)-1]
+	}
+
+	return err
+}
+
+// apply sets any presentation attributes on the element
+func (s *drawState) apply(attrs map[string]string, masks map[string]*image.Alpha) {
+	if v, ok := attrs["fill"]; ok {
+		s.fill = v
+		s.fillOpacity = 1
+	}
+
+	if v, ok := attrs["fill-opacity"]; ok {
+		s.fillOpacity = parseFloat(v, 1)
+	}
+
+	if v, ok := attrs["fill-rule"]; ok {
+		s.fillRule = v
+	}
+
+	if v, ok := attrs["stroke"]; ok {
+		s.stroke = v
+	}
+
+	if v, ok := attrs["font-size"]; ok {
+		s.fontSize = parseFloat(v, s.fontSize)
+	}
+
+	if v, ok := attrs["letter-spacing"]; ok {
+		s.letterSpacing = parseFloat(v, 0)
+	}
+
+	if v, ok := attrs["text-anchor"]; ok {
+		s.anchor = v
+	}
+
+	if v, ok := attrs["mask"]; ok {
+		s.clip = masks[strings.TrimSuffix(strings.TrimPrefix(v, "url(#"), ")")]
+	}
+
+	if v, ok := attrs["transform"]; ok && strings.HasPrefix(v, "translate(") {
+		parts := strings.Fields(strings.NewReplacer("translate(", "", ")", "", ",", " ").Replace(v))
+		if len(parts) == 2 {
+			s.dx += parseFloat(parts[0], 0)
+			s.dy += parseFloat(parts[1], 0)
+		}
+	}
+}
+
+// fill paints the path in the fill colour or gradient
+func (r *rasterizer) fill(state drawState, path []subpath) {
+	if state.dx != 0 || state.dy != 0 {
+		path = translatePath(path, state.dx, state.dy)
+	}
+
+	var src image.Image
+	if strings.HasPrefix(state.fill, "url(#") {
+		stops, ok := r.gradients[strings.TrimSuffix(strings.TrimPrefix(state.fill, "url(#"), ")")]
+		if !ok {
+			return
+		}
+
+		y0, y1 := pathYBounds(path)
+		src = &verticalGradient{stops: stops, y0: y0, y1: y1, opacity: state.fillOpacity}
+	} else {
+		c, ok := parseColor(state.fill, state.fillOpacity)
+		if !ok {
+			return
+		}
+		src = image.NewUniform(c)
+	}
+
+	mask := r.coverage(path, state.fillRule, state.clip)
+	draw.DrawMask(r.canvas, r.canvas.Bounds(), src, image.Point{}, mask, image.Point{}, draw.Over)
+}
+
+// coverage rasterizes each subpath separately and combines them with the fill rule.
+// This is exact for our shapes, where subpaths either don't overlap or are holes.
+func (r *rasterizer) coverage(path []subpath, fillRule string, clip *image.Alpha) *image.Alpha {
+	bounds := r.canvas.Bounds()
+	result := image.NewAlpha(bounds)
+	sub := image.NewAlpha(bounds)
+	z := vector.NewRasterizer(bounds.Dx(), bounds.Dy())
+
+	for _, sp := range path {
+		z.Reset(bounds.Dx(), bounds.Dy())
+		for _, op := range sp {
+			switch op.kind {
+			case 'M':
+				z.MoveTo(float32(op.pts[0]), float32(op.pts[1]))
+			case 'L':
+				z.LineTo(float32(op.pts[0]), float32(op.pts[1]))
+			case 'C':
+				z.CubeTo(float32(op.pts[0]), float32(op.pts[1]), float32(op.pts[2]), float32(op.pts[3]), float32(op.pts[4]), float32(op.pts[5]))
+			}
+		}
+		z.ClosePath()
+
+		for i := range sub.Pix {
+			sub.Pix[i] = 0
+		}
+		z.Draw(sub, bounds, image.Opaque, image.Point{})
+
+		for i, s := range sub.Pix {
+			c := int(result.Pix[i])
+			if fillRule == "evenodd" {
+				c = c + int(s) - 2*c*int(s)/255
+			} else {
+				c = c + int(s) - c*int(s)/255
+			}
+			result.Pix[i] = uint8(c)
+		}
+	}
+
+	if clip != nil {
+		for i := range result.Pix {
+			result.Pix[i] = uint8(int(result.Pix[i]) * int(clip.Pix[i]) / 255)
+		}
+	}
+
+	return result
+}
+
+func (r *rasterizer) drawText(t *pendingText) error {
+	text := t.text.String()
+	if text == "" {
+		return nil
+	}
+
+	c, ok := parseColor(t.state.fill, t.state.fillOpacity)
+	if !ok {
+		return nil
+	}
+
+	face, err := r.getFace(t.state.fontSize)
+	if err != nil {
+		return fmt.Errorf("Error getting font: %v", err)
+	}
+
+	d := font.Drawer{Dst: r.canvas, Src: image.NewUniform(c), Face: face}
+
+	spacing := fixed.Int26_6(t.state.letterSpacing * 64)
+	width := measureText(face, text, spacing)
+
+	x := fixed.Int26_6((t.x + t.state.dx) * 64)
+	switch t.state.anchor {
+	case "middle":
+		x -= width / 2
+	case "end":
+		x -= width
+	}
+
+	d.Dot = fixed.Point26_6{X: x, Y: fixed.Int26_6((t.y + t.state.dy) * 64)}
+	for _, ch := range text {
+		d.DrawString(string(ch))
+		d.Dot.X += spacing
+	}
+
+	return nil
+}
+
+// measureText is the width of the text drawn one character at a time, with the spacing after each
+func measureText(face font.Face, text string, spacing fixed.Int26_6) (width fixed.Int26_6) {
+	d := font.Drawer{Face: face}
+	for _, ch := range text {
+		width += d.MeasureString(string(ch)) + spacing
+	}
+
+	return width
+}
+
+// pngTextWidth measures text in the font that PNG badges are drawn with
+func pngTextWidth(s string, fontSize float64, letterSpacing float64) float64 {
+	face, err := newFace(fontSize)
+	if err != nil {
+		// Drawing the text will fail too, and return the error
+		return textWidth(s, fontSize, letterSpacing)
+	}
+	defer face.Close()
+
+	return float64(measureText(face, s, fixed.Int26_6(letterSpacing*64))) / 64
+}
+
+var (
+	fontOnce sync.Once
+	fontErr  error
+	textFont *opentype.Font
+)
+
+// newFace gets the Go font at the size. Verdana can't be distributed so PNG badges use the
+// Go font, and are laid out with its widths.
+func newFace(size float64) (font.Face, error) {
+	fontOnce.Do(func() {
+		textFont, fontErr = opentype.Parse(goregular.TTF)
+	})
+
+	if fontErr != nil {
+		return nil, fontErr
+	}
+
+	return opentype.NewFace(textFont, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+}
+
+// getFace gets the face for the size. Faces aren't safe for concurrent use, so each
+// rasterizer has its own.
+func (r *rasterizer) getFace(size float64) (font.Face, error) {
+	if face, ok := r.faces[size]; ok {
+		return face, nil
+	}
+
+	face, err := newFace(size)
+	if err != nil {
+		return nil, err
+	}
+
+	r.faces[size] = face
+	return face, nil
+}
+
+// verticalGradient is a linear gradient from the top to the bottom of a shape
+type verticalGradient struct {
+	stops   []gradientStop
+	y0, y1  float64
+	opacity float64
+}
+
+func (g *verticalGradient) ColorModel() color.Model { return color.NRGBAModel }
+
+func (g *verticalGradient) Bounds() image.Rectangle {
+	return image.Rect(-1e9, -1e9, 1e9, 1e9)
+}
+
+func (g *verticalGradient) At(x, y int) color.Color {
+	if len(g.stops) == 0 {
+		return color.Transparent
+	}
+
+	t := 0.0
+	if g.y1 > g.y0 {
+		t = (float64(y) + .5 - g.y0) / (g.y1 - g.y0)
+	}
+
+	c := g.stops[len(g.stops)-1].color
+	if t <= g.stops[0].offset {
+		c = g.stops[0].color
+	} else {
+		for i := 1; i < len(g.stops); i++ {
+			if t <= g.stops[i].offset {
+				a, b := g.stops[i-1], g.stops[i]
+				f := (t - a.offset) / (b.offset - a.offset)
+				c = color.NRGBA{
+					R: mix(a.color.R, b.color.R, f),
+					G: mix(a.color.G, b.color.G, f),
+					B: mix(a.color.B, b.color.B, f),
+					A: mix(a.color.A, b.color.A, f),
+				}
+				break
+			}
+		}
+	}
+
+	c.A = uint8(float64(c.A) * g.opacity)
+	return c
+}
+
+func mix(a uint8, b uint8, f float64) uint8 {
+	return uint8(float64(a) + (float64(b)-float64(a))*f + .5)
+}
+
+// parseColor converts a hex colour to an NRGBA colour with the opacity
+func parseColor(s string, opacity float64) (c color.NRGBA, ok bool) {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+
+	if len(s) != 6 {
+		return c, false
+	}
+
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return c, false
+	}
+
+	return color.NRGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: uint8(255*opacity + .5)}, true
+}
+
+func parseFloat(s string, def float64) float64 {
+	s = strings.TrimSpace(s)
+	if strings.HasSuffix(s, "%") {
+		if v, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64); err == nil {
+			return v / 100
+		}
+		return def
+	}
+
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		return v
+	}
+
+	return def
+}
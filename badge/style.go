@@ -0,0 +1,34 @@
+package badge
+
+import (
+	"fmt"
+)
+
+// Style is the shape and shading of a badge, matching the shields.io styles
+type Style string
+
+// The badge styles we support
+const (
+	StyleFlat        Style = "flat"
+	StyleFlatSquare  Style = "flat-square"
+	StylePlastic     Style = "plastic"
+	StyleForTheBadge Style = "for-the-badge"
+	StyleSocial      Style = "social"
+)
+
+var styles = []Style{StyleFlat, StyleFlatSquare, StylePlastic, StyleForTheBadge, StyleSocial}
+
+// ParseStyle checks the style name is one we support. An empty name is the flat style.
+func ParseStyle(name string) (Style, error) {
+	if name == "" {
+		return StyleFlat, nil
+	}
+
+	for _, s := range styles {
+		if string(s) == name {
+			return s, nil
+		}
+	}
+
+	return "", fmt.Errorf("Unknown badge style %s", name)
+}
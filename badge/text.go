@@ -0,0 +1,73 @@
+package badge
+
+import (
+	"bytes"
+	"encoding/xml"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// Font size in pixels for the badge text
+	constFontSize = 11
+
+	constEllipsis = "\u2026"
+)
+
+// TextWidth is the width in pixels of the text in the badge font. We measure with Verdana
+// where we can, then DejaVu Sans, and treat anything else as a wide character.
+func TextWidth(s string) float64 {
+	return textWidth(s, constFontSize, 0)
+}
+
+// textWidth measures text at any font size, with extra spacing after each character
+func textWidth(s string, fontSize float64, letterSpacing float64) float64 {
+	var em float64
+	var n int
+	for _, r := range s {
+		em += runeWidth(r)
+		n++
+	}
+
+	return em*fontSize + letterSpacing*float64(n)
+}
+
+// runeWidth is the width of the rune as a fraction of the font size
+func runeWidth(r rune) float64 {
+	if w, ok := verdanaWidths.width(r); ok {
+		return float64(w) / float64(verdanaWidths.unitsPerEm)
+	}
+
+	if w, ok := dejaVuSansWidths.width(r); ok {
+		return float64(w) / float64(dejaVuSansWidths.unitsPerEm)
+	}
+
+	switch {
+	case unicode.Is(unicode.Mn, r) || unicode.IsControl(r):
+		// Combining marks and control characters don't take up any space
+		return 0
+	case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+		// CJK characters are square
+		return 1
+	}
+
+	w, _ := verdanaWidths.width('m')
+	return float64(w) / float64(verdanaWidths.unitsPerEm)
+}
+
+// Truncate shortens text to at most length runes, ending with an ellipsis if anything was cut off
+func Truncate(s string, length int) string {
+	if length < 1 || utf8.RuneCountInString(s) <= length {
+		return s
+	}
+
+	runes := []rune(s)
+	return string(runes[:length-1]) + constEllipsis
+}
+
+// escape makes text safe to include in the SVG
+func escape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
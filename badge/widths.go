@@ -0,0 +1,46 @@
+package badge
+
+//go:generate go run gen_widths.go
+
+// widthRange has the widths for a run of consecutive runes
+type widthRange struct {
+	first  rune
+	widths []uint16
+}
+
+// widthTable holds glyph advance widths in font units. A width of zero means the font
+// has no glyph for the rune.
+type widthTable struct {
+	unitsPerEm int
+	ranges     []widthRange
+}
+
+// width returns the advance width of the rune in font units and whether the font has a glyph for it
+func (t widthTable) width(r rune) (int, bool) {
+	for _, wr := range t.ranges {
+		if r >= wr.first && r < wr.first+rune(len(wr.widths)) {
+			w := int(wr.widths[r-wr.first])
+			return w, w > 0
+		}
+	}
+
+	return 0, false
+}
+
+// verdanaWidths are the advance widths of the printable ASCII glyphs in Verdana, which
+// is the font most browsers will use for badges. They're from verdana.ttf in Microsoft's
+// Core fonts for the Web, and go run gen_widths.go -verdana <file> prints them. Anything
+// else falls back to DejaVu Sans, which has very similar metrics.
+var verdanaWidths = widthTable{
+	unitsPerEm: 2048,
+	ranges: []widthRange{
+		{first: 0x0020, widths: []uint16{
+			720, 806, 940, 1676, 1302, 2204, 1488, 550, 930, 930, 1302, 1676, 745, 930, 745, 930,
+			1302, 1302, 1302, 1302, 1302, 1302, 1302, 1302, 1302, 1302, 930, 930, 1676, 1676, 1676, 1117,
+			2048, 1400, 1404, 1430, 1579, 1295, 1177, 1588, 1540, 862, 931, 1419, 1140, 1726, 1532, 1612,
+			1235, 1612, 1424, 1400, 1262, 1499, 1400, 2025, 1403, 1260, 1403, 930, 930, 930, 1676, 1302,
+			1302, 1230, 1275, 1067, 1275, 1220, 720, 1275, 1296, 562, 705, 1212, 562, 1992, 1296, 1243,
+			1275, 1275, 873, 1067, 806, 1296, 1212, 1672, 1212, 1212, 1076, 1300, 930, 1300, 1676,
+		}},
+	},
+}
@@ -152,6 +152,13 @@ func (d *PgDB) PutImage(img Image) (nmc NotificationMessageChanges, err error) {
 					return
 				}
 			}
+
+			err = putPlatforms(t, v.Platforms, tx)
+			if err != nil {
+				log.Errorf("Error saving platforms for tag %s: %v", t.Tag, err)
+				tx.Rollback()
+				return
+			}
 		}
 	}
 
@@ -166,6 +173,13 @@ func (d *PgDB) PutImage(img Image) (nmc NotificationMessageChanges, err error) {
 			tx.Rollback()
 			return
 		}
+
+		err = tx.Where("image_name = ? AND tag = ?", ot.ImageName, ot.Tag).Delete(Platform{}).Error
+		if err != nil {
+			log.Errorf("Error deleting platforms for tag %s: %v", ot.Tag, err)
+			tx.Rollback()
+			return
+		}
 	}
 
 	// I'm not totally happy that the text for this is hiding away in the database code, but there you are
@@ -177,15 +191,43 @@ func (d *PgDB) PutImage(img Image) (nmc NotificationMessageChanges, err error) {
 	}
 
 	imageURL := d.GetPageURL(img)
-	nmc.Text = fmt.Sprintf("MicroBadger: Docker Hub image %s has changed %s", nmc.ImageName, imageURL)
+	nmc.Text = fmt.Sprintf("MicroBadger: %s image %s has changed %s", d.getRegistryName(img.Name), nmc.ImageName, imageURL)
 
 	tx.Commit()
+	d.imageChanged(img.Name)
 	return
 }
 
+// putPlatforms replaces the platforms for a tag inside a transaction
+func putPlatforms(t Tag, platforms []Platform, tx *gorm.DB) (err error) {
+	err = tx.Where("image_name = ? AND tag = ?", t.ImageName, t.Tag).Delete(Platform{}).Error
+	if err != nil {
+		return err
+	}
+
+	for _, p := range platforms {
+		if p.Tag != t.Tag {
+			continue
+		}
+
+		p.ImageName = t.ImageName
+		err = tx.Create(&p).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // PutImageOnly saves just the image, none of its related image versions or tags
 func (d *PgDB) PutImageOnly(img Image) error {
-	return d.db.Save(&img).Error
+	err := d.db.Save(&img).Error
+	if err == nil {
+		d.imageChanged(img.Name)
+	}
+
+	return err
 }
 
 // DeleteImage deletes it including all versions and tags
@@ -198,6 +240,7 @@ func (d *PgDB) DeleteImage(image string) (err error) {
 	}
 
 	tx.Commit()
+	d.imageChanged(image)
 	log.Debugf("Deleted image %s", image)
 	return
 }
@@ -207,6 +250,12 @@ func deleteImage(image string, tx *gorm.DB) (err error) {
 	var versions []ImageVersion
 	var tags []Tag
 
+	err = tx.Where("image_name = ?", image).Delete(Platform{}).Error
+	if err != nil {
+		log.Errorf("Error deleting platforms for image %s - %v", image, err)
+		return
+	}
+
 	err = tx.Where("image_name = ?", image).Find(&versions).Error
 	if err != nil {
 		log.Errorf("Error getting versions for image %s - %v", image, err)
@@ -422,6 +471,21 @@ func (d *PgDB) GetTagsList(iv *ImageVersion) (tags []Tag, err error) {
 	return tags, err
 }
 
+// GetPlatforms returns the platforms for multi-architecture tags on this version
+func (d *PgDB) GetPlatforms(iv *ImageVersion) (platforms []Platform, err error) {
+	log.Debugf("Getting platforms for version %s of %s", iv.SHA, iv.ImageName)
+
+	err = d.db.Table("platforms").
+		Where("image_name = ? AND tag IN (SELECT tag FROM tags WHERE image_name = ? AND sha = ?)", iv.ImageName, iv.ImageName, iv.SHA).
+		Select("DISTINCT sha, os, architecture, variant").
+		Order("os, architecture, variant").
+		Scan(&platforms).Error
+
+	log.Debugf("Found %d platforms for version %s of %s", len(platforms), iv.SHA, iv.ImageName)
+
+	return platforms, err
+}
+
 // Return true if either download size is 0 or layers is '' or the hash has not yet been calculated
 func (d *PgDB) ImageVersionNeedsSizeOrLayers(iv *ImageVersion) bool {
 	row := d.db.
@@ -483,6 +547,7 @@ func (d *PgDB) getBadgesInstalledCount(isAutomated bool) (badges int, images int
 	return results.Badges, results.Images, err
 }
 
+// GetLabelSchemaImages lists the images with label-schema or OpenContainers labels
 func (d *PgDB) GetLabelSchemaImages(pageNum int) ImageList {
 	log.Debug("Getting label schema images")
 	var name []string
@@ -492,7 +557,7 @@ func (d *PgDB) GetLabelSchemaImages(pageNum int) ImageList {
 
 	// We are only returning public images on this query
 	err := d.db.Table("image_versions").
-		Where("labels LIKE '%org.label-schema.%' AND is_private is not True AND status = 'INSPECTED'").
+		Where("(labels LIKE '%org.label-schema.%' OR labels LIKE '%org.opencontainers.image.%') AND is_private is not True AND status = 'INSPECTED'").
 		Joins("JOIN images ON image_versions.image_name = images.name AND image_versions.sha = images.latest").
 		Order("image_name").Limit(constDisplayMaxImages).
 		Select("image_name").
@@ -526,7 +591,7 @@ func (d *PgDB) GetLabelSchemaImageCount() int {
 
 	err := d.db.Raw(`SELECT COUNT(DISTINCT v.image_name) AS image_count FROM image_versions v
 									 JOIN images i ON v.image_name = i.name AND v.sha = i.latest
-									 WHERE v.labels LIKE '%org.label-schema.%' AND i.is_private IS NOT True
+									 WHERE (v.labels LIKE '%org.label-schema.%' OR v.labels LIKE '%org.opencontainers.image.%') AND i.is_private IS NOT True
 									 AND i.status = 'INSPECTED'`).
 		Pluck("image_count", &results).Error
 
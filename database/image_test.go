@@ -239,3 +239,57 @@ func TestDeleteImage(t *testing.T) {
 		t.Errorf("Found %d unexpected rows in tags", rows)
 	}
 }
+
+func TestPlatforms(t *testing.T) {
+	var d PgDB
+	var rows int
+
+	d = getDatabase(t)
+	emptyDatabase(d)
+	addThings(d)
+
+	img, err := d.GetImage("lizrice/childimage")
+	if err != nil {
+		t.Fatalf("Failed to get image: %v", err)
+	}
+
+	platforms := []Platform{
+		{Tag: "latest", SHA: "10000", OS: "linux", Architecture: "amd64"},
+		{Tag: "latest", SHA: "10001", OS: "linux", Architecture: "arm", Variant: "v7"},
+	}
+
+	img.Versions = []ImageVersion{
+		{SHA: "10000", ImageName: img.Name, Tags: []Tag{{Tag: "latest", ImageName: img.Name, SHA: "10000"}}, Platforms: platforms},
+		{SHA: "10001", ImageName: img.Name},
+	}
+
+	_, err = d.PutImage(img)
+	if err != nil {
+		t.Fatalf("Failed to put image: %v", err)
+	}
+
+	p, err := d.GetPlatforms(&img.Versions[0])
+	if err != nil {
+		t.Fatalf("Failed to get platforms: %v", err)
+	}
+
+	if len(p) != 2 || p[0].String() != "linux/amd64" || p[1].String() != "linux/arm/v7" || p[1].SHA != "10001" {
+		t.Errorf("Unexpected platforms %v", p)
+	}
+
+	// The other platform has no tags so no platforms
+	p, err = d.GetPlatforms(&img.Versions[1])
+	if err != nil || len(p) != 0 {
+		t.Errorf("Unexpected platforms %v - %v", p, err)
+	}
+
+	err = d.DeleteImage(img.Name)
+	if err != nil {
+		t.Errorf("Unexpected error deleting image %s - %v", img.Name, err)
+	}
+
+	d.db.Table("platforms").Count(&rows)
+	if rows != 0 {
+		t.Errorf("Found %d unexpected rows in platforms", rows)
+	}
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+// GetInspectionFailure returns the failed inspections of the image, or an error if
+// it hasn't failed since it was last inspected
+func (d *PgDB) GetInspectionFailure(image string) (f InspectionFailure, err error) {
+	err = d.db.Where("image_name = ?", image).First(&f).Error
+	return f, err
+}
+
+// PutInspectionFailure saves it
+func (d *PgDB) PutInspectionFailure(f InspectionFailure) error {
+	return d.db.Save(&f).Error
+}
+
+// DeleteInspectionFailure removes the failures for the image once it has been inspected
+func (d *PgDB) DeleteInspectionFailure(image string) error {
+	return d.db.Where("image_name = ?", image).Delete(InspectionFailure{}).Error
+}
+
+// IsDeadLettered returns true if the image has failed too many times to be inspected again
+func (d *PgDB) IsDeadLettered(image string) bool {
+	f, err := d.GetInspectionFailure(image)
+	return err == nil && f.DeadLettered
+}
+
+// DeadLetterImage saves the failure as dead-lettered, and marks the image as failed with the error
+func (d *PgDB) DeadLetterImage(f InspectionFailure) (err error) {
+	f.DeadLettered = true
+
+	tx := d.db.Begin()
+	err = tx.Save(&f).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Model(&Image{}).Where("name = ?", f.ImageName).
+		Updates(map[string]interface{}{"status": "FAILED_INSPECTION", "status_reason": f.LastError}).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit().Error
+	if err == nil {
+		d.imageChanged(f.ImageName)
+	}
+
+	return err
+}
+
+// GetDeadLetters returns a page of dead-lettered images, most recent first
+func (d *PgDB) GetDeadLetters(pageNum int) DeadLetterList {
+	var deadLetters []InspectionFailure
+	var count int
+
+	err := d.db.Model(&InspectionFailure{}).Where("dead_lettered = true").Count(&count).Error
+	if err != nil {
+		log.Errorf("Error counting dead letters: %v", err)
+	}
+
+	offset := (pageNum - 1) * constImagesPerPage
+	err = d.db.Where("dead_lettered = true").
+		Order("updated_at DESC").
+		Limit(constImagesPerPage).
+		Offset(offset).
+		Find(&deadLetters).Error
+	if err != nil {
+		log.Errorf("Error getting dead letters: %v", err)
+	}
+
+	pageCount := count / constImagesPerPage
+	if (count % constImagesPerPage) > 0 {
+		pageCount++
+	}
+
+	return DeadLetterList{
+		CurrentPage:     pageNum,
+		PageCount:       pageCount,
+		DeadLetterCount: count,
+		DeadLetters:     deadLetters,
+	}
+}
+
+// PurgeDeadLetter removes the image from the dead letters without inspecting it again,
+// returning an error if it isn't dead-lettered
+func (d *PgDB) PurgeDeadLetter(image string) error {
+	res := d.db.Where("image_name = ? AND dead_lettered = true", image).Delete(InspectionFailure{})
+	if res.Error == nil && res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return res.Error
+}
+
+// PurgeDeadLetters removes all the dead letters, returning how many there were
+func (d *PgDB) PurgeDeadLetters() (int64, error) {
+	res := d.db.Where("dead_lettered = true").Delete(InspectionFailure{})
+	return res.RowsAffected, res.Error
+}
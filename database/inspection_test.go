@@ -0,0 +1,87 @@
+// +build dbrequired
+
+package database
+
+import (
+	"testing"
+
+	"github.com/jinzhu/gorm"
+)
+
+func TestDeadLetters(t *testing.T) {
+	db := getDatabase(t)
+	emptyDatabase(db)
+	addThings(db)
+
+	err := db.PutInspectionFailure(InspectionFailure{ImageName: "lizrice/childimage", Attempts: 1, LastError: "timeout"})
+	if err != nil {
+		t.Fatalf("Error saving inspection failure: %v", err)
+	}
+
+	if db.IsDeadLettered("lizrice/childimage") {
+		t.Errorf("Image shouldn't be dead-lettered after one failure")
+	}
+
+	f, err := db.GetInspectionFailure("lizrice/childimage")
+	if err != nil {
+		t.Fatalf("Error getting inspection failure: %v", err)
+	}
+
+	f.Attempts++
+	f.LastError = "manifest unknown"
+	err = db.DeadLetterImage(f)
+	if err != nil {
+		t.Fatalf("Error dead-lettering image: %v", err)
+	}
+
+	if !db.IsDeadLettered("lizrice/childimage") {
+		t.Errorf("Image should be dead-lettered")
+	}
+
+	img, err := db.GetImage("lizrice/childimage")
+	if err != nil {
+		t.Fatalf("Error getting image: %v", err)
+	}
+
+	if img.Status != "FAILED_INSPECTION" || img.StatusReason != "manifest unknown" {
+		t.Errorf("Unexpected image status %s reason %s", img.Status, img.StatusReason)
+	}
+
+	dl := db.GetDeadLetters(1)
+	if dl.DeadLetterCount != 1 || len(dl.DeadLetters) != 1 || dl.DeadLetters[0].Attempts != 2 {
+		t.Errorf("Unexpected dead letters %#v", dl)
+	}
+
+	err = db.PurgeDeadLetter("lizrice/childimage")
+	if err != nil {
+		t.Errorf("Error purging dead letter: %v", err)
+	}
+
+	err = db.PurgeDeadLetter("lizrice/childimage")
+	if err != gorm.ErrRecordNotFound {
+		t.Errorf("Expected not found purging again, got %v", err)
+	}
+
+	db.DeadLetterImage(InspectionFailure{ImageName: "lizrice/childimage", Attempts: 5})
+	db.PutInspectionFailure(InspectionFailure{ImageName: "lizrice/featured", Attempts: 1})
+	count, err := db.PurgeDeadLetters()
+	if err != nil || count != 1 {
+		t.Errorf("Expected to purge 1 dead letter, got %d: %v", count, err)
+	}
+
+	// Failures that aren't dead-lettered are left until the image is inspected
+	_, err = db.GetInspectionFailure("lizrice/featured")
+	if err != nil {
+		t.Errorf("Error getting inspection failure: %v", err)
+	}
+
+	err = db.DeleteInspectionFailure("lizrice/featured")
+	if err != nil {
+		t.Errorf("Error deleting inspection failure: %v", err)
+	}
+
+	_, err = db.GetInspectionFailure("lizrice/featured")
+	if err != gorm.ErrRecordNotFound {
+		t.Errorf("Expected not found after deleting, got %v", err)
+	}
+}
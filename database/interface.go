@@ -50,11 +50,16 @@ type RegistryList struct {
 	Registries        []Registry
 }
 
-// Registry is a supported docker registry
+// Registry is a supported docker registry. Images in registries other than
+// the Docker Hub have the registry host at the start of their name.
 type Registry struct {
 	ID              string `gorm:"primary_key"`
 	Name            string
 	Url             string
+	Host            string `gorm:"unique_index" json:",omitempty"`
+	APIURL          string `json:"-"` // Defaults to https://{Host}
+	AuthURL         string `json:"-"` // Token realm - discovered from the registry if not set
+	Service         string `json:"-"`
 	CredentialsName string `gorm:"-"`
 }
 
@@ -63,6 +68,7 @@ type Image struct {
 	// Fields managed by gorm
 	Name            string    `gorm:"primary_key" json:"-"`
 	Status          string    `json:"-"`
+	StatusReason    string    `json:"-"` // Why the status was set e.g. the error for FAILED_INSPECTION
 	Featured        bool      `json:"-"`
 	Latest          string    `sql:"REFERENCES image_versions(sha) ON DELETE RESTRICT" json:"LatestSHA"`
 	BadgeCount      int       `json:"-"` // Number of badges we can generate for this image
@@ -77,6 +83,7 @@ type Image struct {
 	BadgesInstalled int       `json:"-"`          // Number of badges for this image we have found (so far we only look on Docker Hub)
 	PullCount       int
 	StarCount       int
+	ScheduledAt     time.Time `json:"-"` // When the scheduler last sent the image for re-inspection
 
 	// Gorm auto-updating doesn't work well as we have our own primary key, so we handle this ourselves
 	Versions []ImageVersion `gorm:"-" json:",omitempty"`
@@ -91,6 +98,7 @@ type Image struct {
 	DownloadSize int64             `gorm:"-" json:",omitempty"`
 	Labels       map[string]string `gorm:"-" json:",omitempty"`
 	LatestTag    string            `gorm:"-" json:"LatestVersion,omitempty"`
+	Platforms    []Platform        `gorm:"-" json:",omitempty"`
 }
 
 // ImageVersion is a version of an image
@@ -108,6 +116,10 @@ type ImageVersion struct {
 	LayersArray    []ImageLayer      `gorm:"-" json:"Layers,omitempty"`
 	Manifest       string            `json:"-"`
 	Hash           string            `gorm:"index" json:"-"` // Hash of the layers in this image
+	OS             string            `json:",omitempty"`
+	Architecture   string            `json:",omitempty"`
+	Variant        string            `json:",omitempty"`
+	Platforms      []Platform        `gorm:"-" json:",omitempty"` // All the platforms for multi-architecture tags on this version
 	Parents        []ImageVersion    `gorm:"-" json:",omitempty"`
 	Identical      []ImageVersion    `gorm:"-" json:",omitempty"`
 	MicrobadgerURL string            `gorm:"-" json:",omitempty"`
@@ -115,6 +127,7 @@ type ImageVersion struct {
 	// JSON only fields for data parsed from the labels.
 	License        *License        `gorm:"-" json:",omitempty"`
 	VersionControl *VersionControl `gorm:"-" json:",omitempty"`
+	Metadata       *ImageMetadata  `gorm:"-" json:",omitempty"`
 }
 
 // Tag is assigned to an image version
@@ -124,24 +137,94 @@ type Tag struct {
 	SHA       string `gorm:"index" json:",omitempty" sql:"REFERENCES image_versions(sha) on DELETE RESTRICT"`
 }
 
+// Platform is the image version for one architecture of a multi-architecture tag.
+// The tag refers to the version for the default platform.
+type Platform struct {
+	ImageName    string `gorm:"primary_key" json:"-" sql:"REFERENCES images(name) ON DELETE RESTRICT"`
+	Tag          string `gorm:"primary_key" json:"-"`
+	SHA          string `gorm:"primary_key"`
+	OS           string
+	Architecture string
+	Variant      string `json:",omitempty"`
+}
+
+// String formats the platform as os/architecture/variant
+func (p Platform) String() string {
+	s := p.OS + "/" + p.Architecture
+	if p.Variant != "" {
+		s += "/" + p.Variant
+	}
+	return s
+}
+
 // ImageLayer is the detail of layers that make up an image version. TODO!! Consider storing these so we can pull them and store the details
 type ImageLayer struct {
-	BlobSum      string `gorm:"-"` // TODO! We need this in API for calculating hashes, but we don't want it travelling on the API
-	Command      string `gorm:"-" json:"Command"`
-	DownloadSize int64  `gorm:"-" json:"DownloadSize"`
+	BlobSum          string `gorm:"-"` // TODO! We need this in API for calculating hashes, but we don't want it travelling on the API
+	Command          string `gorm:"-" json:"Command"`
+	DownloadSize     int64  `gorm:"-" json:"DownloadSize"`
+	UncompressedSize int64  `gorm:"-" json:",omitempty"` // Only set if the layer contents have been inspected
+	FileCount        int    `gorm:"-" json:",omitempty"`
 }
 
-// License is parsed from the org.label-schema.license label.
+// LayerContents is what we found when we downloaded a layer blob. Blobs are content addressed
+// so these are shared by all the images that use the layer.
+type LayerContents struct {
+	BlobSum          string `gorm:"primary_key"`
+	UncompressedSize int64
+	FileCount        int
+	Truncated        bool         // The file tree only has the first part of the layer
+	Files            PostgresJSON `gorm:"type:jsonb"`
+	InspectedAt      time.Time
+}
+
+// FileNode is an entry in the file tree of a layer. Files are the most common type so their Type is empty.
+type FileNode struct {
+	Name     string
+	Type     string      `json:",omitempty"` // dir, symlink, hardlink, whiteout or other
+	Size     int64       `json:",omitempty"` // For directories this is the size of everything in them
+	Target   string      `json:",omitempty"` // Links only
+	Opaque   bool        `json:",omitempty"` // Directory hides the contents of lower layers
+	Children []*FileNode `json:",omitempty"`
+}
+
+// License is parsed from the org.opencontainers.image.licenses or org.label-schema.license label.
+// Code is the whole label, which can be an SPDX license expression. URL is only set if there's
+// a single license.
 type License struct {
-	Code string `json:"Code,omitempty"`
-	URL  string `json:"URL,omitempty"`
+	Code     string          `json:"Code,omitempty"`
+	URL      string          `json:"URL,omitempty"`
+	Licenses []LicenseDetail `json:"Licenses,omitempty"`
 }
 
-// VersionControl is parsed from the org.label-schema.vcs-* labels.
+// LicenseDetail is one of the licenses in an SPDX license expression. The name and URLs
+// are only set for identifiers in the SPDX license list.
+type LicenseDetail struct {
+	Code         string `json:"Code"`
+	Name         string `json:"Name,omitempty"`
+	URL          string `json:"URL,omitempty"`
+	OSIApproved  bool   `json:"OSIApproved"`
+	Exception    string `json:"Exception,omitempty"`
+	ExceptionURL string `json:"ExceptionURL,omitempty"`
+}
+
+// VersionControl is parsed from the org.opencontainers.image.source and revision labels,
+// or the org.label-schema.vcs-* labels.
 type VersionControl struct {
-	Type   string
-	URL    string
-	Commit string
+	Type     string
+	URL      string
+	Commit   string
+	Provider string `json:",omitempty"` // The hosting service if we know it, e.g. github or gitlab
+}
+
+// ImageMetadata is parsed from the descriptive org.opencontainers.image.* labels, or the
+// equivalent org.label-schema.* labels.
+type ImageMetadata struct {
+	Title         string `json:",omitempty"`
+	Version       string `json:",omitempty"`
+	Created       string `json:",omitempty"`
+	URL           string `json:",omitempty"`
+	Documentation string `json:",omitempty"`
+	Vendor        string `json:",omitempty"`
 }
 
 // User is a user, and has to refer to potentially multiple authorizations
@@ -196,6 +279,35 @@ type UserSetting struct {
 	HasPrivateRegistrySupport bool `json:",omitempty"`
 }
 
+// InspectionFailure counts the failed inspections of an image since it was last inspected
+// successfully. Images that fail too many times are dead-lettered until an admin retries them.
+type InspectionFailure struct {
+	ImageName     string `gorm:"primary_key"`
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	DeadLettered  bool `json:"-"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// DeadLetterList is a page of dead-lettered images
+type DeadLetterList struct {
+	CurrentPage     int                 `json:",omitempty"`
+	PageCount       int                 `json:",omitempty"`
+	DeadLetterCount int                 `json:",omitempty"`
+	DeadLetters     []InspectionFailure `json:",omitempty"`
+}
+
+// ReinspectionPolicy sets how often the scheduler re-inspects images. Images that users have
+// favourited or set up notifications for are checked most often, then popular images.
+type ReinspectionPolicy struct {
+	SubscribedInterval time.Duration
+	PopularInterval    time.Duration
+	DefaultInterval    time.Duration
+	PopularPullCount   int // Images with at least this many pulls are popular
+}
+
 // Favourite is an image that a user wanted to keep track of
 type Favourite struct {
 	User   User
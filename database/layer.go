@@ -0,0 +1,12 @@
+package database
+
+// GetLayerContents gets the contents of a layer blob if it has been inspected
+func (d *PgDB) GetLayerContents(blobSum string) (lc LayerContents, err error) {
+	err = d.db.Where("blob_sum = ?", blobSum).First(&lc).Error
+	return lc, err
+}
+
+// PutLayerContents saves the contents of a layer blob
+func (d *PgDB) PutLayerContents(lc LayerContents) error {
+	return d.db.Save(&lc).Error
+}
@@ -0,0 +1,55 @@
+// +build dbrequired
+
+package database
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLayerContents(t *testing.T) {
+	var err error
+	var db PgDB
+
+	db = getDatabase(t)
+	emptyDatabase(db)
+
+	_, err = db.GetLayerContents("sha256:layer1")
+	if err == nil {
+		t.Errorf("Expected error getting layer that hasn't been inspected")
+	}
+
+	lc := LayerContents{
+		BlobSum:          "sha256:layer1",
+		UncompressedSize: 10240,
+		FileCount:        2,
+		Files:            PostgresJSON{RawMessage: json.RawMessage(`{"Name":"/","Type":"dir","Size":12,"Children":[{"Name":"app","Size":12}]}`)},
+	}
+
+	err = db.PutLayerContents(lc)
+	if err != nil {
+		t.Errorf("Error saving layer contents: %v", err)
+	}
+
+	// Saving again updates the existing layer
+	lc.FileCount = 3
+	err = db.PutLayerContents(lc)
+	if err != nil {
+		t.Errorf("Error saving layer contents: %v", err)
+	}
+
+	lc2, err := db.GetLayerContents("sha256:layer1")
+	if err != nil {
+		t.Errorf("Error getting layer contents: %v", err)
+	}
+
+	if lc2.UncompressedSize != 10240 || lc2.FileCount != 3 {
+		t.Errorf("Unexpected layer contents %v", lc2)
+	}
+
+	var root FileNode
+	err = json.Unmarshal(lc2.Files.RawMessage, &root)
+	if err != nil || len(root.Children) != 1 || root.Children[0].Name != "app" {
+		t.Errorf("Unexpected files %v: %v", root, err)
+	}
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// Postgres notification channel for images that have been saved or deleted
+	constImageChangedChannel = "image_changed"
+
+	constListenMinReconnect = 1 * time.Second
+	constListenMaxReconnect = 1 * time.Minute
+)
+
+// ListenForImageChanges calls changed with the name of each image that is saved or deleted by
+// any process using the database. If the connection is lost, notifications might have been
+// missed, so changed is called with an empty name once it's reconnected.
+func (d *PgDB) ListenForImageChanges(changed func(name string)) error {
+	listener := pq.NewListener(d.params, constListenMinReconnect, constListenMaxReconnect, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Errorf("Image change listener error: %v", err)
+		}
+	})
+
+	err := listener.Listen(constImageChangedChannel)
+	if err != nil {
+		listener.Close()
+		return err
+	}
+
+	go func() {
+		for n := range listener.Notify {
+			// A nil notification means we've reconnected
+			if n == nil {
+				changed("")
+				continue
+			}
+
+			changed(n.Extra)
+		}
+	}()
+
+	return nil
+}
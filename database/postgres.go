@@ -1,6 +1,7 @@
 package database
 
 import (
+	"database/sql"
 	"fmt"
 	"os"
 	"time"
@@ -27,8 +28,13 @@ const (
 // PgDB is our postgres database
 type PgDB struct {
 	db           *gorm.DB
+	params       string
 	SessionStore *gormstore.Store
 	SiteURL      string
+
+	// ImageChanged is called with the image name after this process saves or deletes an image.
+	// Use ListenForImageChanges to hear about changes made by other processes.
+	ImageChanged func(name string)
 }
 
 // Exec does a raw SQL command on the database
@@ -36,6 +42,24 @@ func (d *PgDB) Exec(cmd string, params ...interface{}) {
 	d.db.Exec(cmd, params...)
 }
 
+// SQL returns the underlying database connection pool
+func (d *PgDB) SQL() *sql.DB {
+	return d.db.DB()
+}
+
+// imageChanged tells the listener, if there is one, and any other processes listening for
+// notifications that the image has been saved or deleted
+func (d *PgDB) imageChanged(name string) {
+	if d.ImageChanged != nil {
+		d.ImageChanged(name)
+	}
+
+	err := d.db.Exec("SELECT pg_notify(?, ?)", constImageChangedChannel, name).Error
+	if err != nil {
+		log.Errorf("Failed to notify that image %s changed: %v", name, err)
+	}
+}
+
 // GetDB returns a database connection.
 func GetDB() (db PgDB, err error) {
 	host := utils.GetEnvOrDefault("MB_DB_HOST", "postgres")
@@ -52,7 +76,7 @@ func GetDB() (db PgDB, err error) {
 func GetPostgres(host string, user string, dbname string, password string, debug bool) (db PgDB, err error) {
 	params := fmt.Sprintf("host=%s user=%s dbname=%s sslmode=disable password=%s", host, user, dbname, password)
 	log.Debugf("Opening postgres with params %s", params)
-	db = PgDB{}
+	db = PgDB{params: params}
 	var gormDb *gorm.DB
 
 	attempts := 0
@@ -81,7 +105,7 @@ func GetPostgres(host string, user string, dbname string, password string, debug
 		db.db = gormDb
 	}
 
-	db.db.AutoMigrate(&Registry{}, &Image{}, &ImageVersion{}, &Tag{}, &Favourite{}, &User{}, &UserAuth{}, &UserSetting{}, &UserImagePermission{}, &UserRegistryCredential{}, &Notification{}, &NotificationMessage{})
+	db.db.AutoMigrate(&Registry{}, &Image{}, &ImageVersion{}, &Tag{}, &Platform{}, &Favourite{}, &User{}, &UserAuth{}, &UserSetting{}, &UserImagePermission{}, &UserRegistryCredential{}, &Notification{}, &NotificationMessage{}, &LayerContents{}, &InspectionFailure{})
 
 	// Session store
 	db.SessionStore = gormstore.New(db.db, []byte(os.Getenv("MB_SESSION_SECRET")))
@@ -92,7 +116,9 @@ func GetPostgres(host string, user string, dbname string, password string, debug
 	// Initialize the Docker Hub registry if it's not already there
 	_, dockerMissing := db.GetRegistry("docker")
 	if dockerMissing != nil {
-		db.PutRegistry(&Registry{ID: "docker", Name: "Docker Hub", Url: "https://hub.docker.com"})
+		db.PutRegistry(&Registry{ID: "docker", Name: "Docker Hub", Url: "https://hub.docker.com", Host: utils.DockerHubHost})
+	} else {
+		db.db.Model(&Registry{}).Where("id = ? AND (host IS NULL OR host = '')", "docker").Update("host", utils.DockerHubHost)
 	}
 
 	// For building URLs
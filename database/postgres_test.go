@@ -24,6 +24,7 @@ func getDatabase(t *testing.T) PgDB {
 }
 
 func emptyDatabase(db PgDB) {
+	db.Exec("DELETE FROM platforms")
 	db.Exec("DELETE FROM tags")
 	db.Exec("DELETE FROM image_versions")
 	db.Exec("DELETE FROM images")
@@ -38,6 +39,8 @@ func emptyDatabase(db PgDB) {
 	db.Exec("DELETE from user_registry_credentials")
 	db.Exec("DELETE from user_settings")
 	db.Exec("DELETE FROM sessions")
+	db.Exec("DELETE FROM layer_contents")
+	db.Exec("DELETE FROM inspection_failures")
 	db.Exec("DELETE FROM registries WHERE id <> 'docker'") // don't delete the pre-installed Docker registry
 }
 
@@ -1,5 +1,9 @@
 package database
 
+import (
+	"github.com/microscaling/microbadger/utils"
+)
+
 func (d *PgDB) GetRegistry(id string) (reg *Registry, err error) {
 	// Need to initialize an empty Registry so that we get the table name correctly
 	reg = &Registry{}
@@ -11,3 +15,42 @@ func (d *PgDB) PutRegistry(reg *Registry) error {
 	err := d.db.FirstOrCreate(reg).Error
 	return err
 }
+
+// GetRegistryByHost returns the registry for images with the host in their name
+func (d *PgDB) GetRegistryByHost(host string) (reg Registry, err error) {
+	err = d.db.Where("host = ?", host).First(&reg).Error
+	return reg, err
+}
+
+// GetRegistries returns all the supported registries
+func (d *PgDB) GetRegistries() (regs []Registry, err error) {
+	err = d.db.Order("id").Find(&regs).Error
+	return regs, err
+}
+
+// IsDockerHub is true for the Docker Hub registry
+func (r Registry) IsDockerHub() bool {
+	return r.Host == "" || r.Host == utils.DockerHubHost
+}
+
+// ImageName gets the full image name for a repository in this registry. Docker Hub
+// images don't include the host so that existing image names don't change.
+func (r Registry) ImageName(repository string) string {
+	if r.IsDockerHub() {
+		return repository
+	}
+
+	return r.Host + "/" + repository
+}
+
+// getRegistryName gets the name of the image's registry for messages, or the host if
+// the registry isn't in the database
+func (d *PgDB) getRegistryName(image string) string {
+	host, _ := utils.ParseImageName(image)
+	reg, err := d.GetRegistryByHost(host)
+	if err != nil || reg.Name == "" {
+		return host
+	}
+
+	return reg.Name
+}
@@ -14,16 +14,21 @@ func TestRegistry(t *testing.T) {
 		ID:   "test",
 		Name: "My test registry",
 		Url:  "http://test",
+		Host: "registry.test",
 	}
 
 	db = getDatabase(t)
 
 	// Before we empty the database, check that we are setting up the default Docker Hub entry correctly
-	_, err = db.GetRegistry("docker")
+	docker, err := db.GetRegistry("docker")
 	if err != nil {
 		t.Errorf("Didn't initialize the Docker Registry entry: %v", err)
 	}
 
+	if !docker.IsDockerHub() || docker.ImageName("org/image") != "org/image" {
+		t.Errorf("Unexpected Docker Hub registry %v", docker)
+	}
+
 	emptyDatabase(db)
 
 	err = db.PutRegistry(&reg)
@@ -40,8 +45,35 @@ func TestRegistry(t *testing.T) {
 		t.Errorf("Unexpected: %v ", r)
 	}
 
+	if r.ImageName("org/image") != "registry.test/org/image" {
+		t.Errorf("Unexpected image name %s", r.ImageName("org/image"))
+	}
+
+	regs, err := db.GetRegistries()
+	if err != nil || len(regs) != 2 || regs[1].Host != "registry.test" {
+		t.Errorf("Unexpected registries %v: %v", regs, err)
+	}
+
 	_, err = db.GetRegistry("notthere")
 	if err == nil {
 		t.Errorf("Shouldn't be able to get missing registry")
 	}
+
+	r2, err := db.GetRegistryByHost("registry.test")
+	if err != nil || r2.ID != "test" {
+		t.Errorf("Unexpected registry for host %v: %v", r2, err)
+	}
+
+	// Notifications name the image's registry
+	names := map[string]string{
+		"lizrice/childimage":            "Docker Hub",
+		"registry.test/org/image":       "My test registry",
+		"ghcr.io/microscaling/whatever": "ghcr.io",
+	}
+
+	for image, name := range names {
+		if n := db.getRegistryName(image); n != name {
+			t.Errorf("Expected registry name %s for %s, got %s", name, image, n)
+		}
+	}
 }
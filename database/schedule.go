@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// When the image was last inspected or sent for re-inspection
+const constLastChecked = "GREATEST(COALESCE(updated_at, 'epoch'), scheduled_at)"
+
+// GetImagesDueForReinspection returns images that haven't been inspected or scheduled within
+// the interval from the policy, least recently checked first. Images that are waiting to be
+// retried after failing, or that have been dead-lettered, are left alone.
+func (d *PgDB) GetImagesDueForReinspection(p ReinspectionPolicy, limit int) (images []string, err error) {
+	err = d.db.Model(&Image{}).
+		Where("status IN ('INSPECTED', 'SIZE')").
+		Where("NOT EXISTS (SELECT 1 FROM inspection_failures WHERE inspection_failures.image_name = images.name)").
+		Where(constLastChecked+` < now() - interval '1 second' * CASE
+			WHEN EXISTS (SELECT 1 FROM favourites WHERE favourites.image_name = images.name)
+				OR EXISTS (SELECT 1 FROM notifications WHERE notifications.image_name = images.name) THEN CAST(? AS float8)
+			WHEN pull_count >= ? THEN CAST(? AS float8)
+			ELSE CAST(? AS float8) END`,
+			p.SubscribedInterval.Seconds(), p.PopularPullCount, p.PopularInterval.Seconds(), p.DefaultInterval.Seconds()).
+		Order(constLastChecked).
+		Limit(limit).
+		Pluck("name", &images).Error
+
+	return images, err
+}
+
+// MarkImagesScheduled records that the images were sent for re-inspection, without changing
+// when they were last updated
+func (d *PgDB) MarkImagesScheduled(images []string) error {
+	if len(images) == 0 {
+		return nil
+	}
+
+	return d.db.Model(&Image{}).Where("name IN (?)", images).UpdateColumn("scheduled_at", time.Now().UTC()).Error
+}
+
+// AdvisoryLock is a Postgres session-level advisory lock. It's held on its own connection, and
+// Postgres releases it if that connection is lost.
+type AdvisoryLock struct {
+	key  int64
+	conn *sql.Conn
+}
+
+// TryAdvisoryLock takes the lock if nobody else holds it. It returns nil if the lock is taken.
+func (d *PgDB) TryAdvisoryLock(key int64) (*AdvisoryLock, error) {
+	ctx := context.Background()
+	conn, err := d.SQL().Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var locked bool
+	err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&locked)
+	if err != nil || !locked {
+		conn.Close()
+		return nil, err
+	}
+
+	return &AdvisoryLock{key: key, conn: conn}, nil
+}
+
+// Held checks we still have the lock, which we won't if the connection has been lost
+func (l *AdvisoryLock) Held() bool {
+	var held bool
+	err := l.conn.QueryRowContext(context.Background(),
+		"SELECT EXISTS (SELECT 1 FROM pg_locks WHERE locktype = 'advisory' AND pid = pg_backend_pid() AND granted AND ((classid::bigint << 32) | objid::bigint) = $1)",
+		l.key).Scan(&held)
+	if err != nil {
+		log.Errorf("Error checking advisory lock %d: %v", l.key, err)
+		return false
+	}
+
+	return held
+}
+
+// Release the lock and its connection
+func (l *AdvisoryLock) Release() error {
+	_, err := l.conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", l.key)
+	l.conn.Close()
+	return err
+}
@@ -0,0 +1,97 @@
+// +build dbrequired
+
+package database
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/markbates/goth"
+)
+
+func TestGetImagesDueForReinspection(t *testing.T) {
+	db := getDatabase(t)
+	emptyDatabase(db)
+	addThings(db)
+
+	p := ReinspectionPolicy{
+		SubscribedInterval: time.Hour,
+		PopularInterval:    24 * time.Hour,
+		DefaultInterval:    7 * 24 * time.Hour,
+		PopularPullCount:   1000,
+	}
+
+	// Only inspected images are scheduled
+	db.Exec("UPDATE images SET updated_at = now() - interval '2 hours'")
+	db.Exec("UPDATE images SET updated_at = now() - interval '2 days' WHERE name = 'lizrice/childimage'")
+	images, err := db.GetImagesDueForReinspection(p, 10)
+	if err != nil {
+		t.Fatalf("Error getting images: %v", err)
+	}
+
+	if len(images) != 0 {
+		t.Errorf("Expected no images to be due, got %v", images)
+	}
+
+	// Subscribers make the image due sooner, and popular images are due before the others
+	u, _ := db.GetOrCreateUser(User{}, goth.User{Provider: "myprov", UserID: "12345", Name: "myname", Email: "me@myaddress.com"})
+	db.PutFavourite(u, "lizrice/childimage")
+	db.Exec("UPDATE images SET updated_at = now() - interval '3 days' WHERE name = 'lizrice/featured'")
+	images, err = db.GetImagesDueForReinspection(p, 10)
+	if err != nil {
+		t.Fatalf("Error getting images: %v", err)
+	}
+
+	if !reflect.DeepEqual(images, []string{"lizrice/featured", "lizrice/childimage"}) {
+		t.Errorf("Unexpected images due %v", images)
+	}
+
+	images, _ = db.GetImagesDueForReinspection(p, 1)
+	if !reflect.DeepEqual(images, []string{"lizrice/featured"}) {
+		t.Errorf("Unexpected images due with limit %v", images)
+	}
+
+	// Scheduled images aren't due again until the interval has passed
+	err = db.MarkImagesScheduled([]string{"lizrice/featured"})
+	if err != nil {
+		t.Errorf("Error marking images scheduled: %v", err)
+	}
+
+	// Nor are images that are being retried
+	db.PutInspectionFailure(InspectionFailure{ImageName: "lizrice/childimage", Attempts: 1})
+
+	images, _ = db.GetImagesDueForReinspection(p, 10)
+	if len(images) != 0 {
+		t.Errorf("Expected no images to be due, got %v", images)
+	}
+}
+
+func TestAdvisoryLock(t *testing.T) {
+	db := getDatabase(t)
+
+	lock, err := db.TryAdvisoryLock(12345)
+	if err != nil || lock == nil {
+		t.Fatalf("Expected to get the lock, got %v", err)
+	}
+
+	if !lock.Held() {
+		t.Errorf("Expected the lock to be held")
+	}
+
+	other, err := db.TryAdvisoryLock(12345)
+	if err != nil || other != nil {
+		t.Errorf("Expected the lock to be taken, got %v %v", other, err)
+	}
+
+	err = lock.Release()
+	if err != nil {
+		t.Errorf("Error releasing lock: %v", err)
+	}
+
+	other, err = db.TryAdvisoryLock(12345)
+	if err != nil || other == nil {
+		t.Fatalf("Expected to get the lock after it was released, got %v", err)
+	}
+	other.Release()
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/markbates/goth"
 
 	"github.com/microscaling/microbadger/hub"
+	"github.com/microscaling/microbadger/utils"
 )
 
 type userImageAccess struct {
@@ -220,9 +221,14 @@ func deleteUserRegistryCredential(registryID string, userID uint, tx *gorm.DB) (
 // GetRegistryCredentialsForImage gets auth creds for this image so it can be inspected
 func (d *PgDB) GetRegistryCredentialsForImage(imageName string) (registryCreds []UserRegistryCredential, err error) {
 	permsJoin := "JOIN user_image_permissions uip ON uip.user_id = urc.user_id"
+	registryJoin := "JOIN registries r ON r.id = urc.registry_id"
+
+	// Only use credentials for the registry that hosts this image
+	host, _ := utils.ParseImageName(imageName)
 
 	err = d.db.Table("user_registry_credentials urc").
-		Joins(permsJoin).Where("uip.image_name = ?", imageName).
+		Joins(permsJoin).Joins(registryJoin).
+		Where("uip.image_name = ? AND r.host = ?", imageName, host).
 		Select("urc.*").Find(&registryCreds).Error
 	return
 }
@@ -0,0 +1,128 @@
+//go:build ignore
+// +build ignore
+
+// gen_licenses.go writes the SPDX license and exception tables from the SPDX license list data.
+// Run it with go generate.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const constLicenseListData = "https://raw.githubusercontent.com/spdx/license-list-data/main/json/"
+
+type licenseList struct {
+	Version  string `json:"licenseListVersion"`
+	Licenses []struct {
+		ID          string `json:"licenseId"`
+		Name        string `json:"name"`
+		OSIApproved bool   `json:"isOsiApproved"`
+		Deprecated  bool   `json:"isDeprecatedLicenseId"`
+	} `json:"licenses"`
+}
+
+type exceptionList struct {
+	Version    string `json:"licenseListVersion"`
+	Exceptions []struct {
+		ID string `json:"licenseExceptionId"`
+	} `json:"exceptions"`
+}
+
+func main() {
+	licensesFile := flag.String("licenses", constLicenseListData+"licenses.json", "SPDX licenses.json file or URL")
+	exceptionsFile := flag.String("exceptions", constLicenseListData+"exceptions.json", "SPDX exceptions.json file or URL")
+	out := flag.String("out", "licenses_spdx.go", "output file")
+	flag.Parse()
+
+	var licenses licenseList
+	read(*licensesFile, &licenses)
+
+	var exceptions exceptionList
+	read(*exceptionsFile, &exceptions)
+
+	sort.Slice(licenses.Licenses, func(i, j int) bool {
+		return strings.ToLower(licenses.Licenses[i].ID) < strings.ToLower(licenses.Licenses[j].ID)
+	})
+	sort.Slice(exceptions.Exceptions, func(i, j int) bool {
+		return strings.ToLower(exceptions.Exceptions[i].ID) < strings.ToLower(exceptions.Exceptions[j].ID)
+	})
+
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, "// Code generated by gen_licenses.go; DO NOT EDIT.")
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, "package inspector")
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, "// spdxLicenseListVersion is the version of the SPDX license list the tables come from")
+	fmt.Fprintf(&buf, "const spdxLicenseListVersion = %q\n", licenses.Version)
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, "// spdxLicenses are the SPDX licenses by lower case identifier")
+	fmt.Fprintln(&buf, "var spdxLicenses = map[string]spdxLicense{")
+	for _, l := range licenses.Licenses {
+		fmt.Fprintf(&buf, "%q: {ID: %q, Name: %q", strings.ToLower(l.ID), l.ID, l.Name)
+		if l.OSIApproved {
+			fmt.Fprint(&buf, ", OSIApproved: true")
+		}
+		if l.Deprecated {
+			fmt.Fprint(&buf, ", Deprecated: true")
+		}
+		fmt.Fprintln(&buf, "},")
+	}
+	fmt.Fprintln(&buf, "}")
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, "// spdxExceptions are the SPDX license exception identifiers by lower case identifier")
+	fmt.Fprintln(&buf, "var spdxExceptions = map[string]string{")
+	for _, e := range exceptions.Exceptions {
+		fmt.Fprintf(&buf, "%q: %q,\n", strings.ToLower(e.ID), e.ID)
+	}
+	fmt.Fprintln(&buf, "}")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("Error formatting source: %v", err)
+	}
+
+	err = ioutil.WriteFile(*out, src, 0644)
+	if err != nil {
+		log.Fatalf("Error writing %s: %v", *out, err)
+	}
+}
+
+// read unmarshals the JSON from a file or URL
+func read(name string, v interface{}) {
+	var data []byte
+	var err error
+
+	if strings.HasPrefix(name, "https://") {
+		var resp *http.Response
+		resp, err = http.Get(name)
+		if err != nil {
+			log.Fatalf("Error getting %s: %v", name, err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			log.Fatalf("Error getting %s: %s", name, resp.Status)
+		}
+		data, err = ioutil.ReadAll(resp.Body)
+	} else {
+		data, err = ioutil.ReadFile(name)
+	}
+
+	if err != nil {
+		log.Fatalf("Error reading %s: %v", name, err)
+	}
+
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		log.Fatalf("Error parsing %s: %v", name, err)
+	}
+}
@@ -2,9 +2,6 @@ package inspector
 
 import (
 	"encoding/json"
-	"io/ioutil"
-	"net/url"
-	"path"
 	"strings"
 
 	"github.com/microscaling/microbadger/database"
@@ -15,9 +12,26 @@ const (
 	constVersionControlType = "org.label-schema.vcs-type"
 	constVersionControlURL  = "org.label-schema.vcs-url"
 	constVersionControlRef  = "org.label-schema.vcs-ref"
-	constGitHubSSH          = "git@github.com:"
-	constGitHubHTTPS        = "https://github.com/"
-	constLicenseFile        = "inspector/licenses.json"
+	constName               = "org.label-schema.name"
+	constVersion            = "org.label-schema.version"
+	constBuildDate          = "org.label-schema.build-date"
+	constURL                = "org.label-schema.url"
+	constUsage              = "org.label-schema.usage"
+	constVendor             = "org.label-schema.vendor"
+)
+
+// The OpenContainers annotations replace label-schema, so they're used in preference
+// to the equivalent label-schema labels.
+const (
+	constOCILicenses      = "org.opencontainers.image.licenses"
+	constOCISource        = "org.opencontainers.image.source"
+	constOCIRevision      = "org.opencontainers.image.revision"
+	constOCITitle         = "org.opencontainers.image.title"
+	constOCIVersion       = "org.opencontainers.image.version"
+	constOCICreated       = "org.opencontainers.image.created"
+	constOCIURL           = "org.opencontainers.image.url"
+	constOCIDocumentation = "org.opencontainers.image.documentation"
+	constOCIVendor        = "org.opencontainers.image.vendor"
 )
 
 var licenseCodeAltLabels = []string{
@@ -36,28 +50,8 @@ var vcsUrlAltLabels = []string{
 	"vcs-url",
 }
 
-var (
-	licenseCodes map[string]string
-)
-
-func init() {
-	// Load the license data
-	licenses, err := getLicenses()
-	if err != nil {
-		log.Errorf("Error getting license list - %v", err)
-		return
-	}
-
-	licenseCodes = make(map[string]string)
-
-	for _, license := range licenses {
-		licenseCodes[strings.ToLower(license.Code)] = license.URL
-	}
-
-	log.Debugf("License data initialized with %d licenses", len(licenseCodes))
-}
-
-// ParseLabels inspects Docker labels for those matching the label-schema.org schema.
+// ParseLabels inspects Docker labels for those matching the OpenContainers annotations
+// or the label-schema.org schema.
 // TODO Retire badgeCount as its no longer needed.
 func ParseLabels(iv *database.ImageVersion) (badgeCount int, license *database.License, vcs *database.VersionControl) {
 	var labels map[string]string
@@ -84,89 +78,113 @@ func ParseLabels(iv *database.ImageVersion) (badgeCount int, license *database.L
 func parseVersionControl(labels map[string]string) *database.VersionControl {
 
 	vcs := &database.VersionControl{
-		Type:   getLabel(labels, constVersionControlType, vcsTypeAltLabels),
-		URL:    getLabel(labels, constVersionControlURL, vcsUrlAltLabels),
-		Commit: getLabel(labels, constVersionControlRef, vcsRefAltLabels),
+		Type:   getLabel(labels, []string{constVersionControlType}, vcsTypeAltLabels),
+		URL:    getLabel(labels, []string{constOCISource, constVersionControlURL}, vcsUrlAltLabels),
+		Commit: getLabel(labels, []string{constOCIRevision, constVersionControlRef}, vcsRefAltLabels),
 	}
 
-	if vcs.Type == "" || strings.ToLower(vcs.Type) == "git" {
-		// Support for GitHub URLs
-		if vcs.Commit != "" && strings.Contains(vcs.URL, "github.com") {
-			// TODO Add support for BitBucket etc.
-			return parseGitHubLabels(vcs)
-		}
+	if vcs.Type != "" && strings.ToLower(vcs.Type) != "git" {
+		return nil
 	}
 
-	return nil
+	if vcs.Commit == "" || vcs.URL == "" {
+		return nil
+	}
+
+	return parseVCSURL(vcs)
 }
 
 func parseLicense(labels map[string]string) *database.License {
-	code := getLabel(labels, constLicenseCode, licenseCodeAltLabels)
-	if code != "" {
-		license := &database.License{
-			Code: code,
-			URL:  licenseCodes[strings.ToLower(code)],
-		}
+	code := getLabel(labels, []string{constOCILicenses, constLicenseCode}, licenseCodeAltLabels)
+	if code == "" {
+		return nil
+	}
+
+	license := &database.License{Code: code}
 
+	// Free text that isn't an SPDX expression just has the code
+	expr, err := parseLicenseExpression(code)
+	if err != nil {
+		log.Debugf("License %s isn't an SPDX expression: %v", code, err)
 		return license
 	}
 
-	return nil
-}
-
-func parseGitHubLabels(vcs *database.VersionControl) *database.VersionControl {
-	// Set type to Git
-	vcs.Type = "git"
+	license.Licenses = resolveLicenses(expr)
+	if len(license.Licenses) == 1 {
+		license.URL = license.Licenses[0].URL
+	}
 
-	// Convert from SSH to HTTPS URL
-	vcs.URL = strings.Replace(vcs.URL, constGitHubSSH, constGitHubHTTPS, 1)
+	return license
+}
 
-	// Remove .git suffix if present
-	vcs.URL = strings.TrimSuffix(vcs.URL, ".git")
+// ParseMetadata gets the descriptive labels for the image version
+func ParseMetadata(iv *database.ImageVersion) *database.ImageMetadata {
+	var labels map[string]string
 
-	commitURL, err := url.Parse(vcs.URL)
+	err := json.Unmarshal([]byte(iv.Labels), &labels)
 	if err != nil {
-		log.Errorf("Error parsing GitHub URL - %v", err)
+		log.Errorf("Error unmarshalling labels %s: %v", iv.Labels, err)
 		return nil
 	}
 
-	// Link to exact commit
-	commitURL.Path = path.Join(commitURL.Path, "tree", vcs.Commit)
-	vcs.URL = commitURL.String()
-
-	return vcs
+	return parseMetadata(labels)
 }
 
-func getLabel(labels map[string]string, main string, alternatives []string) string {
-
-	value, ok := labels[main]
-	if !ok {
-		// Check for alternative versions
-		for _, alternative := range alternatives {
-			for key, value := range labels {
-				if strings.Contains(key, alternative) {
-					log.Debugf("Found alternative label format %s", alternative)
-					return value
-				}
-			}
-		}
+func parseMetadata(labels map[string]string) *database.ImageMetadata {
+	metadata := &database.ImageMetadata{
+		Title:         getLabel(labels, []string{constOCITitle, constName}, nil),
+		Version:       getLabel(labels, []string{constOCIVersion, constVersion}, nil),
+		Created:       getLabel(labels, []string{constOCICreated, constBuildDate}, nil),
+		URL:           getLabel(labels, []string{constOCIURL, constURL}, nil),
+		Documentation: getLabel(labels, []string{constOCIDocumentation, constUsage}, nil),
+		Vendor:        getLabel(labels, []string{constOCIVendor, constVendor}, nil),
+	}
+
+	if *metadata == (database.ImageMetadata{}) {
+		return nil
 	}
 
-	return value
+	return metadata
 }
 
-func getLicenses() (licenses []*database.License, err error) {
-	raw, err := ioutil.ReadFile(constLicenseFile)
+// parseVCSURL links to the commit if we know the provider. For other hosts we can only
+// link to the repository.
+func parseVCSURL(vcs *database.VersionControl) *database.VersionControl {
+	repo, err := normalizeRepoURL(vcs.URL)
 	if err != nil {
-		log.Errorf("Error reading licenses.json - %v", err)
-		return nil, err
+		log.Errorf("Error parsing VCS URL - %v", err)
+		return nil
 	}
 
-	err = json.Unmarshal(raw, &licenses)
-	if err != nil {
-		log.Errorf("Error unmarshalling licenses.json - %v", err)
-		return nil, err
+	vcs.Type = "git"
+	if p, ok := getVCSProvider(repo.Hostname()); ok {
+		vcs.Provider = p.name
+		vcs.URL = p.commitURL(repo, vcs.Commit)
+	} else {
+		vcs.URL = repo.String()
+	}
+
+	return vcs
+}
+
+// getLabel uses the first of the keys that's set. If none of them are, it looks for
+// a label containing any of the alternatives.
+func getLabel(labels map[string]string, keys []string, alternatives []string) string {
+	for _, key := range keys {
+		if value, ok := labels[key]; ok {
+			return value
+		}
+	}
+
+	// Check for alternative versions
+	for _, alternative := range alternatives {
+		for key, value := range labels {
+			if strings.Contains(key, alternative) {
+				log.Debugf("Found alternative label format %s", alternative)
+				return value
+			}
+		}
 	}
 
-	return
+	return ""
 }
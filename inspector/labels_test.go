@@ -40,7 +40,7 @@ func TestParseGithubLabels(t *testing.T) {
 		vcs = &database.VersionControl{}
 		vcs.URL = test
 		vcs.Commit = "12345"
-		vcs = parseGitHubLabels(vcs)
+		vcs = parseVCSURL(vcs)
 		if vcs.Type != "git" {
 			t.Fatalf("Wrong VCS type: %s", vcs.Type)
 		}
@@ -50,3 +50,72 @@ func TestParseGithubLabels(t *testing.T) {
 		}
 	}
 }
+
+func TestParseLabels(t *testing.T) {
+	var tests = []struct {
+		name    string
+		labels  string
+		license string
+		vcsURL  string
+	}{
+		{
+			name:    "label-schema",
+			labels:  `{"org.label-schema.license":"MIT","org.label-schema.vcs-url":"https://github.com/microscaling/microbadger","org.label-schema.vcs-ref":"12345"}`,
+			license: "MIT",
+			vcsURL:  "https://github.com/microscaling/microbadger/tree/12345",
+		},
+		{
+			name:    "opencontainers",
+			labels:  `{"org.opencontainers.image.licenses":"Apache-2.0","org.opencontainers.image.source":"https://github.com/microscaling/microbadger.git","org.opencontainers.image.revision":"abcde"}`,
+			license: "Apache-2.0",
+			vcsURL:  "https://github.com/microscaling/microbadger/tree/abcde",
+		},
+		{
+			name:    "opencontainers wins",
+			labels:  `{"org.opencontainers.image.licenses":"Apache-2.0","org.label-schema.license":"MIT","org.opencontainers.image.revision":"abcde","org.label-schema.vcs-ref":"12345","org.label-schema.vcs-url":"https://github.com/microscaling/microbadger"}`,
+			license: "Apache-2.0",
+			vcsURL:  "https://github.com/microscaling/microbadger/tree/abcde",
+		},
+		{
+			name:   "none",
+			labels: `{"maintainer":"someone"}`,
+		},
+	}
+
+	for _, test := range tests {
+		_, license, vcs := ParseLabels(&database.ImageVersion{Labels: test.labels})
+
+		if license == nil && test.license != "" || license != nil && license.Code != test.license {
+			t.Errorf("%s: unexpected license %v", test.name, license)
+		}
+
+		if vcs == nil && test.vcsURL != "" || vcs != nil && vcs.URL != test.vcsURL {
+			t.Errorf("%s: unexpected version control %v", test.name, vcs)
+		}
+	}
+}
+
+func TestParseMetadata(t *testing.T) {
+	labels := `{"org.opencontainers.image.title":"microbadger","org.label-schema.name":"old name",` +
+		`"org.label-schema.version":"1.0","org.opencontainers.image.created":"2020-06-01T12:00:00Z",` +
+		`"org.opencontainers.image.url":"https://microbadger.com","org.opencontainers.image.documentation":"https://microbadger.com/docs",` +
+		`"org.label-schema.vendor":"Microscaling Systems"}`
+
+	metadata := ParseMetadata(&database.ImageVersion{Labels: labels})
+	expected := database.ImageMetadata{
+		Title:         "microbadger",
+		Version:       "1.0",
+		Created:       "2020-06-01T12:00:00Z",
+		URL:           "https://microbadger.com",
+		Documentation: "https://microbadger.com/docs",
+		Vendor:        "Microscaling Systems",
+	}
+
+	if metadata == nil || *metadata != expected {
+		t.Errorf("Unexpected metadata %v", metadata)
+	}
+
+	if metadata = ParseMetadata(&database.ImageVersion{Labels: `{"maintainer":"someone"}`}); metadata != nil {
+		t.Errorf("Expected no metadata, got %v", metadata)
+	}
+}
@@ -0,0 +1,272 @@
+package inspector
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/microscaling/microbadger/database"
+	"github.com/microscaling/microbadger/registry"
+)
+
+const (
+	// Default limit on the compressed size of layers we'll download
+	constMaxLayerInspectSize = 500 * 1024 * 1024
+
+	// The file tree for a layer stops growing after this many entries
+	constMaxLayerFiles = 50000
+
+	whiteoutPrefix = ".wh."
+	whiteoutOpaque = ".wh..wh..opq"
+)
+
+var (
+	// Layer contents are only inspected if MB_INSPECT_LAYER_CONTENTS is set as it means
+	// downloading every layer
+	inspectLayerContents       = false
+	maxLayerInspectSize  int64 = constMaxLayerInspectSize
+)
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (n int, err error) {
+	n, err = c.r.Read(p)
+	c.n += int64(n)
+	return
+}
+
+// addLayerContents downloads the layers for this version that we haven't seen before and
+// adds their uncompressed size and file count. Failures are logged rather than returned
+// as the rest of the size information is still useful.
+func addLayerContents(t *registry.TokenAuthClient, db *database.PgDB, iv *database.ImageVersion) (err error) {
+	var layers []database.ImageLayer
+	err = json.Unmarshal([]byte(iv.Layers), &layers)
+	if err != nil {
+		log.Errorf("Error unmarshalling layers for %s: %v", iv.ImageName, err)
+		return err
+	}
+
+	for i, layer := range layers {
+		if layer.BlobSum == "" {
+			continue
+		}
+
+		lc, err := db.GetLayerContents(layer.BlobSum)
+		if err != nil {
+			if layer.DownloadSize > maxLayerInspectSize {
+				log.Infof("Not inspecting layer %s of %s with size %d", layer.BlobSum, iv.ImageName, layer.DownloadSize)
+				continue
+			}
+
+			lc, err = inspectLayer(t, layer.BlobSum)
+			if err != nil {
+				log.Infof("Couldn't inspect layer %s of %s: %v", layer.BlobSum, iv.ImageName, err)
+				if errors.Is(err, registry.ErrRateLimited) {
+					break
+				}
+				continue
+			}
+
+			err = db.PutLayerContents(lc)
+			if err != nil {
+				log.Errorf("Error saving contents of layer %s: %v", layer.BlobSum, err)
+			}
+		}
+
+		layers[i].UncompressedSize = lc.UncompressedSize
+		layers[i].FileCount = lc.FileCount
+	}
+
+	return setVersionLayers(iv, layers)
+}
+
+func inspectLayer(t *registry.TokenAuthClient, blobSum string) (lc database.LayerContents, err error) {
+	log.Debugf("Inspecting contents of layer %s", blobSum)
+
+	blob, err := t.GetBlob(blobSum)
+	if err != nil {
+		return
+	}
+
+	defer blob.Close()
+
+	lc, root, err := getLayerContents(blob)
+	if err != nil {
+		return
+	}
+
+	files, err := json.Marshal(root)
+	if err != nil {
+		return
+	}
+
+	lc.BlobSum = blobSum
+	lc.Files = database.PostgresJSON{RawMessage: files}
+	lc.InspectedAt = time.Now()
+	return
+}
+
+// getLayerContents reads a layer tarball, which is usually gzipped, and builds its file tree.
+// Whiteout files are kept in the tree as they show what the layer deletes from the layers below.
+func getLayerContents(r io.Reader) (lc database.LayerContents, root *database.FileNode, err error) {
+	br := bufio.NewReader(r)
+	var tr io.Reader = br
+
+	// Layers can be uncompressed tarballs
+	magic, _ := br.Peek(2)
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return lc, nil, fmt.Errorf("Error decompressing layer: %v", err)
+		}
+
+		defer gz.Close()
+		tr = gz
+	}
+
+	cr := &countingReader{r: tr}
+	tree := newFileTree()
+
+	archive := tar.NewReader(cr)
+	for {
+		hdr, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return lc, nil, fmt.Errorf("Error reading layer: %v", err)
+		}
+
+		if tree.add(hdr) {
+			lc.FileCount++
+		}
+	}
+
+	// Read any padding after the end of the archive so the size is right
+	_, err = io.Copy(ioutil.Discard, cr)
+	if err != nil {
+		return lc, nil, fmt.Errorf("Error reading layer: %v", err)
+	}
+
+	lc.UncompressedSize = cr.n
+	lc.Truncated = tree.truncated
+	root = tree.root
+	setDirectorySizes(root)
+	return
+}
+
+// fileTree builds the tree of FileNodes from the entries in a tarball
+type fileTree struct {
+	root      *database.FileNode
+	nodes     map[string]*database.FileNode
+	truncated bool
+}
+
+func newFileTree() *fileTree {
+	root := &database.FileNode{Name: "/", Type: "dir"}
+	return &fileTree{
+		root:  root,
+		nodes: map[string]*database.FileNode{".": root},
+	}
+}
+
+// add puts the tar entry into the tree, returning true if it's a file rather than a directory or whiteout
+func (ft *fileTree) add(hdr *tar.Header) bool {
+	name := path.Clean(strings.TrimPrefix(hdr.Name, "/"))
+	if name == "." {
+		return false
+	}
+
+	dir, base := path.Split(name)
+	dir = path.Clean(dir)
+
+	if base == whiteoutOpaque {
+		if n := ft.get(dir, "dir"); n != nil {
+			n.Opaque = true
+		}
+		return false
+	}
+
+	if strings.HasPrefix(base, whiteoutPrefix) {
+		ft.get(path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix)), "whiteout")
+		return false
+	}
+
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		ft.get(name, "dir")
+		return false
+	case tar.TypeReg:
+		if n := ft.get(name, ""); n != nil {
+			n.Size = hdr.Size
+		}
+	case tar.TypeSymlink:
+		if n := ft.get(name, "symlink"); n != nil {
+			n.Target = hdr.Linkname
+		}
+	case tar.TypeLink:
+		if n := ft.get(name, "hardlink"); n != nil {
+			n.Target = hdr.Linkname
+		}
+	default:
+		ft.get(name, "other")
+	}
+
+	return true
+}
+
+// get finds or creates the node for a path, along with any parent directories that weren't
+// in the tarball. Once the tree is full it returns nil for new paths.
+func (ft *fileTree) get(name string, nodeType string) *database.FileNode {
+	if n, ok := ft.nodes[name]; ok {
+		n.Type = nodeType
+		return n
+	}
+
+	if len(ft.nodes) > constMaxLayerFiles {
+		ft.truncated = true
+		return nil
+	}
+
+	parent := ft.get(path.Dir(name), "dir")
+	if parent == nil {
+		return nil
+	}
+
+	n := &database.FileNode{Name: path.Base(name), Type: nodeType}
+	parent.Children = append(parent.Children, n)
+	ft.nodes[name] = n
+	return n
+}
+
+// setDirectorySizes sorts the tree and adds up the size of everything in each directory
+func setDirectorySizes(n *database.FileNode) int64 {
+	if n.Type != "dir" {
+		return n.Size
+	}
+
+	sort.Slice(n.Children, func(i, j int) bool {
+		return n.Children[i].Name < n.Children[j].Name
+	})
+
+	n.Size = 0
+	for _, c := range n.Children {
+		n.Size += setDirectorySizes(c)
+	}
+
+	return n.Size
+}
@@ -0,0 +1,157 @@
+package inspector
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"strconv"
+	"testing"
+
+	"github.com/microscaling/microbadger/database"
+)
+
+type testTarEntry struct {
+	name     string
+	typeflag byte
+	contents string
+	linkname string
+}
+
+func makeTestLayer(t *testing.T, entries []testTarEntry, compress bool) []byte {
+	var buf bytes.Buffer
+	var tw *tar.Writer
+	var gz *gzip.Writer
+
+	if compress {
+		gz = gzip.NewWriter(&buf)
+		tw = tar.NewWriter(gz)
+	} else {
+		tw = tar.NewWriter(&buf)
+	}
+
+	for _, e := range entries {
+		hdr := &tar.Header{
+			Name:     e.name,
+			Typeflag: e.typeflag,
+			Size:     int64(len(e.contents)),
+			Linkname: e.linkname,
+			Mode:     0644,
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("Failed to write header: %v", err)
+		}
+
+		if _, err := tw.Write([]byte(e.contents)); err != nil {
+			t.Fatalf("Failed to write contents: %v", err)
+		}
+	}
+
+	tw.Close()
+	if gz != nil {
+		gz.Close()
+	}
+
+	return buf.Bytes()
+}
+
+func findFileNode(n *database.FileNode, names ...string) *database.FileNode {
+	for _, name := range names {
+		var next *database.FileNode
+		for _, c := range n.Children {
+			if c.Name == name {
+				next = c
+				break
+			}
+		}
+
+		if next == nil {
+			return nil
+		}
+		n = next
+	}
+
+	return n
+}
+
+func TestGetLayerContents(t *testing.T) {
+	entries := []testTarEntry{
+		{name: "etc/", typeflag: tar.TypeDir},
+		{name: "etc/hosts", typeflag: tar.TypeReg, contents: "127.0.0.1 localhost"},
+		{name: "etc/.wh.passwd", typeflag: tar.TypeReg},
+		{name: "usr/local/bin/app", typeflag: tar.TypeReg, contents: "binary"},
+		{name: "usr/local/bin/link", typeflag: tar.TypeSymlink, linkname: "app"},
+		{name: "var/cache/.wh..wh..opq", typeflag: tar.TypeReg},
+		{name: "./root/.aws/credentials", typeflag: tar.TypeReg, contents: "secret"},
+	}
+
+	for _, compress := range []bool{true, false} {
+		layer := makeTestLayer(t, entries, compress)
+
+		lc, root, err := getLayerContents(bytes.NewReader(layer))
+		if err != nil {
+			t.Fatalf("Unexpected error getting layer contents: %v", err)
+		}
+
+		// Whiteouts and directories aren't counted as files
+		if lc.FileCount != 4 || lc.Truncated {
+			t.Errorf("Unexpected file count %d", lc.FileCount)
+		}
+
+		if lc.UncompressedSize < int64(512*len(entries)) {
+			t.Errorf("Uncompressed size %d is too small", lc.UncompressedSize)
+		}
+
+		if !compress && lc.UncompressedSize != int64(len(layer)) {
+			t.Errorf("Uncompressed size %d doesn't match the tar size %d", lc.UncompressedSize, len(layer))
+		}
+
+		if root.Size != 31 {
+			t.Errorf("Unexpected total size %d", root.Size)
+		}
+
+		if n := findFileNode(root, "etc", "passwd"); n == nil || n.Type != "whiteout" {
+			t.Errorf("Expected whiteout for /etc/passwd, got %v", n)
+		}
+
+		if n := findFileNode(root, "usr", "local", "bin"); n == nil || n.Type != "dir" || n.Size != 6 || len(n.Children) != 2 || n.Children[0].Name != "app" {
+			t.Errorf("Unexpected /usr/local/bin %v", n)
+		}
+
+		if n := findFileNode(root, "usr", "local", "bin", "link"); n == nil || n.Type != "symlink" || n.Target != "app" {
+			t.Errorf("Unexpected link %v", n)
+		}
+
+		if n := findFileNode(root, "var", "cache"); n == nil || !n.Opaque || len(n.Children) != 0 {
+			t.Errorf("Expected opaque /var/cache, got %v", n)
+		}
+
+		if n := findFileNode(root, "root", ".aws", "credentials"); n == nil || n.Type != "" || n.Size != 6 {
+			t.Errorf("Unexpected credentials file %v", n)
+		}
+	}
+}
+
+func TestGetLayerContentsTruncated(t *testing.T) {
+	var entries []testTarEntry
+	for i := 0; i < constMaxLayerFiles+10; i++ {
+		entries = append(entries, testTarEntry{name: "files/" + string(rune('a'+i%26)) + "/" + strconv.Itoa(i), typeflag: tar.TypeReg})
+	}
+
+	lc, root, err := getLayerContents(bytes.NewReader(makeTestLayer(t, entries, true)))
+	if err != nil {
+		t.Fatalf("Unexpected error getting layer contents: %v", err)
+	}
+
+	if !lc.Truncated || lc.FileCount != len(entries) {
+		t.Errorf("Expected truncated tree with %d files, got %v %d", len(entries), lc.Truncated, lc.FileCount)
+	}
+
+	if root == nil || findFileNode(root, "files") == nil {
+		t.Errorf("Expected partial tree")
+	}
+
+	if _, _, err = getLayerContents(bytes.NewReader([]byte("not a tarball"))); err == nil {
+		t.Errorf("Expected error for a layer that isn't a tarball")
+	}
+}
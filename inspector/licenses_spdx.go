@@ -0,0 +1,717 @@
+// Code generated by gen_licenses.go; DO NOT EDIT.
+
+package inspector
+
+// spdxLicenseListVersion is the version of the SPDX license list the tables come from
+const spdxLicenseListVersion = "3.23"
+
+// spdxLicenses are the SPDX licenses by lower case identifier
+var spdxLicenses = map[string]spdxLicense{
+	"0bsd":                                 {ID: "0BSD", Name: "BSD Zero Clause License", OSIApproved: true},
+	"aal":                                  {ID: "AAL", Name: "Attribution Assurance License", OSIApproved: true},
+	"abstyles":                             {ID: "Abstyles", Name: "Abstyles License"},
+	"adacore-doc":                          {ID: "AdaCore-doc", Name: "AdaCore Doc License"},
+	"adobe-2006":                           {ID: "Adobe-2006", Name: "Adobe Systems Incorporated Source Code License Agreement"},
+	"adobe-display-postscript":             {ID: "Adobe-Display-PostScript", Name: "Adobe Display PostScript License"},
+	"adobe-glyph":                          {ID: "Adobe-Glyph", Name: "Adobe Glyph List License"},
+	"adobe-utopia":                         {ID: "Adobe-Utopia", Name: "Adobe Utopia Font License"},
+	"adsl":                                 {ID: "ADSL", Name: "Amazon Digital Services License"},
+	"afl-1.1":                              {ID: "AFL-1.1", Name: "Academic Free License v1.1", OSIApproved: true},
+	"afl-1.2":                              {ID: "AFL-1.2", Name: "Academic Free License v1.2", OSIApproved: true},
+	"afl-2.0":                              {ID: "AFL-2.0", Name: "Academic Free License v2.0", OSIApproved: true},
+	"afl-2.1":                              {ID: "AFL-2.1", Name: "Academic Free License v2.1", OSIApproved: true},
+	"afl-3.0":                              {ID: "AFL-3.0", Name: "Academic Free License v3.0", OSIApproved: true},
+	"afmparse":                             {ID: "Afmparse", Name: "Afmparse License"},
+	"agpl-1.0":                             {ID: "AGPL-1.0", Name: "Affero General Public License v1.0"},
+	"agpl-1.0-only":                        {ID: "AGPL-1.0-only", Name: "Affero General Public License v1.0 only"},
+	"agpl-1.0-or-later":                    {ID: "AGPL-1.0-or-later", Name: "Affero General Public License v1.0 or later"},
+	"agpl-3.0":                             {ID: "AGPL-3.0", Name: "GNU Affero General Public License v3.0", OSIApproved: true},
+	"agpl-3.0-only":                        {ID: "AGPL-3.0-only", Name: "GNU Affero General Public License v3.0 only", OSIApproved: true},
+	"agpl-3.0-or-later":                    {ID: "AGPL-3.0-or-later", Name: "GNU Affero General Public License v3.0 or later", OSIApproved: true},
+	"aladdin":                              {ID: "Aladdin", Name: "Aladdin Free Public License"},
+	"amdplpa":                              {ID: "AMDPLPA", Name: "AMD's plpa_map.c License"},
+	"aml":                                  {ID: "AML", Name: "Apple MIT License"},
+	"aml-glslang":                          {ID: "AML-glslang", Name: "AML glslang variant License"},
+	"ampas":                                {ID: "AMPAS", Name: "Academy of Motion Picture Arts and Sciences BSD"},
+	"antlr-pd":                             {ID: "ANTLR-PD", Name: "ANTLR Software Rights Notice"},
+	"antlr-pd-fallback":                    {ID: "ANTLR-PD-fallback", Name: "ANTLR Software Rights Notice with license fallback"},
+	"apache-1.0":                           {ID: "Apache-1.0", Name: "Apache License 1.0"},
+	"apache-1.1":                           {ID: "Apache-1.1", Name: "Apache License 1.1", OSIApproved: true},
+	"apache-2.0":                           {ID: "Apache-2.0", Name: "Apache License 2.0", OSIApproved: true},
+	"apafml":                               {ID: "APAFML", Name: "Adobe Postscript AFM License"},
+	"apl-1.0":                              {ID: "APL-1.0", Name: "Adaptive Public License 1.0", OSIApproved: true},
+	"app-s2p":                              {ID: "App-s2p", Name: "App::s2p License"},
+	"apsl-1.0":                             {ID: "APSL-1.0", Name: "Apple Public Source License 1.0", OSIApproved: true},
+	"apsl-1.1":                             {ID: "APSL-1.1", Name: "Apple Public Source License 1.1", OSIApproved: true},
+	"apsl-1.2":                             {ID: "APSL-1.2", Name: "Apple Public Source License 1.2", OSIApproved: true},
+	"apsl-2.0":                             {ID: "APSL-2.0", Name: "Apple Public Source License 2.0", OSIApproved: true},
+	"arphic-1999":                          {ID: "Arphic-1999", Name: "Arphic Public License"},
+	"artistic-1.0":                         {ID: "Artistic-1.0", Name: "Artistic License 1.0", OSIApproved: true},
+	"artistic-1.0-cl8":                     {ID: "Artistic-1.0-cl8", Name: "Artistic License 1.0 w/clause 8", OSIApproved: true},
+	"artistic-1.0-perl":                    {ID: "Artistic-1.0-Perl", Name: "Artistic License 1.0 (Perl)", OSIApproved: true},
+	"artistic-2.0":                         {ID: "Artistic-2.0", Name: "Artistic License 2.0", OSIApproved: true},
+	"aswf-digital-assets-1.0":              {ID: "ASWF-Digital-Assets-1.0", Name: "ASWF Digital Assets License version 1.0"},
+	"aswf-digital-assets-1.1":              {ID: "ASWF-Digital-Assets-1.1", Name: "ASWF Digital Assets License 1.1"},
+	"baekmuk":                              {ID: "Baekmuk", Name: "Baekmuk License"},
+	"bahyph":                               {ID: "Bahyph", Name: "Bahyph License"},
+	"barr":                                 {ID: "Barr", Name: "Barr License"},
+	"bcrypt-solar-designer":                {ID: "bcrypt-Solar-Designer", Name: "bcrypt Solar Designer License"},
+	"beerware":                             {ID: "Beerware", Name: "Beerware License"},
+	"bitstream-charter":                    {ID: "Bitstream-Charter", Name: "Bitstream Charter Font License"},
+	"bitstream-vera":                       {ID: "Bitstream-Vera", Name: "Bitstream Vera Font License"},
+	"bittorrent-1.0":                       {ID: "BitTorrent-1.0", Name: "BitTorrent Open Source License v1.0"},
+	"bittorrent-1.1":                       {ID: "BitTorrent-1.1", Name: "BitTorrent Open Source License v1.1"},
+	"blessing":                             {ID: "blessing", Name: "SQLite Blessing"},
+	"blueoak-1.0.0":                        {ID: "BlueOak-1.0.0", Name: "Blue Oak Model License 1.0.0", OSIApproved: true},
+	"boehm-gc":                             {ID: "Boehm-GC", Name: "Boehm-Demers-Weiser GC License"},
+	"borceux":                              {ID: "Borceux", Name: "Borceux license"},
+	"brian-gladman-2-clause":               {ID: "Brian-Gladman-2-Clause", Name: "Brian Gladman 2-Clause License"},
+	"brian-gladman-3-clause":               {ID: "Brian-Gladman-3-Clause", Name: "Brian Gladman 3-Clause License"},
+	"bsd-1-clause":                         {ID: "BSD-1-Clause", Name: "BSD 1-Clause License", OSIApproved: true},
+	"bsd-2-clause":                         {ID: "BSD-2-Clause", Name: "BSD 2-Clause \"Simplified\" License", OSIApproved: true},
+	"bsd-2-clause-darwin":                  {ID: "BSD-2-Clause-Darwin", Name: "BSD 2-Clause - Ian Darwin variant"},
+	"bsd-2-clause-freebsd":                 {ID: "BSD-2-Clause-FreeBSD", Name: "BSD 2-Clause FreeBSD License"},
+	"bsd-2-clause-netbsd":                  {ID: "BSD-2-Clause-NetBSD", Name: "BSD 2-Clause NetBSD License"},
+	"bsd-2-clause-patent":                  {ID: "BSD-2-Clause-Patent", Name: "BSD-2-Clause Plus Patent License", OSIApproved: true},
+	"bsd-2-clause-views":                   {ID: "BSD-2-Clause-Views", Name: "BSD 2-Clause with views sentence"},
+	"bsd-3-clause":                         {ID: "BSD-3-Clause", Name: "BSD 3-Clause \"New\" or \"Revised\" License", OSIApproved: true},
+	"bsd-3-clause-acpica":                  {ID: "BSD-3-Clause-acpica", Name: "BSD 3-Clause acpica variant"},
+	"bsd-3-clause-attribution":             {ID: "BSD-3-Clause-Attribution", Name: "BSD with attribution"},
+	"bsd-3-clause-clear":                   {ID: "BSD-3-Clause-Clear", Name: "BSD 3-Clause Clear License"},
+	"bsd-3-clause-flex":                    {ID: "BSD-3-Clause-flex", Name: "BSD 3-Clause Flex variant"},
+	"bsd-3-clause-hp":                      {ID: "BSD-3-Clause-HP", Name: "Hewlett-Packard BSD variant license"},
+	"bsd-3-clause-lbnl":                    {ID: "BSD-3-Clause-LBNL", Name: "Lawrence Berkeley National Labs BSD variant license", OSIApproved: true},
+	"bsd-3-clause-modification":            {ID: "BSD-3-Clause-Modification", Name: "BSD 3-Clause Modification"},
+	"bsd-3-clause-no-military-license":     {ID: "BSD-3-Clause-No-Military-License", Name: "BSD 3-Clause No Military License"},
+	"bsd-3-clause-no-nuclear-license":      {ID: "BSD-3-Clause-No-Nuclear-License", Name: "BSD 3-Clause No Nuclear License"},
+	"bsd-3-clause-no-nuclear-license-2014": {ID: "BSD-3-Clause-No-Nuclear-License-2014", Name: "BSD 3-Clause No Nuclear License 2014"},
+	"bsd-3-clause-no-nuclear-warranty":     {ID: "BSD-3-Clause-No-Nuclear-Warranty", Name: "BSD 3-Clause No Nuclear Warranty"},
+	"bsd-3-clause-open-mpi":                {ID: "BSD-3-Clause-Open-MPI", Name: "BSD 3-Clause Open MPI variant"},
+	"bsd-3-clause-sun":                     {ID: "BSD-3-Clause-Sun", Name: "BSD 3-Clause Sun Microsystems"},
+	"bsd-4-clause":                         {ID: "BSD-4-Clause", Name: "BSD 4-Clause \"Original\" or \"Old\" License"},
+	"bsd-4-clause-shortened":               {ID: "BSD-4-Clause-Shortened", Name: "BSD 4 Clause Shortened"},
+	"bsd-4-clause-uc":                      {ID: "BSD-4-Clause-UC", Name: "BSD-4-Clause (University of California-Specific)"},
+	"bsd-4.3reno":                          {ID: "BSD-4.3RENO", Name: "BSD 4.3 RENO License"},
+	"bsd-4.3tahoe":                         {ID: "BSD-4.3TAHOE", Name: "BSD 4.3 TAHOE License"},
+	"bsd-advertising-acknowledgement":      {ID: "BSD-Advertising-Acknowledgement", Name: "BSD Advertising Acknowledgement License"},
+	"bsd-attribution-hpnd-disclaimer":      {ID: "BSD-Attribution-HPND-disclaimer", Name: "BSD with Attribution and HPND disclaimer"},
+	"bsd-inferno-nettverk":                 {ID: "BSD-Inferno-Nettverk", Name: "BSD-Inferno-Nettverk"},
+	"bsd-protection":                       {ID: "BSD-Protection", Name: "BSD Protection License"},
+	"bsd-source-beginning-file":            {ID: "BSD-Source-beginning-file", Name: "BSD Source Code Attribution - beginning of file variant"},
+	"bsd-source-code":                      {ID: "BSD-Source-Code", Name: "BSD Source Code Attribution"},
+	"bsd-systemics":                        {ID: "BSD-Systemics", Name: "Systemics BSD variant license"},
+	"bsd-systemics-w3works":                {ID: "BSD-Systemics-W3Works", Name: "Systemics W3Works BSD variant license"},
+	"bsl-1.0":                              {ID: "BSL-1.0", Name: "Boost Software License 1.0", OSIApproved: true},
+	"busl-1.1":                             {ID: "BUSL-1.1", Name: "Business Source License 1.1"},
+	"bzip2-1.0.5":                          {ID: "bzip2-1.0.5", Name: "bzip2 and libbzip2 License v1.0.5"},
+	"bzip2-1.0.6":                          {ID: "bzip2-1.0.6", Name: "bzip2 and libbzip2 License v1.0.6"},
+	"c-uda-1.0":                            {ID: "C-UDA-1.0", Name: "Computational Use of Data Agreement v1.0"},
+	"cal-1.0":                              {ID: "CAL-1.0", Name: "Cryptographic Autonomy License 1.0", OSIApproved: true},
+	"cal-1.0-combined-work-exception":      {ID: "CAL-1.0-Combined-Work-Exception", Name: "Cryptographic Autonomy License 1.0 (Combined Work Exception)", OSIApproved: true},
+	"caldera":                              {ID: "Caldera", Name: "Caldera License"},
+	"caldera-no-preamble":                  {ID: "Caldera-no-preamble", Name: "Caldera License (without preamble)"},
+	"catosl-1.1":                           {ID: "CATOSL-1.1", Name: "Computer Associates Trusted Open Source License 1.1", OSIApproved: true},
+	"cc-by-1.0":                            {ID: "CC-BY-1.0", Name: "Creative Commons Attribution 1.0 Generic"},
+	"cc-by-2.0":                            {ID: "CC-BY-2.0", Name: "Creative Commons Attribution 2.0 Generic"},
+	"cc-by-2.5":                            {ID: "CC-BY-2.5", Name: "Creative Commons Attribution 2.5 Generic"},
+	"cc-by-2.5-au":                         {ID: "CC-BY-2.5-AU", Name: "Creative Commons Attribution 2.5 Australia"},
+	"cc-by-3.0":                            {ID: "CC-BY-3.0", Name: "Creative Commons Attribution 3.0 Unported"},
+	"cc-by-3.0-at":                         {ID: "CC-BY-3.0-AT", Name: "Creative Commons Attribution 3.0 Austria"},
+	"cc-by-3.0-au":                         {ID: "CC-BY-3.0-AU", Name: "Creative Commons Attribution 3.0 Australia"},
+	"cc-by-3.0-de":                         {ID: "CC-BY-3.0-DE", Name: "Creative Commons Attribution 3.0 Germany"},
+	"cc-by-3.0-igo":                        {ID: "CC-BY-3.0-IGO", Name: "Creative Commons Attribution 3.0 IGO"},
+	"cc-by-3.0-nl":                         {ID: "CC-BY-3.0-NL", Name: "Creative Commons Attribution 3.0 Netherlands"},
+	"cc-by-3.0-us":                         {ID: "CC-BY-3.0-US", Name: "Creative Commons Attribution 3.0 United States"},
+	"cc-by-4.0":                            {ID: "CC-BY-4.0", Name: "Creative Commons Attribution 4.0 International"},
+	"cc-by-nc-1.0":                         {ID: "CC-BY-NC-1.0", Name: "Creative Commons Attribution Non Commercial 1.0 Generic"},
+	"cc-by-nc-2.0":                         {ID: "CC-BY-NC-2.0", Name: "Creative Commons Attribution Non Commercial 2.0 Generic"},
+	"cc-by-nc-2.5":                         {ID: "CC-BY-NC-2.5", Name: "Creative Commons Attribution Non Commercial 2.5 Generic"},
+	"cc-by-nc-3.0":                         {ID: "CC-BY-NC-3.0", Name: "Creative Commons Attribution Non Commercial 3.0 Unported"},
+	"cc-by-nc-3.0-de":                      {ID: "CC-BY-NC-3.0-DE", Name: "Creative Commons Attribution Non Commercial 3.0 Germany"},
+	"cc-by-nc-4.0":                         {ID: "CC-BY-NC-4.0", Name: "Creative Commons Attribution Non Commercial 4.0 International"},
+	"cc-by-nc-nd-1.0":                      {ID: "CC-BY-NC-ND-1.0", Name: "Creative Commons Attribution Non Commercial No Derivatives 1.0 Generic"},
+	"cc-by-nc-nd-2.0":                      {ID: "CC-BY-NC-ND-2.0", Name: "Creative Commons Attribution Non Commercial No Derivatives 2.0 Generic"},
+	"cc-by-nc-nd-2.5":                      {ID: "CC-BY-NC-ND-2.5", Name: "Creative Commons Attribution Non Commercial No Derivatives 2.5 Generic"},
+	"cc-by-nc-nd-3.0":                      {ID: "CC-BY-NC-ND-3.0", Name: "Creative Commons Attribution Non Commercial No Derivatives 3.0 Unported"},
+	"cc-by-nc-nd-3.0-de":                   {ID: "CC-BY-NC-ND-3.0-DE", Name: "Creative Commons Attribution Non Commercial No Derivatives 3.0 Germany"},
+	"cc-by-nc-nd-3.0-igo":                  {ID: "CC-BY-NC-ND-3.0-IGO", Name: "Creative Commons Attribution Non Commercial No Derivatives 3.0 IGO"},
+	"cc-by-nc-nd-4.0":                      {ID: "CC-BY-NC-ND-4.0", Name: "Creative Commons Attribution Non Commercial No Derivatives 4.0 International"},
+	"cc-by-nc-sa-1.0":                      {ID: "CC-BY-NC-SA-1.0", Name: "Creative Commons Attribution Non Commercial Share Alike 1.0 Generic"},
+	"cc-by-nc-sa-2.0":                      {ID: "CC-BY-NC-SA-2.0", Name: "Creative Commons Attribution Non Commercial Share Alike 2.0 Generic"},
+	"cc-by-nc-sa-2.0-de":                   {ID: "CC-BY-NC-SA-2.0-DE", Name: "Creative Commons Attribution Non Commercial Share Alike 2.0 Germany"},
+	"cc-by-nc-sa-2.0-fr":                   {ID: "CC-BY-NC-SA-2.0-FR", Name: "Creative Commons Attribution-NonCommercial-ShareAlike 2.0 France"},
+	"cc-by-nc-sa-2.0-uk":                   {ID: "CC-BY-NC-SA-2.0-UK", Name: "Creative Commons Attribution Non Commercial Share Alike 2.0 England and Wales"},
+	"cc-by-nc-sa-2.5":                      {ID: "CC-BY-NC-SA-2.5", Name: "Creative Commons Attribution Non Commercial Share Alike 2.5 Generic"},
+	"cc-by-nc-sa-3.0":                      {ID: "CC-BY-NC-SA-3.0", Name: "Creative Commons Attribution Non Commercial Share Alike 3.0 Unported"},
+	"cc-by-nc-sa-3.0-de":                   {ID: "CC-BY-NC-SA-3.0-DE", Name: "Creative Commons Attribution Non Commercial Share Alike 3.0 Germany"},
+	"cc-by-nc-sa-3.0-igo":                  {ID: "CC-BY-NC-SA-3.0-IGO", Name: "Creative Commons Attribution Non Commercial Share Alike 3.0 IGO"},
+	"cc-by-nc-sa-4.0":                      {ID: "CC-BY-NC-SA-4.0", Name: "Creative Commons Attribution Non Commercial Share Alike 4.0 International"},
+	"cc-by-nd-1.0":                         {ID: "CC-BY-ND-1.0", Name: "Creative Commons Attribution No Derivatives 1.0 Generic"},
+	"cc-by-nd-2.0":                         {ID: "CC-BY-ND-2.0", Name: "Creative Commons Attribution No Derivatives 2.0 Generic"},
+	"cc-by-nd-2.5":                         {ID: "CC-BY-ND-2.5", Name: "Creative Commons Attribution No Derivatives 2.5 Generic"},
+	"cc-by-nd-3.0":                         {ID: "CC-BY-ND-3.0", Name: "Creative Commons Attribution No Derivatives 3.0 Unported"},
+	"cc-by-nd-3.0-de":                      {ID: "CC-BY-ND-3.0-DE", Name: "Creative Commons Attribution No Derivatives 3.0 Germany"},
+	"cc-by-nd-4.0":                         {ID: "CC-BY-ND-4.0", Name: "Creative Commons Attribution No Derivatives 4.0 International"},
+	"cc-by-sa-1.0":                         {ID: "CC-BY-SA-1.0", Name: "Creative Commons Attribution Share Alike 1.0 Generic"},
+	"cc-by-sa-2.0":                         {ID: "CC-BY-SA-2.0", Name: "Creative Commons Attribution Share Alike 2.0 Generic"},
+	"cc-by-sa-2.0-uk":                      {ID: "CC-BY-SA-2.0-UK", Name: "Creative Commons Attribution Share Alike 2.0 England and Wales"},
+	"cc-by-sa-2.1-jp":                      {ID: "CC-BY-SA-2.1-JP", Name: "Creative Commons Attribution Share Alike 2.1 Japan"},
+	"cc-by-sa-2.5":                         {ID: "CC-BY-SA-2.5", Name: "Creative Commons Attribution Share Alike 2.5 Generic"},
+	"cc-by-sa-3.0":                         {ID: "CC-BY-SA-3.0", Name: "Creative Commons Attribution Share Alike 3.0 Unported"},
+	"cc-by-sa-3.0-at":                      {ID: "CC-BY-SA-3.0-AT", Name: "Creative Commons Attribution Share Alike 3.0 Austria"},
+	"cc-by-sa-3.0-de":                      {ID: "CC-BY-SA-3.0-DE", Name: "Creative Commons Attribution Share Alike 3.0 Germany"},
+	"cc-by-sa-3.0-igo":                     {ID: "CC-BY-SA-3.0-IGO", Name: "Creative Commons Attribution-ShareAlike 3.0 IGO"},
+	"cc-by-sa-4.0":                         {ID: "CC-BY-SA-4.0", Name: "Creative Commons Attribution Share Alike 4.0 International"},
+	"cc-pddc":                              {ID: "CC-PDDC", Name: "Creative Commons Public Domain Dedication and Certification"},
+	"cc0-1.0":                              {ID: "CC0-1.0", Name: "Creative Commons Zero v1.0 Universal"},
+	"cddl-1.0":                             {ID: "CDDL-1.0", Name: "Common Development and Distribution License 1.0", OSIApproved: true},
+	"cddl-1.1":                             {ID: "CDDL-1.1", Name: "Common Development and Distribution License 1.1"},
+	"cdl-1.0":                              {ID: "CDL-1.0", Name: "Common Documentation License 1.0"},
+	"cdla-permissive-1.0":                  {ID: "CDLA-Permissive-1.0", Name: "Community Data License Agreement Permissive 1.0"},
+	"cdla-permissive-2.0":                  {ID: "CDLA-Permissive-2.0", Name: "Community Data License Agreement Permissive 2.0"},
+	"cdla-sharing-1.0":                     {ID: "CDLA-Sharing-1.0", Name: "Community Data License Agreement Sharing 1.0"},
+	"cecill-1.0":                           {ID: "CECILL-1.0", Name: "CeCILL Free Software License Agreement v1.0"},
+	"cecill-1.1":                           {ID: "CECILL-1.1", Name: "CeCILL Free Software License Agreement v1.1"},
+	"cecill-2.0":                           {ID: "CECILL-2.0", Name: "CeCILL Free Software License Agreement v2.0"},
+	"cecill-2.1":                           {ID: "CECILL-2.1", Name: "CeCILL Free Software License Agreement v2.1", OSIApproved: true},
+	"cecill-b":                             {ID: "CECILL-B", Name: "CeCILL-B Free Software License Agreement"},
+	"cecill-c":                             {ID: "CECILL-C", Name: "CeCILL-C Free Software License Agreement"},
+	"cern-ohl-1.1":                         {ID: "CERN-OHL-1.1", Name: "CERN Open Hardware Licence v1.1"},
+	"cern-ohl-1.2":                         {ID: "CERN-OHL-1.2", Name: "CERN Open Hardware Licence v1.2"},
+	"cern-ohl-p-2.0":                       {ID: "CERN-OHL-P-2.0", Name: "CERN Open Hardware Licence Version 2 - Permissive", OSIApproved: true},
+	"cern-ohl-s-2.0":                       {ID: "CERN-OHL-S-2.0", Name: "CERN Open Hardware Licence Version 2 - Strongly Reciprocal", OSIApproved: true},
+	"cern-ohl-w-2.0":                       {ID: "CERN-OHL-W-2.0", Name: "CERN Open Hardware Licence Version 2 - Weakly Reciprocal", OSIApproved: true},
+	"cfitsio":                              {ID: "CFITSIO", Name: "CFITSIO License"},
+	"check-cvs":                            {ID: "check-cvs", Name: "check-cvs License"},
+	"checkmk":                              {ID: "checkmk", Name: "Checkmk License"},
+	"clartistic":                           {ID: "ClArtistic", Name: "Clarified Artistic License"},
+	"clips":                                {ID: "Clips", Name: "Clips License"},
+	"cmu-mach":                             {ID: "CMU-Mach", Name: "CMU Mach License"},
+	"cmu-mach-nodoc":                       {ID: "CMU-Mach-nodoc", Name: "CMU    Mach - no notices-in-documentation variant"},
+	"cnri-jython":                          {ID: "CNRI-Jython", Name: "CNRI Jython License"},
+	"cnri-python":                          {ID: "CNRI-Python", Name: "CNRI Python License", OSIApproved: true},
+	"cnri-python-gpl-compatible":           {ID: "CNRI-Python-GPL-Compatible", Name: "CNRI Python Open Source GPL Compatible License Agreement"},
+	"coil-1.0":                             {ID: "COIL-1.0", Name: "Copyfree Open Innovation License"},
+	"community-spec-1.0":                   {ID: "Community-Spec-1.0", Name: "Community Specification License 1.0"},
+	"condor-1.1":                           {ID: "Condor-1.1", Name: "Condor Public License v1.1"},
+	"copyleft-next-0.3.0":                  {ID: "copyleft-next-0.3.0", Name: "copyleft-next 0.3.0"},
+	"copyleft-next-0.3.1":                  {ID: "copyleft-next-0.3.1", Name: "copyleft-next 0.3.1"},
+	"cornell-lossless-jpeg":                {ID: "Cornell-Lossless-JPEG", Name: "Cornell Lossless JPEG License"},
+	"cpal-1.0":                             {ID: "CPAL-1.0", Name: "Common Public Attribution License 1.0", OSIApproved: true},
+	"cpl-1.0":                              {ID: "CPL-1.0", Name: "Common Public License 1.0", OSIApproved: true},
+	"cpol-1.02":                            {ID: "CPOL-1.02", Name: "Code Project Open License 1.02"},
+	"cronyx":                               {ID: "Cronyx", Name: "Cronyx License"},
+	"crossword":                            {ID: "Crossword", Name: "Crossword License"},
+	"crystalstacker":                       {ID: "CrystalStacker", Name: "CrystalStacker License"},
+	"cua-opl-1.0":                          {ID: "CUA-OPL-1.0", Name: "CUA Office Public License v1.0", OSIApproved: true},
+	"cube":                                 {ID: "Cube", Name: "Cube License"},
+	"curl":                                 {ID: "curl", Name: "curl License"},
+	"d-fsl-1.0":                            {ID: "D-FSL-1.0", Name: "Deutsche Freie Software Lizenz"},
+	"dec-3-clause":                         {ID: "DEC-3-Clause", Name: "DEC 3-Clause License"},
+	"diffmark":                             {ID: "diffmark", Name: "diffmark license"},
+	"dl-de-by-2.0":                         {ID: "DL-DE-BY-2.0", Name: "Data licence Germany – attribution – version 2.0"},
+	"dl-de-zero-2.0":                       {ID: "DL-DE-ZERO-2.0", Name: "Data licence Germany – zero – version 2.0"},
+	"doc":                                  {ID: "DOC", Name: "DOC License"},
+	"dotseqn":                              {ID: "Dotseqn", Name: "Dotseqn License"},
+	"drl-1.0":                              {ID: "DRL-1.0", Name: "Detection Rule License 1.0"},
+	"drl-1.1":                              {ID: "DRL-1.1", Name: "Detection Rule License 1.1"},
+	"dsdp":                                 {ID: "DSDP", Name: "DSDP License"},
+	"dtoa":                                 {ID: "dtoa", Name: "David M. Gay dtoa License"},
+	"dvipdfm":                              {ID: "dvipdfm", Name: "dvipdfm License"},
+	"ecl-1.0":                              {ID: "ECL-1.0", Name: "Educational Community License v1.0", OSIApproved: true},
+	"ecl-2.0":                              {ID: "ECL-2.0", Name: "Educational Community License v2.0", OSIApproved: true},
+	"ecos-2.0":                             {ID: "eCos-2.0", Name: "eCos license version 2.0"},
+	"efl-1.0":                              {ID: "EFL-1.0", Name: "Eiffel Forum License v1.0", OSIApproved: true},
+	"efl-2.0":                              {ID: "EFL-2.0", Name: "Eiffel Forum License v2.0", OSIApproved: true},
+	"egenix":                               {ID: "eGenix", Name: "eGenix.com Public License 1.1.0"},
+	"elastic-2.0":                          {ID: "Elastic-2.0", Name: "Elastic License 2.0"},
+	"entessa":                              {ID: "Entessa", Name: "Entessa Public License v1.0", OSIApproved: true},
+	"epics":                                {ID: "EPICS", Name: "EPICS Open License"},
+	"epl-1.0":                              {ID: "EPL-1.0", Name: "Eclipse Public License 1.0", OSIApproved: true},
+	"epl-2.0":                              {ID: "EPL-2.0", Name: "Eclipse Public License 2.0", OSIApproved: true},
+	"erlpl-1.1":                            {ID: "ErlPL-1.1", Name: "Erlang Public License v1.1"},
+	"etalab-2.0":                           {ID: "etalab-2.0", Name: "Etalab Open License 2.0"},
+	"eudatagrid":                           {ID: "EUDatagrid", Name: "EU DataGrid Software License", OSIApproved: true},
+	"eupl-1.0":                             {ID: "EUPL-1.0", Name: "European Union Public License 1.0"},
+	"eupl-1.1":                             {ID: "EUPL-1.1", Name: "European Union Public License 1.1", OSIApproved: true},
+	"eupl-1.2":                             {ID: "EUPL-1.2", Name: "European Union Public License 1.2", OSIApproved: true},
+	"eurosym":                              {ID: "Eurosym", Name: "Eurosym License"},
+	"fair":                                 {ID: "Fair", Name: "Fair License", OSIApproved: true},
+	"fbm":                                  {ID: "FBM", Name: "Fuzzy Bitmap License"},
+	"fdk-aac":                              {ID: "FDK-AAC", Name: "Fraunhofer FDK AAC Codec Library"},
+	"ferguson-twofish":                     {ID: "Ferguson-Twofish", Name: "Ferguson Twofish License"},
+	"frameworx-1.0":                        {ID: "Frameworx-1.0", Name: "Frameworx Open License 1.0", OSIApproved: true},
+	"freebsd-doc":                          {ID: "FreeBSD-DOC", Name: "FreeBSD Documentation License"},
+	"freeimage":                            {ID: "FreeImage", Name: "FreeImage Public License v1.0"},
+	"fsfap":                                {ID: "FSFAP", Name: "FSF All Permissive License"},
+	"fsfap-no-warranty-disclaimer":         {ID: "FSFAP-no-warranty-disclaimer", Name: "FSF All Permissive License (without Warranty)"},
+	"fsful":                                {ID: "FSFUL", Name: "FSF Unlimited License"},
+	"fsfullr":                              {ID: "FSFULLR", Name: "FSF Unlimited License (with License Retention)"},
+	"fsfullrwd":                            {ID: "FSFULLRWD", Name: "FSF Unlimited License (With License Retention and Warranty Disclaimer)"},
+	"ftl":                                  {ID: "FTL", Name: "Freetype Project License"},
+	"furuseth":                             {ID: "Furuseth", Name: "Furuseth License"},
+	"fwlw":                                 {ID: "fwlw", Name: "fwlw License"},
+	"gcr-docs":                             {ID: "GCR-docs", Name: "Gnome GCR Documentation License"},
+	"gd":                                   {ID: "GD", Name: "GD License"},
+	"gfdl-1.1":                             {ID: "GFDL-1.1", Name: "GNU Free Documentation License v1.1"},
+	"gfdl-1.1-invariants-only":             {ID: "GFDL-1.1-invariants-only", Name: "GNU Free Documentation License v1.1 only - invariants"},
+	"gfdl-1.1-invariants-or-later":         {ID: "GFDL-1.1-invariants-or-later", Name: "GNU Free Documentation License v1.1 or later - invariants"},
+	"gfdl-1.1-no-invariants-only":          {ID: "GFDL-1.1-no-invariants-only", Name: "GNU Free Documentation License v1.1 only - no invariants"},
+	"gfdl-1.1-no-invariants-or-later":      {ID: "GFDL-1.1-no-invariants-or-later", Name: "GNU Free Documentation License v1.1 or later - no invariants"},
+	"gfdl-1.1-only":                        {ID: "GFDL-1.1-only", Name: "GNU Free Documentation License v1.1 only"},
+	"gfdl-1.1-or-later":                    {ID: "GFDL-1.1-or-later", Name: "GNU Free Documentation License v1.1 or later"},
+	"gfdl-1.2":                             {ID: "GFDL-1.2", Name: "GNU Free Documentation License v1.2"},
+	"gfdl-1.2-invariants-only":             {ID: "GFDL-1.2-invariants-only", Name: "GNU Free Documentation License v1.2 only - invariants"},
+	"gfdl-1.2-invariants-or-later":         {ID: "GFDL-1.2-invariants-or-later", Name: "GNU Free Documentation License v1.2 or later - invariants"},
+	"gfdl-1.2-no-invariants-only":          {ID: "GFDL-1.2-no-invariants-only", Name: "GNU Free Documentation License v1.2 only - no invariants"},
+	"gfdl-1.2-no-invariants-or-later":      {ID: "GFDL-1.2-no-invariants-or-later", Name: "GNU Free Documentation License v1.2 or later - no invariants"},
+	"gfdl-1.2-only":                        {ID: "GFDL-1.2-only", Name: "GNU Free Documentation License v1.2 only"},
+	"gfdl-1.2-or-later":                    {ID: "GFDL-1.2-or-later", Name: "GNU Free Documentation License v1.2 or later"},
+	"gfdl-1.3":                             {ID: "GFDL-1.3", Name: "GNU Free Documentation License v1.3"},
+	"gfdl-1.3-invariants-only":             {ID: "GFDL-1.3-invariants-only", Name: "GNU Free Documentation License v1.3 only - invariants"},
+	"gfdl-1.3-invariants-or-later":         {ID: "GFDL-1.3-invariants-or-later", Name: "GNU Free Documentation License v1.3 or later - invariants"},
+	"gfdl-1.3-no-invariants-only":          {ID: "GFDL-1.3-no-invariants-only", Name: "GNU Free Documentation License v1.3 only - no invariants"},
+	"gfdl-1.3-no-invariants-or-later":      {ID: "GFDL-1.3-no-invariants-or-later", Name: "GNU Free Documentation License v1.3 or later - no invariants"},
+	"gfdl-1.3-only":                        {ID: "GFDL-1.3-only", Name: "GNU Free Documentation License v1.3 only"},
+	"gfdl-1.3-or-later":                    {ID: "GFDL-1.3-or-later", Name: "GNU Free Documentation License v1.3 or later"},
+	"giftware":                             {ID: "Giftware", Name: "Giftware License"},
+	"gl2ps":                                {ID: "GL2PS", Name: "GL2PS License"},
+	"glide":                                {ID: "Glide", Name: "3dfx Glide License"},
+	"glulxe":                               {ID: "Glulxe", Name: "Glulxe License"},
+	"glwtpl":                               {ID: "GLWTPL", Name: "Good Luck With That Public License"},
+	"gnuplot":                              {ID: "gnuplot", Name: "gnuplot License"},
+	"gpl-1.0":                              {ID: "GPL-1.0", Name: "GNU General Public License v1.0 only"},
+	"gpl-1.0+":                             {ID: "GPL-1.0+", Name: "GNU General Public License v1.0 or later"},
+	"gpl-1.0-only":                         {ID: "GPL-1.0-only", Name: "GNU General Public License v1.0 only"},
+	"gpl-1.0-or-later":                     {ID: "GPL-1.0-or-later", Name: "GNU General Public License v1.0 or later"},
+	"gpl-2.0":                              {ID: "GPL-2.0", Name: "GNU General Public License v2.0 only", OSIApproved: true},
+	"gpl-2.0+":                             {ID: "GPL-2.0+", Name: "GNU General Public License v2.0 or later", OSIApproved: true},
+	"gpl-2.0-only":                         {ID: "GPL-2.0-only", Name: "GNU General Public License v2.0 only", OSIApproved: true},
+	"gpl-2.0-or-later":                     {ID: "GPL-2.0-or-later", Name: "GNU General Public License v2.0 or later", OSIApproved: true},
+	"gpl-2.0-with-autoconf-exception":      {ID: "GPL-2.0-with-autoconf-exception", Name: "GNU General Public License v2.0 w/Autoconf exception"},
+	"gpl-2.0-with-bison-exception":         {ID: "GPL-2.0-with-bison-exception", Name: "GNU General Public License v2.0 w/Bison exception"},
+	"gpl-2.0-with-classpath-exception":     {ID: "GPL-2.0-with-classpath-exception", Name: "GNU General Public License v2.0 w/Classpath exception"},
+	"gpl-2.0-with-font-exception":          {ID: "GPL-2.0-with-font-exception", Name: "GNU General Public License v2.0 w/Font exception"},
+	"gpl-2.0-with-gcc-exception":           {ID: "GPL-2.0-with-GCC-exception", Name: "GNU General Public License v2.0 w/GCC Runtime Library exception"},
+	"gpl-3.0":                              {ID: "GPL-3.0", Name: "GNU General Public License v3.0 only", OSIApproved: true},
+	"gpl-3.0+":                             {ID: "GPL-3.0+", Name: "GNU General Public License v3.0 or later", OSIApproved: true},
+	"gpl-3.0-only":                         {ID: "GPL-3.0-only", Name: "GNU General Public License v3.0 only", OSIApproved: true},
+	"gpl-3.0-or-later":                     {ID: "GPL-3.0-or-later", Name: "GNU General Public License v3.0 or later", OSIApproved: true},
+	"gpl-3.0-with-autoconf-exception":      {ID: "GPL-3.0-with-autoconf-exception", Name: "GNU General Public License v3.0 w/Autoconf exception"},
+	"gpl-3.0-with-gcc-exception":           {ID: "GPL-3.0-with-GCC-exception", Name: "GNU General Public License v3.0 w/GCC Runtime Library exception", OSIApproved: true},
+	"graphics-gems":                        {ID: "Graphics-Gems", Name: "Graphics Gems License"},
+	"gsoap-1.3b":                           {ID: "gSOAP-1.3b", Name: "gSOAP Public License v1.3b"},
+	"gtkbook":                              {ID: "gtkbook", Name: "gtkbook License"},
+	"haskellreport":                        {ID: "HaskellReport", Name: "Haskell Language Report License"},
+	"hdparm":                               {ID: "hdparm", Name: "hdparm License"},
+	"hippocratic-2.1":                      {ID: "Hippocratic-2.1", Name: "Hippocratic License 2.1"},
+	"hp-1986":                              {ID: "HP-1986", Name: "Hewlett-Packard 1986 License"},
+	"hp-1989":                              {ID: "HP-1989", Name: "Hewlett-Packard 1989 License"},
+	"hpnd":                                 {ID: "HPND", Name: "Historical Permission Notice and Disclaimer", OSIApproved: true},
+	"hpnd-dec":                             {ID: "HPND-DEC", Name: "Historical Permission Notice and Disclaimer - DEC variant"},
+	"hpnd-doc":                             {ID: "HPND-doc", Name: "Historical Permission Notice and Disclaimer - documentation variant"},
+	"hpnd-doc-sell":                        {ID: "HPND-doc-sell", Name: "Historical Permission Notice and Disclaimer - documentation sell variant"},
+	"hpnd-export-us":                       {ID: "HPND-export-US", Name: "HPND with US Government export control warning"},
+	"hpnd-export-us-modify":                {ID: "HPND-export-US-modify", Name: "HPND with US Government export control warning and modification rqmt"},
+	"hpnd-fenneberg-livingston":            {ID: "HPND-Fenneberg-Livingston", Name: "Historical Permission Notice and Disclaimer - Fenneberg-Livingston variant"},
+	"hpnd-inria-imag":                      {ID: "HPND-INRIA-IMAG", Name: "Historical Permission Notice and Disclaimer    - INRIA-IMAG variant"},
+	"hpnd-kevlin-henney":                   {ID: "HPND-Kevlin-Henney", Name: "Historical Permission Notice and Disclaimer - Kevlin Henney variant"},
+	"hpnd-markus-kuhn":                     {ID: "HPND-Markus-Kuhn", Name: "Historical Permission Notice and Disclaimer - Markus Kuhn variant"},
+	"hpnd-mit-disclaimer":                  {ID: "HPND-MIT-disclaimer", Name: "Historical Permission Notice and Disclaimer with MIT disclaimer"},
+	"hpnd-pbmplus":                         {ID: "HPND-Pbmplus", Name: "Historical Permission Notice and Disclaimer - Pbmplus variant"},
+	"hpnd-sell-mit-disclaimer-xserver":     {ID: "HPND-sell-MIT-disclaimer-xserver", Name: "Historical Permission Notice and Disclaimer - sell xserver variant with MIT disclaimer"},
+	"hpnd-sell-regexpr":                    {ID: "HPND-sell-regexpr", Name: "Historical Permission Notice and Disclaimer - sell regexpr variant"},
+	"hpnd-sell-variant":                    {ID: "HPND-sell-variant", Name: "Historical Permission Notice and Disclaimer - sell variant"},
+	"hpnd-sell-variant-mit-disclaimer":     {ID: "HPND-sell-variant-MIT-disclaimer", Name: "HPND sell variant with MIT disclaimer"},
+	"hpnd-uc":                              {ID: "HPND-UC", Name: "Historical Permission Notice and Disclaimer - University of California variant"},
+	"htmltidy":                             {ID: "HTMLTIDY", Name: "HTML Tidy License"},
+	"ibm-pibs":                             {ID: "IBM-pibs", Name: "IBM PowerPC Initialization and Boot Software"},
+	"icu":                                  {ID: "ICU", Name: "ICU License", OSIApproved: true},
+	"iec-code-components-eula":             {ID: "IEC-Code-Components-EULA", Name: "IEC    Code Components End-user licence agreement"},
+	"ijg":                                  {ID: "IJG", Name: "Independent JPEG Group License"},
+	"ijg-short":                            {ID: "IJG-short", Name: "Independent JPEG Group License - short"},
+	"imagemagick":                          {ID: "ImageMagick", Name: "ImageMagick License"},
+	"imatix":                               {ID: "iMatix", Name: "iMatix Standard Function Library Agreement"},
+	"imlib2":                               {ID: "Imlib2", Name: "Imlib2 License"},
+	"info-zip":                             {ID: "Info-ZIP", Name: "Info-ZIP License"},
+	"inner-net-2.0":                        {ID: "Inner-Net-2.0", Name: "Inner Net License v2.0"},
+	"intel":                                {ID: "Intel", Name: "Intel Open Source License", OSIApproved: true},
+	"intel-acpi":                           {ID: "Intel-ACPI", Name: "Intel ACPI Software License Agreement"},
+	"interbase-1.0":                        {ID: "Interbase-1.0", Name: "Interbase Public License v1.0"},
+	"ipa":                                  {ID: "IPA", Name: "IPA Font License", OSIApproved: true},
+	"ipl-1.0":                              {ID: "IPL-1.0", Name: "IBM Public License v1.0", OSIApproved: true},
+	"isc":                                  {ID: "ISC", Name: "ISC License", OSIApproved: true},
+	"isc-veillard":                         {ID: "ISC-Veillard", Name: "ISC Veillard variant"},
+	"jam":                                  {ID: "Jam", Name: "Jam License", OSIApproved: true},
+	"jasper-2.0":                           {ID: "JasPer-2.0", Name: "JasPer License"},
+	"jpl-image":                            {ID: "JPL-image", Name: "JPL Image Use Policy"},
+	"jpnic":                                {ID: "JPNIC", Name: "Japan Network Information Center License"},
+	"json":                                 {ID: "JSON", Name: "JSON License"},
+	"kastrup":                              {ID: "Kastrup", Name: "Kastrup License"},
+	"kazlib":                               {ID: "Kazlib", Name: "Kazlib License"},
+	"knuth-ctan":                           {ID: "Knuth-CTAN", Name: "Knuth CTAN License"},
+	"lal-1.2":                              {ID: "LAL-1.2", Name: "Licence Art Libre 1.2"},
+	"lal-1.3":                              {ID: "LAL-1.3", Name: "Licence Art Libre 1.3"},
+	"latex2e":                              {ID: "Latex2e", Name: "Latex2e License"},
+	"latex2e-translated-notice":            {ID: "Latex2e-translated-notice", Name: "Latex2e with translated notice permission"},
+	"leptonica":                            {ID: "Leptonica", Name: "Leptonica License"},
+	"lgpl-2.0":                             {ID: "LGPL-2.0", Name: "GNU Library General Public License v2 only", OSIApproved: true},
+	"lgpl-2.0+":                            {ID: "LGPL-2.0+", Name: "GNU Library General Public License v2 or later", OSIApproved: true},
+	"lgpl-2.0-only":                        {ID: "LGPL-2.0-only", Name: "GNU Library General Public License v2 only", OSIApproved: true},
+	"lgpl-2.0-or-later":                    {ID: "LGPL-2.0-or-later", Name: "GNU Library General Public License v2 or later", OSIApproved: true},
+	"lgpl-2.1":                             {ID: "LGPL-2.1", Name: "GNU Lesser General Public License v2.1 only", OSIApproved: true},
+	"lgpl-2.1+":                            {ID: "LGPL-2.1+", Name: "GNU Lesser General Public License v2.1 or later", OSIApproved: true},
+	"lgpl-2.1-only":                        {ID: "LGPL-2.1-only", Name: "GNU Lesser General Public License v2.1 only", OSIApproved: true},
+	"lgpl-2.1-or-later":                    {ID: "LGPL-2.1-or-later", Name: "GNU Lesser General Public License v2.1 or later", OSIApproved: true},
+	"lgpl-3.0":                             {ID: "LGPL-3.0", Name: "GNU Lesser General Public License v3.0 only", OSIApproved: true},
+	"lgpl-3.0+":                            {ID: "LGPL-3.0+", Name: "GNU Lesser General Public License v3.0 or later", OSIApproved: true},
+	"lgpl-3.0-only":                        {ID: "LGPL-3.0-only", Name: "GNU Lesser General Public License v3.0 only", OSIApproved: true},
+	"lgpl-3.0-or-later":                    {ID: "LGPL-3.0-or-later", Name: "GNU Lesser General Public License v3.0 or later", OSIApproved: true},
+	"lgpllr":                               {ID: "LGPLLR", Name: "Lesser General Public License For Linguistic Resources"},
+	"libpng":                               {ID: "Libpng", Name: "libpng License"},
+	"libpng-2.0":                           {ID: "libpng-2.0", Name: "PNG Reference Library version 2"},
+	"libselinux-1.0":                       {ID: "libselinux-1.0", Name: "libselinux public domain notice"},
+	"libtiff":                              {ID: "libtiff", Name: "libtiff License"},
+	"libutil-david-nugent":                 {ID: "libutil-David-Nugent", Name: "libutil David Nugent License"},
+	"liliq-p-1.1":                          {ID: "LiLiQ-P-1.1", Name: "Licence Libre du Québec – Permissive version 1.1", OSIApproved: true},
+	"liliq-r-1.1":                          {ID: "LiLiQ-R-1.1", Name: "Licence Libre du Québec – Réciprocité version 1.1", OSIApproved: true},
+	"liliq-rplus-1.1":                      {ID: "LiLiQ-Rplus-1.1", Name: "Licence Libre du Québec – Réciprocité forte version 1.1", OSIApproved: true},
+	"linux-man-pages-1-para":               {ID: "Linux-man-pages-1-para", Name: "Linux man-pages - 1 paragraph"},
+	"linux-man-pages-copyleft":             {ID: "Linux-man-pages-copyleft", Name: "Linux man-pages Copyleft"},
+	"linux-man-pages-copyleft-2-para":      {ID: "Linux-man-pages-copyleft-2-para", Name: "Linux man-pages Copyleft - 2 paragraphs"},
+	"linux-man-pages-copyleft-var":         {ID: "Linux-man-pages-copyleft-var", Name: "Linux man-pages Copyleft Variant"},
+	"linux-openib":                         {ID: "Linux-OpenIB", Name: "Linux Kernel Variant of OpenIB.org license"},
+	"loop":                                 {ID: "LOOP", Name: "Common Lisp LOOP License"},
+	"lpd-document":                         {ID: "LPD-document", Name: "LPD Documentation License"},
+	"lpl-1.0":                              {ID: "LPL-1.0", Name: "Lucent Public License Version 1.0", OSIApproved: true},
+	"lpl-1.02":                             {ID: "LPL-1.02", Name: "Lucent Public License v1.02", OSIApproved: true},
+	"lppl-1.0":                             {ID: "LPPL-1.0", Name: "LaTeX Project Public License v1.0"},
+	"lppl-1.1":                             {ID: "LPPL-1.1", Name: "LaTeX Project Public License v1.1"},
+	"lppl-1.2":                             {ID: "LPPL-1.2", Name: "LaTeX Project Public License v1.2"},
+	"lppl-1.3a":                            {ID: "LPPL-1.3a", Name: "LaTeX Project Public License v1.3a"},
+	"lppl-1.3c":                            {ID: "LPPL-1.3c", Name: "LaTeX Project Public License v1.3c", OSIApproved: true},
+	"lsof":                                 {ID: "lsof", Name: "lsof License"},
+	"lucida-bitmap-fonts":                  {ID: "Lucida-Bitmap-Fonts", Name: "Lucida Bitmap Fonts License"},
+	"lzma-sdk-9.11-to-9.20":                {ID: "LZMA-SDK-9.11-to-9.20", Name: "LZMA SDK License (versions 9.11 to 9.20)"},
+	"lzma-sdk-9.22":                        {ID: "LZMA-SDK-9.22", Name: "LZMA SDK License (versions 9.22 and beyond)"},
+	"mackerras-3-clause":                   {ID: "Mackerras-3-Clause", Name: "Mackerras 3-Clause License"},
+	"mackerras-3-clause-acknowledgment":    {ID: "Mackerras-3-Clause-acknowledgment", Name: "Mackerras 3-Clause - acknowledgment variant"},
+	"magaz":                                {ID: "magaz", Name: "magaz License"},
+	"mailprio":                             {ID: "mailprio", Name: "mailprio License"},
+	"makeindex":                            {ID: "MakeIndex", Name: "MakeIndex License"},
+	"martin-birgmeier":                     {ID: "Martin-Birgmeier", Name: "Martin Birgmeier License"},
+	"mcphee-slideshow":                     {ID: "McPhee-slideshow", Name: "McPhee Slideshow License"},
+	"metamail":                             {ID: "metamail", Name: "metamail License"},
+	"minpack":                              {ID: "Minpack", Name: "Minpack License"},
+	"miros":                                {ID: "MirOS", Name: "The MirOS Licence", OSIApproved: true},
+	"mit":                                  {ID: "MIT", Name: "MIT License", OSIApproved: true},
+	"mit-0":                                {ID: "MIT-0", Name: "MIT No Attribution", OSIApproved: true},
+	"mit-advertising":                      {ID: "MIT-advertising", Name: "Enlightenment License (e16)"},
+	"mit-cmu":                              {ID: "MIT-CMU", Name: "CMU License"},
+	"mit-enna":                             {ID: "MIT-enna", Name: "enna License"},
+	"mit-feh":                              {ID: "MIT-feh", Name: "feh License"},
+	"mit-festival":                         {ID: "MIT-Festival", Name: "MIT Festival Variant"},
+	"mit-modern-variant":                   {ID: "MIT-Modern-Variant", Name: "MIT License Modern Variant", OSIApproved: true},
+	"mit-open-group":                       {ID: "MIT-open-group", Name: "MIT Open Group variant"},
+	"mit-testregex":                        {ID: "MIT-testregex", Name: "MIT testregex Variant"},
+	"mit-wu":                               {ID: "MIT-Wu", Name: "MIT Tom Wu Variant"},
+	"mitnfa":                               {ID: "MITNFA", Name: "MIT +no-false-attribs license"},
+	"mmixware":                             {ID: "MMIXware", Name: "MMIXware License"},
+	"motosoto":                             {ID: "Motosoto", Name: "Motosoto License", OSIApproved: true},
+	"mpeg-ssg":                             {ID: "MPEG-SSG", Name: "MPEG Software Simulation"},
+	"mpi-permissive":                       {ID: "mpi-permissive", Name: "mpi Permissive License"},
+	"mpich2":                               {ID: "mpich2", Name: "mpich2 License"},
+	"mpl-1.0":                              {ID: "MPL-1.0", Name: "Mozilla Public License 1.0", OSIApproved: true},
+	"mpl-1.1":                              {ID: "MPL-1.1", Name: "Mozilla Public License 1.1", OSIApproved: true},
+	"mpl-2.0":                              {ID: "MPL-2.0", Name: "Mozilla Public License 2.0", OSIApproved: true},
+	"mpl-2.0-no-copyleft-exception":        {ID: "MPL-2.0-no-copyleft-exception", Name: "Mozilla Public License 2.0 (no copyleft exception)", OSIApproved: true},
+	"mplus":                                {ID: "mplus", Name: "mplus Font License"},
+	"ms-lpl":                               {ID: "MS-LPL", Name: "Microsoft Limited Public License"},
+	"ms-pl":                                {ID: "MS-PL", Name: "Microsoft Public License", OSIApproved: true},
+	"ms-rl":                                {ID: "MS-RL", Name: "Microsoft Reciprocal License", OSIApproved: true},
+	"mtll":                                 {ID: "MTLL", Name: "Matrix Template Library License"},
+	"mulanpsl-1.0":                         {ID: "MulanPSL-1.0", Name: "Mulan Permissive Software License, Version 1"},
+	"mulanpsl-2.0":                         {ID: "MulanPSL-2.0", Name: "Mulan Permissive Software License, Version 2", OSIApproved: true},
+	"multics":                              {ID: "Multics", Name: "Multics License", OSIApproved: true},
+	"mup":                                  {ID: "Mup", Name: "Mup License"},
+	"naist-2003":                           {ID: "NAIST-2003", Name: "Nara Institute of Science and Technology License (2003)"},
+	"nasa-1.3":                             {ID: "NASA-1.3", Name: "NASA Open Source Agreement 1.3", OSIApproved: true},
+	"naumen":                               {ID: "Naumen", Name: "Naumen Public License", OSIApproved: true},
+	"nbpl-1.0":                             {ID: "NBPL-1.0", Name: "Net Boolean Public License v1"},
+	"ncgl-uk-2.0":                          {ID: "NCGL-UK-2.0", Name: "Non-Commercial Government Licence"},
+	"ncsa":                                 {ID: "NCSA", Name: "University of Illinois/NCSA Open Source License", OSIApproved: true},
+	"net-snmp":                             {ID: "Net-SNMP", Name: "Net-SNMP License"},
+	"netcdf":                               {ID: "NetCDF", Name: "NetCDF license"},
+	"newsletr":                             {ID: "Newsletr", Name: "Newsletr License"},
+	"ngpl":                                 {ID: "NGPL", Name: "Nethack General Public License", OSIApproved: true},
+	"nicta-1.0":                            {ID: "NICTA-1.0", Name: "NICTA Public Software License, Version 1.0"},
+	"nist-pd":                              {ID: "NIST-PD", Name: "NIST Public Domain Notice"},
+	"nist-pd-fallback":                     {ID: "NIST-PD-fallback", Name: "NIST Public Domain Notice with license fallback"},
+	"nist-software":                        {ID: "NIST-Software", Name: "NIST Software License"},
+	"nlod-1.0":                             {ID: "NLOD-1.0", Name: "Norwegian Licence for Open Government Data (NLOD) 1.0"},
+	"nlod-2.0":                             {ID: "NLOD-2.0", Name: "Norwegian Licence for Open Government Data (NLOD) 2.0"},
+	"nlpl":                                 {ID: "NLPL", Name: "No Limit Public License"},
+	"nokia":                                {ID: "Nokia", Name: "Nokia Open Source License", OSIApproved: true},
+	"nosl":                                 {ID: "NOSL", Name: "Netizen Open Source License"},
+	"noweb":                                {ID: "Noweb", Name: "Noweb License"},
+	"npl-1.0":                              {ID: "NPL-1.0", Name: "Netscape Public License v1.0"},
+	"npl-1.1":                              {ID: "NPL-1.1", Name: "Netscape Public License v1.1"},
+	"nposl-3.0":                            {ID: "NPOSL-3.0", Name: "Non-Profit Open Software License 3.0", OSIApproved: true},
+	"nrl":                                  {ID: "NRL", Name: "NRL License"},
+	"ntp":                                  {ID: "NTP", Name: "NTP License", OSIApproved: true},
+	"ntp-0":                                {ID: "NTP-0", Name: "NTP No Attribution"},
+	"nunit":                                {ID: "Nunit", Name: "Nunit License"},
+	"o-uda-1.0":                            {ID: "O-UDA-1.0", Name: "Open Use of Data Agreement v1.0"},
+	"occt-pl":                              {ID: "OCCT-PL", Name: "Open CASCADE Technology Public License"},
+	"oclc-2.0":                             {ID: "OCLC-2.0", Name: "OCLC Research Public License 2.0", OSIApproved: true},
+	"odbl-1.0":                             {ID: "ODbL-1.0", Name: "Open Data Commons Open Database License v1.0"},
+	"odc-by-1.0":                           {ID: "ODC-By-1.0", Name: "Open Data Commons Attribution License v1.0"},
+	"offis":                                {ID: "OFFIS", Name: "OFFIS License"},
+	"ofl-1.0":                              {ID: "OFL-1.0", Name: "SIL Open Font License 1.0"},
+	"ofl-1.0-no-rfn":                       {ID: "OFL-1.0-no-RFN", Name: "SIL Open Font License 1.0 with no Reserved Font Name"},
+	"ofl-1.0-rfn":                          {ID: "OFL-1.0-RFN", Name: "SIL Open Font License 1.0 with Reserved Font Name"},
+	"ofl-1.1":                              {ID: "OFL-1.1", Name: "SIL Open Font License 1.1", OSIApproved: true},
+	"ofl-1.1-no-rfn":                       {ID: "OFL-1.1-no-RFN", Name: "SIL Open Font License 1.1 with no Reserved Font Name", OSIApproved: true},
+	"ofl-1.1-rfn":                          {ID: "OFL-1.1-RFN", Name: "SIL Open Font License 1.1 with Reserved Font Name", OSIApproved: true},
+	"ogc-1.0":                              {ID: "OGC-1.0", Name: "OGC Software License, Version 1.0"},
+	"ogdl-taiwan-1.0":                      {ID: "OGDL-Taiwan-1.0", Name: "Taiwan Open Government Data License, version 1.0"},
+	"ogl-canada-2.0":                       {ID: "OGL-Canada-2.0", Name: "Open Government Licence - Canada"},
+	"ogl-uk-1.0":                           {ID: "OGL-UK-1.0", Name: "Open Government Licence v1.0"},
+	"ogl-uk-2.0":                           {ID: "OGL-UK-2.0", Name: "Open Government Licence v2.0"},
+	"ogl-uk-3.0":                           {ID: "OGL-UK-3.0", Name: "Open Government Licence v3.0"},
+	"ogtsl":                                {ID: "OGTSL", Name: "Open Group Test Suite License", OSIApproved: true},
+	"oldap-1.1":                            {ID: "OLDAP-1.1", Name: "Open LDAP Public License v1.1"},
+	"oldap-1.2":                            {ID: "OLDAP-1.2", Name: "Open LDAP Public License v1.2"},
+	"oldap-1.3":                            {ID: "OLDAP-1.3", Name: "Open LDAP Public License v1.3"},
+	"oldap-1.4":                            {ID: "OLDAP-1.4", Name: "Open LDAP Public License v1.4"},
+	"oldap-2.0":                            {ID: "OLDAP-2.0", Name: "Open LDAP Public License v2.0 (or possibly 2.0A and 2.0B)"},
+	"oldap-2.0.1":                          {ID: "OLDAP-2.0.1", Name: "Open LDAP Public License v2.0.1"},
+	"oldap-2.1":                            {ID: "OLDAP-2.1", Name: "Open LDAP Public License v2.1"},
+	"oldap-2.2":                            {ID: "OLDAP-2.2", Name: "Open LDAP Public License v2.2"},
+	"oldap-2.2.1":                          {ID: "OLDAP-2.2.1", Name: "Open LDAP Public License v2.2.1"},
+	"oldap-2.2.2":                          {ID: "OLDAP-2.2.2", Name: "Open LDAP Public License 2.2.2"},
+	"oldap-2.3":                            {ID: "OLDAP-2.3", Name: "Open LDAP Public License v2.3"},
+	"oldap-2.4":                            {ID: "OLDAP-2.4", Name: "Open LDAP Public License v2.4"},
+	"oldap-2.5":                            {ID: "OLDAP-2.5", Name: "Open LDAP Public License v2.5"},
+	"oldap-2.6":                            {ID: "OLDAP-2.6", Name: "Open LDAP Public License v2.6"},
+	"oldap-2.7":                            {ID: "OLDAP-2.7", Name: "Open LDAP Public License v2.7"},
+	"oldap-2.8":                            {ID: "OLDAP-2.8", Name: "Open LDAP Public License v2.8", OSIApproved: true},
+	"olfl-1.3":                             {ID: "OLFL-1.3", Name: "Open Logistics Foundation License Version 1.3", OSIApproved: true},
+	"oml":                                  {ID: "OML", Name: "Open Market License"},
+	"openpbs-2.3":                          {ID: "OpenPBS-2.3", Name: "OpenPBS v2.3 Software License"},
+	"openssl":                              {ID: "OpenSSL", Name: "OpenSSL License"},
+	"openssl-standalone":                   {ID: "OpenSSL-standalone", Name: "OpenSSL License - standalone"},
+	"openvision":                           {ID: "OpenVision", Name: "OpenVision License"},
+	"opl-1.0":                              {ID: "OPL-1.0", Name: "Open Public License v1.0"},
+	"opl-uk-3.0":                           {ID: "OPL-UK-3.0", Name: "United    Kingdom Open Parliament Licence v3.0"},
+	"opubl-1.0":                            {ID: "OPUBL-1.0", Name: "Open Publication License v1.0"},
+	"oset-pl-2.1":                          {ID: "OSET-PL-2.1", Name: "OSET Public License version 2.1", OSIApproved: true},
+	"osl-1.0":                              {ID: "OSL-1.0", Name: "Open Software License 1.0", OSIApproved: true},
+	"osl-1.1":                              {ID: "OSL-1.1", Name: "Open Software License 1.1"},
+	"osl-2.0":                              {ID: "OSL-2.0", Name: "Open Software License 2.0", OSIApproved: true},
+	"osl-2.1":                              {ID: "OSL-2.1", Name: "Open Software License 2.1", OSIApproved: true},
+	"osl-3.0":                              {ID: "OSL-3.0", Name: "Open Software License 3.0", OSIApproved: true},
+	"padl":                                 {ID: "PADL", Name: "PADL License"},
+	"parity-6.0.0":                         {ID: "Parity-6.0.0", Name: "The Parity Public License 6.0.0"},
+	"parity-7.0.0":                         {ID: "Parity-7.0.0", Name: "The Parity Public License 7.0.0"},
+	"pddl-1.0":                             {ID: "PDDL-1.0", Name: "Open Data Commons Public Domain Dedication & License 1.0"},
+	"php-3.0":                              {ID: "PHP-3.0", Name: "PHP License v3.0", OSIApproved: true},
+	"php-3.01":                             {ID: "PHP-3.01", Name: "PHP License v3.01", OSIApproved: true},
+	"pixar":                                {ID: "Pixar", Name: "Pixar License"},
+	"plexus":                               {ID: "Plexus", Name: "Plexus Classworlds License"},
+	"pnmstitch":                            {ID: "pnmstitch", Name: "pnmstitch License"},
+	"polyform-noncommercial-1.0.0":         {ID: "PolyForm-Noncommercial-1.0.0", Name: "PolyForm Noncommercial License 1.0.0"},
+	"polyform-small-business-1.0.0":        {ID: "PolyForm-Small-Business-1.0.0", Name: "PolyForm Small Business License 1.0.0"},
+	"postgresql":                           {ID: "PostgreSQL", Name: "PostgreSQL License", OSIApproved: true},
+	"psf-2.0":                              {ID: "PSF-2.0", Name: "Python Software Foundation License 2.0"},
+	"psfrag":                               {ID: "psfrag", Name: "psfrag License"},
+	"psutils":                              {ID: "psutils", Name: "psutils License"},
+	"python-2.0":                           {ID: "Python-2.0", Name: "Python License 2.0", OSIApproved: true},
+	"python-2.0.1":                         {ID: "Python-2.0.1", Name: "Python License 2.0.1"},
+	"python-ldap":                          {ID: "python-ldap", Name: "Python ldap License"},
+	"qhull":                                {ID: "Qhull", Name: "Qhull License"},
+	"qpl-1.0":                              {ID: "QPL-1.0", Name: "Q Public License 1.0", OSIApproved: true},
+	"qpl-1.0-inria-2004":                   {ID: "QPL-1.0-INRIA-2004", Name: "Q Public License 1.0 - INRIA 2004 variant"},
+	"radvd":                                {ID: "radvd", Name: "radvd License"},
+	"rdisc":                                {ID: "Rdisc", Name: "Rdisc License"},
+	"rhecos-1.1":                           {ID: "RHeCos-1.1", Name: "Red Hat eCos Public License v1.1"},
+	"rpl-1.1":                              {ID: "RPL-1.1", Name: "Reciprocal Public License 1.1", OSIApproved: true},
+	"rpl-1.5":                              {ID: "RPL-1.5", Name: "Reciprocal Public License 1.5", OSIApproved: true},
+	"rpsl-1.0":                             {ID: "RPSL-1.0", Name: "RealNetworks Public Source License v1.0", OSIApproved: true},
+	"rsa-md":                               {ID: "RSA-MD", Name: "RSA Message-Digest License"},
+	"rscpl":                                {ID: "RSCPL", Name: "Ricoh Source Code Public License", OSIApproved: true},
+	"ruby":                                 {ID: "Ruby", Name: "Ruby License"},
+	"sax-pd":                               {ID: "SAX-PD", Name: "Sax Public Domain Notice"},
+	"sax-pd-2.0":                           {ID: "SAX-PD-2.0", Name: "Sax Public Domain Notice 2.0"},
+	"saxpath":                              {ID: "Saxpath", Name: "Saxpath License"},
+	"scea":                                 {ID: "SCEA", Name: "SCEA Shared Source License"},
+	"schemereport":                         {ID: "SchemeReport", Name: "Scheme Language Report License"},
+	"sendmail":                             {ID: "Sendmail", Name: "Sendmail License"},
+	"sendmail-8.23":                        {ID: "Sendmail-8.23", Name: "Sendmail License 8.23"},
+	"sgi-b-1.0":                            {ID: "SGI-B-1.0", Name: "SGI Free Software License B v1.0"},
+	"sgi-b-1.1":                            {ID: "SGI-B-1.1", Name: "SGI Free Software License B v1.1"},
+	"sgi-b-2.0":                            {ID: "SGI-B-2.0", Name: "SGI Free Software License B v2.0"},
+	"sgi-opengl":                           {ID: "SGI-OpenGL", Name: "SGI OpenGL License"},
+	"sgp4":                                 {ID: "SGP4", Name: "SGP4 Permission Notice"},
+	"shl-0.5":                              {ID: "SHL-0.5", Name: "Solderpad Hardware License v0.5"},
+	"shl-0.51":                             {ID: "SHL-0.51", Name: "Solderpad Hardware License, Version 0.51"},
+	"simpl-2.0":                            {ID: "SimPL-2.0", Name: "Simple Public License 2.0", OSIApproved: true},
+	"sissl":                                {ID: "SISSL", Name: "Sun Industry Standards Source License v1.1", OSIApproved: true},
+	"sissl-1.2":                            {ID: "SISSL-1.2", Name: "Sun Industry Standards Source License v1.2"},
+	"sl":                                   {ID: "SL", Name: "SL License"},
+	"sleepycat":                            {ID: "Sleepycat", Name: "Sleepycat License", OSIApproved: true},
+	"smlnj":                                {ID: "SMLNJ", Name: "Standard ML of New Jersey License"},
+	"smppl":                                {ID: "SMPPL", Name: "Secure Messaging Protocol Public License"},
+	"snia":                                 {ID: "SNIA", Name: "SNIA Public License 1.1"},
+	"snprintf":                             {ID: "snprintf", Name: "snprintf License"},
+	"softsurfer":                           {ID: "softSurfer", Name: "softSurfer License"},
+	"soundex":                              {ID: "Soundex", Name: "Soundex License"},
+	"spencer-86":                           {ID: "Spencer-86", Name: "Spencer License 86"},
+	"spencer-94":                           {ID: "Spencer-94", Name: "Spencer License 94"},
+	"spencer-99":                           {ID: "Spencer-99", Name: "Spencer License 99"},
+	"spl-1.0":                              {ID: "SPL-1.0", Name: "Sun Public License v1.0", OSIApproved: true},
+	"ssh-keyscan":                          {ID: "ssh-keyscan", Name: "ssh-keyscan License"},
+	"ssh-openssh":                          {ID: "SSH-OpenSSH", Name: "SSH OpenSSH license"},
+	"ssh-short":                            {ID: "SSH-short", Name: "SSH short notice"},
+	"ssleay-standalone":                    {ID: "SSLeay-standalone", Name: "SSLeay License - standalone"},
+	"sspl-1.0":                             {ID: "SSPL-1.0", Name: "Server Side Public License, v 1"},
+	"standardml-nj":                        {ID: "StandardML-NJ", Name: "Standard ML of New Jersey License"},
+	"sugarcrm-1.1.3":                       {ID: "SugarCRM-1.1.3", Name: "SugarCRM Public License v1.1.3"},
+	"sun-ppp":                              {ID: "Sun-PPP", Name: "Sun PPP License"},
+	"sunpro":                               {ID: "SunPro", Name: "SunPro License"},
+	"swl":                                  {ID: "SWL", Name: "Scheme Widget Library (SWL) Software License Agreement"},
+	"swrule":                               {ID: "swrule", Name: "swrule License"},
+	"symlinks":                             {ID: "Symlinks", Name: "Symlinks License"},
+	"tapr-ohl-1.0":                         {ID: "TAPR-OHL-1.0", Name: "TAPR Open Hardware License v1.0"},
+	"tcl":                                  {ID: "TCL", Name: "TCL/TK License"},
+	"tcp-wrappers":                         {ID: "TCP-wrappers", Name: "TCP Wrappers License"},
+	"termreadkey":                          {ID: "TermReadKey", Name: "TermReadKey License"},
+	"tgppl-1.0":                            {ID: "TGPPL-1.0", Name: "Transitive Grace Period Public Licence 1.0"},
+	"tmate":                                {ID: "TMate", Name: "TMate Open Source License"},
+	"torque-1.1":                           {ID: "TORQUE-1.1", Name: "TORQUE v2.5+ Software License v1.1"},
+	"tosl":                                 {ID: "TOSL", Name: "Trusster Open Source License"},
+	"tpdl":                                 {ID: "TPDL", Name: "Time::ParseDate License"},
+	"tpl-1.0":                              {ID: "TPL-1.0", Name: "THOR Public License 1.0"},
+	"ttwl":                                 {ID: "TTWL", Name: "Text-Tabs+Wrap License"},
+	"ttyp0":                                {ID: "TTYP0", Name: "TTYP0 License"},
+	"tu-berlin-1.0":                        {ID: "TU-Berlin-1.0", Name: "Technische Universitaet Berlin License 1.0"},
+	"tu-berlin-2.0":                        {ID: "TU-Berlin-2.0", Name: "Technische Universitaet Berlin License 2.0"},
+	"ucar":                                 {ID: "UCAR", Name: "UCAR License"},
+	"ucl-1.0":                              {ID: "UCL-1.0", Name: "Upstream Compatibility License v1.0", OSIApproved: true},
+	"ulem":                                 {ID: "ulem", Name: "ulem License"},
+	"umich-merit":                          {ID: "UMich-Merit", Name: "Michigan/Merit Networks License"},
+	"unicode-3.0":                          {ID: "Unicode-3.0", Name: "Unicode License v3", OSIApproved: true},
+	"unicode-dfs-2015":                     {ID: "Unicode-DFS-2015", Name: "Unicode License Agreement - Data Files and Software (2015)"},
+	"unicode-dfs-2016":                     {ID: "Unicode-DFS-2016", Name: "Unicode License Agreement - Data Files and Software (2016)", OSIApproved: true},
+	"unicode-tou":                          {ID: "Unicode-TOU", Name: "Unicode Terms of Use"},
+	"unixcrypt":                            {ID: "UnixCrypt", Name: "UnixCrypt License"},
+	"unlicense":                            {ID: "Unlicense", Name: "The Unlicense", OSIApproved: true},
+	"upl-1.0":                              {ID: "UPL-1.0", Name: "Universal Permissive License v1.0", OSIApproved: true},
+	"urt-rle":                              {ID: "URT-RLE", Name: "Utah Raster Toolkit Run Length Encoded License"},
+	"vim":                                  {ID: "Vim", Name: "Vim License"},
+	"vostrom":                              {ID: "VOSTROM", Name: "VOSTROM Public License for Open Source"},
+	"vsl-1.0":                              {ID: "VSL-1.0", Name: "Vovida Software License v1.0", OSIApproved: true},
+	"w3c":                                  {ID: "W3C", Name: "W3C Software Notice and License (2002-12-31)", OSIApproved: true},
+	"w3c-19980720":                         {ID: "W3C-19980720", Name: "W3C Software Notice and License (1998-07-20)"},
+	"w3c-20150513":                         {ID: "W3C-20150513", Name: "W3C Software Notice and Document License (2015-05-13)"},
+	"w3m":                                  {ID: "w3m", Name: "w3m License"},
+	"watcom-1.0":                           {ID: "Watcom-1.0", Name: "Sybase Open Watcom Public License 1.0", OSIApproved: true},
+	"widget-workshop":                      {ID: "Widget-Workshop", Name: "Widget Workshop License"},
+	"wsuipa":                               {ID: "Wsuipa", Name: "Wsuipa License"},
+	"wtfpl":                                {ID: "WTFPL", Name: "Do What The F*ck You Want To Public License"},
+	"wxwindows":                            {ID: "wxWindows", Name: "wxWindows Library License", OSIApproved: true},
+	"x11":                                  {ID: "X11", Name: "X11 License"},
+	"x11-distribute-modifications-variant": {ID: "X11-distribute-modifications-variant", Name: "X11 License Distribution Modification Variant"},
+	"xdebug-1.03":                          {ID: "Xdebug-1.03", Name: "Xdebug License v 1.03"},
+	"xerox":                                {ID: "Xerox", Name: "Xerox License"},
+	"xfig":                                 {ID: "Xfig", Name: "Xfig License"},
+	"xfree86-1.1":                          {ID: "XFree86-1.1", Name: "XFree86 License 1.1"},
+	"xinetd":                               {ID: "xinetd", Name: "xinetd License"},
+	"xkeyboard-config-zinoviev":            {ID: "xkeyboard-config-Zinoviev", Name: "xkeyboard-config Zinoviev License"},
+	"xlock":                                {ID: "xlock", Name: "xlock License"},
+	"xnet":                                 {ID: "Xnet", Name: "X.Net License", OSIApproved: true},
+	"xpp":                                  {ID: "xpp", Name: "XPP License"},
+	"xskat":                                {ID: "XSkat", Name: "XSkat License"},
+	"ypl-1.0":                              {ID: "YPL-1.0", Name: "Yahoo! Public License v1.0"},
+	"ypl-1.1":                              {ID: "YPL-1.1", Name: "Yahoo! Public License v1.1"},
+	"zed":                                  {ID: "Zed", Name: "Zed License"},
+	"zeeff":                                {ID: "Zeeff", Name: "Zeeff License"},
+	"zend-2.0":                             {ID: "Zend-2.0", Name: "Zend License v2.0"},
+	"zimbra-1.3":                           {ID: "Zimbra-1.3", Name: "Zimbra Public License v1.3"},
+	"zimbra-1.4":                           {ID: "Zimbra-1.4", Name: "Zimbra Public License v1.4"},
+	"zlib":                                 {ID: "Zlib", Name: "zlib License", OSIApproved: true},
+	"zlib-acknowledgement":                 {ID: "zlib-acknowledgement", Name: "zlib/libpng License with Acknowledgement"},
+	"zpl-1.1":                              {ID: "ZPL-1.1", Name: "Zope Public License 1.1"},
+	"zpl-2.0":                              {ID: "ZPL-2.0", Name: "Zope Public License 2.0", OSIApproved: true},
+	"zpl-2.1":                              {ID: "ZPL-2.1", Name: "Zope Public License 2.1", OSIApproved: true},
+}
+
+// spdxExceptions are the SPDX license exception identifiers by lower case identifier
+var spdxExceptions = map[string]string{
+	"389-exception":                     "389-exception",
+	"asterisk-exception":                "Asterisk-exception",
+	"autoconf-exception-2.0":            "Autoconf-exception-2.0",
+	"autoconf-exception-3.0":            "Autoconf-exception-3.0",
+	"autoconf-exception-generic":        "Autoconf-exception-generic",
+	"autoconf-exception-generic-3.0":    "Autoconf-exception-generic-3.0",
+	"autoconf-exception-macro":          "Autoconf-exception-macro",
+	"bison-exception-1.24":              "Bison-exception-1.24",
+	"bison-exception-2.2":               "Bison-exception-2.2",
+	"bootloader-exception":              "Bootloader-exception",
+	"classpath-exception-2.0":           "Classpath-exception-2.0",
+	"clisp-exception-2.0":               "CLISP-exception-2.0",
+	"cryptsetup-openssl-exception":      "cryptsetup-OpenSSL-exception",
+	"digirule-foss-exception":           "DigiRule-FOSS-exception",
+	"ecos-exception-2.0":                "eCos-exception-2.0",
+	"fawkes-runtime-exception":          "Fawkes-Runtime-exception",
+	"fltk-exception":                    "FLTK-exception",
+	"fmt-exception":                     "fmt-exception",
+	"font-exception-2.0":                "Font-exception-2.0",
+	"freertos-exception-2.0":            "freertos-exception-2.0",
+	"gcc-exception-2.0":                 "GCC-exception-2.0",
+	"gcc-exception-2.0-note":            "GCC-exception-2.0-note",
+	"gcc-exception-3.1":                 "GCC-exception-3.1",
+	"gmsh-exception":                    "Gmsh-exception",
+	"gnat-exception":                    "GNAT-exception",
+	"gnome-examples-exception":          "GNOME-examples-exception",
+	"gnu-compiler-exception":            "GNU-compiler-exception",
+	"gnu-javamail-exception":            "gnu-javamail-exception",
+	"gpl-3.0-interface-exception":       "GPL-3.0-interface-exception",
+	"gpl-3.0-linking-exception":         "GPL-3.0-linking-exception",
+	"gpl-3.0-linking-source-exception":  "GPL-3.0-linking-source-exception",
+	"gpl-cc-1.0":                        "GPL-CC-1.0",
+	"gstreamer-exception-2005":          "GStreamer-exception-2005",
+	"gstreamer-exception-2008":          "GStreamer-exception-2008",
+	"i2p-gpl-java-exception":            "i2p-gpl-java-exception",
+	"kicad-libraries-exception":         "KiCad-libraries-exception",
+	"lgpl-3.0-linking-exception":        "LGPL-3.0-linking-exception",
+	"libpri-openh323-exception":         "libpri-OpenH323-exception",
+	"libtool-exception":                 "Libtool-exception",
+	"linux-syscall-note":                "Linux-syscall-note",
+	"llgpl":                             "LLGPL",
+	"llvm-exception":                    "LLVM-exception",
+	"lzma-exception":                    "LZMA-exception",
+	"mif-exception":                     "mif-exception",
+	"ocaml-lgpl-linking-exception":      "OCaml-LGPL-linking-exception",
+	"occt-exception-1.0":                "OCCT-exception-1.0",
+	"openjdk-assembly-exception-1.0":    "OpenJDK-assembly-exception-1.0",
+	"openvpn-openssl-exception":         "openvpn-openssl-exception",
+	"ps-or-pdf-font-exception-20170817": "PS-or-PDF-font-exception-20170817",
+	"qpl-1.0-inria-2004-exception":      "QPL-1.0-INRIA-2004-exception",
+	"qt-gpl-exception-1.0":              "Qt-GPL-exception-1.0",
+	"qt-lgpl-exception-1.1":             "Qt-LGPL-exception-1.1",
+	"qwt-exception-1.0":                 "Qwt-exception-1.0",
+	"sane-exception":                    "SANE-exception",
+	"shl-2.0":                           "SHL-2.0",
+	"shl-2.1":                           "SHL-2.1",
+	"stunnel-exception":                 "stunnel-exception",
+	"swi-exception":                     "SWI-exception",
+	"swift-exception":                   "Swift-exception",
+	"texinfo-exception":                 "Texinfo-exception",
+	"u-boot-exception-2.0":              "u-boot-exception-2.0",
+	"ubdl-exception":                    "UBDL-exception",
+	"universal-foss-exception-1.0":      "Universal-FOSS-exception-1.0",
+	"vsftpd-openssl-exception":          "vsftpd-openssl-exception",
+	"wxwindows-exception-3.1":           "WxWindows-exception-3.1",
+	"x11vnc-openssl-exception":          "x11vnc-openssl-exception",
+}
@@ -3,6 +3,7 @@ package inspector
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -23,6 +24,31 @@ var (
 
 func init() {
 	webhookURL = os.Getenv("MB_WEBHOOK_URL")
+
+	if c, err := strconv.Atoi(os.Getenv("MB_REGISTRY_CONCURRENCY")); err == nil && c > 0 {
+		registryConcurrency = c
+	}
+
+	inspectLayerContents = (os.Getenv("MB_INSPECT_LAYER_CONTENTS") == "true")
+	if s, err := strconv.ParseInt(os.Getenv("MB_MAX_LAYER_INSPECT_SIZE"), 10, 64); err == nil && s > 0 {
+		maxLayerInspectSize = s
+	}
+
+	if n, err := strconv.Atoi(os.Getenv("MB_INSPECT_MAX_ATTEMPTS")); err == nil && n > 0 {
+		inspectRetries.maxAttempts = n
+	}
+	if s, err := strconv.Atoi(os.Getenv("MB_INSPECT_RETRY_DELAY")); err == nil && s > 0 {
+		inspectRetries.delay = time.Duration(s) * time.Second
+	}
+	if s, err := strconv.Atoi(os.Getenv("MB_INSPECT_MAX_RETRY_DELAY")); err == nil && s > 0 {
+		inspectRetries.maxDelay = time.Duration(s) * time.Second
+	}
+
+	hosts, err := parseVCSHosts(os.Getenv("MB_VCS_HOSTS"))
+	if err != nil {
+		log.Errorf("Ignoring MB_VCS_HOSTS: %v", err)
+	}
+	vcsHosts = hosts
 }
 
 // CheckImageExists checks if an image exists on DockerHub for this image.
@@ -64,17 +90,19 @@ func Inspect(imageName string, db *database.PgDB, rs *registry.Service, hs *hub.
 	// If this errors try anyway as the image may be public
 	image, _ = getRegistryCredentials(imageName, db, es)
 
-	// Get the information from the hub first
-	hubInfo, err := hs.Info(image)
-	if err != nil {
-		// We still want to carry on in this case as we may be able to get registry info anyway
-		log.Errorf("Failed to get hub info for %s", imageName)
-	}
+	if utils.IsDockerHubImage(imageName) {
+		// Get the information from the hub first
+		hubInfo, err := hs.Info(image)
+		if err != nil {
+			// We still want to carry on in this case as we may be able to get registry info anyway
+			log.Errorf("Failed to get hub info for %s", imageName)
+		}
 
-	// Update Docker Hub metadata and stop if the image hasn't changed
-	hasChanged, img = setHubInfo(img, hubInfo)
-	if !hasChanged {
-		return nil
+		// Update Docker Hub metadata and stop if the image hasn't changed
+		hasChanged, img = setHubInfo(img, hubInfo)
+		if !hasChanged {
+			return nil
+		}
 	}
 
 	versions, err := getVersionsFromRegistry(image, rs)
@@ -85,13 +113,19 @@ func Inspect(imageName string, db *database.PgDB, rs *registry.Service, hs *hub.
 			img.Status = "MISSING"
 		} else {
 			img.Status = "FAILED_INSPECTION"
+			img.StatusReason = err.Error()
 		}
 		img.BadgeCount = 0
 
 		// Save image status so its no longer displayed if it has been deleted or made private.
-		err := db.PutImageOnly(img)
-		if err != nil {
-			log.Errorf("Failed to save image %v", err)
+		putErr := db.PutImageOnly(img)
+		if putErr != nil {
+			log.Errorf("Failed to save image %v", putErr)
+		}
+
+		// Trying again won't help if the image is missing, but other failures are retried
+		if img.Status == "MISSING" {
+			return putErr
 		}
 
 		return err
@@ -106,6 +140,7 @@ func Inspect(imageName string, db *database.PgDB, rs *registry.Service, hs *hub.
 	}
 
 	img.Status = "SIZE"
+	img.StatusReason = ""
 	updateLatest(&img)
 	img.BadgeCount++ // One badge for the link to Docker Hub
 
@@ -23,6 +23,7 @@ func getDatabase(t *testing.T) database.PgDB {
 }
 
 func emptyDatabase(db database.PgDB) {
+	db.Exec("DELETE FROM platforms")
 	db.Exec("DELETE FROM tags")
 	db.Exec("DELETE FROM image_versions")
 	db.Exec("DELETE FROM images")
@@ -6,12 +6,26 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/microscaling/microbadger/database"
 	"github.com/microscaling/microbadger/registry"
 )
 
+// Manifests for each tag are fetched in parallel, up to this many at a time
+var registryConcurrency = 5
+
+// tagVersion is the version info for a single tag. Other platforms of a multi-architecture
+// tag have versions that aren't tagged.
+type tagVersion struct {
+	found            bool
+	version          database.ImageVersion
+	platforms        []database.Platform
+	platformVersions []database.ImageVersion
+	err              error
+}
+
 func getVersionsFromRegistry(i registry.Image, rs *registry.Service) (versions map[string]database.ImageVersion, err error) {
 	versions = make(map[string]database.ImageVersion, 1)
 
@@ -35,28 +49,27 @@ func getVersionsFromRegistry(i registry.Image, rs *registry.Service) (versions m
 
 	// Get the version info for all the tags, looking out for any that match latest
 	log.Debugf("%d tags for %s", len(tags), i.Name)
-	for _, tagName := range tags {
+	results := getTagVersions(t, i.Name, tags, registryConcurrency)
 
-		manifest, manifestBytes, err := t.GetManifest(tagName)
-		if err != nil {
-			// Sometimes the registry returns not found for a tag eevn though that tag was included
-			// in the list of tags - presumably this is the registry in a bit of a bad state.
-			// We don't want to fail the whole image in this situation.
-			err = fmt.Errorf("Error getting manifest for tag %s: %v", tagName, err)
-			if strings.Contains(err.Error(), "404") {
-				continue
-			} else {
-				return versions, err
-			}
+	// Results are in the same order as the tags so this is deterministic
+	for n, tagName := range tags {
+		result := results[n]
+		if result.err != nil {
+			return versions, result.err
 		}
 
-		version, err := getVersionInfo(manifest)
-		if err != nil {
-			log.Errorf("Error getting version info for %s tag %s: %v", i.Name, tagName, err)
-			return versions, err
+		if !result.found {
+			continue
+		}
+
+		for _, pv := range result.platformVersions {
+			if _, ok := versions[pv.SHA]; !ok {
+				versions[pv.SHA] = pv
+			}
 		}
 
 		// We might already know about this version under a different tag name
+		version := result.version
 		if v, ok := versions[version.SHA]; ok {
 			version = v
 		}
@@ -67,14 +80,177 @@ func getVersionsFromRegistry(i registry.Image, rs *registry.Service) (versions m
 			SHA:       version.SHA,
 		}
 
+		for _, p := range result.platforms {
+			p.Tag = tagName
+			version.Platforms = append(version.Platforms, p)
+		}
+
 		version.Tags = append(version.Tags, tag)
-		version.Manifest = string(manifestBytes)
 		versions[version.SHA] = version
 	}
 
 	return
 }
 
+// getTagVersions gets the version info for each tag using a pool of workers that share the
+// token auth client. The results are in the same order as the tags. After an error no more
+// tags are started, so the first error in the results is always the one to report.
+func getTagVersions(t *registry.TokenAuthClient, imageName string, tags []string, concurrency int) []tagVersion {
+	results := make([]tagVersion, len(tags))
+	jobs := make(chan int)
+	stop := make(chan struct{})
+
+	var once sync.Once
+	var wg sync.WaitGroup
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	for w := 0; w < concurrency && w < len(tags); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range jobs {
+				results[n] = getTagVersion(t, imageName, tags[n])
+				if results[n].err != nil {
+					once.Do(func() { close(stop) })
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for n := range tags {
+		select {
+		case jobs <- n:
+		case <-stop:
+			break dispatch
+		}
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+func getTagVersion(t *registry.TokenAuthClient, imageName string, tagName string) (result tagVersion) {
+	manifest, manifestBytes, err := t.GetManifest(tagName)
+	if err != nil {
+		// Sometimes the registry returns not found for a tag eevn though that tag was included
+		// in the list of tags - presumably this is the registry in a bit of a bad state.
+		// We don't want to fail the whole image in this situation.
+		err = fmt.Errorf("Error getting manifest for tag %s: %v", tagName, err)
+		if !strings.Contains(err.Error(), "404") {
+			result.err = err
+		}
+		return
+	}
+
+	if manifest.IsList() {
+		// Multi-architecture images have a manifest for each platform
+		result.version, result.platforms, result.platformVersions, err = getPlatformVersions(t, imageName, manifest)
+	} else {
+		result.version, err = getImageVersion(t, imageName, manifest, manifestBytes)
+	}
+
+	if err != nil {
+		log.Errorf("Error getting version info for %s tag %s: %v", imageName, tagName, err)
+		result.err = err
+		return
+	}
+
+	result.found = true
+	return
+}
+
+// getImageVersion gets the version info for a single platform manifest of any schema.
+func getImageVersion(t *registry.TokenAuthClient, imageName string, m registry.Manifest, manifestBytes []byte) (version database.ImageVersion, err error) {
+	if m.HasConfig() {
+		// Schema 2 and OCI manifests keep the labels and history in a separate config blob
+		var config registry.ImageConfig
+		config, err = t.GetImageConfig(m)
+		if err != nil {
+			err = fmt.Errorf("Error getting config: %v", err)
+			return
+		}
+
+		version, err = getVersionInfoFromConfig(imageName, m, config)
+		if err != nil {
+			return
+		}
+
+		// The layer sizes are in the manifest, so we can work out the layers now rather than
+		// getting the config again during size inspection
+		var layerSizes []int64
+		version.DownloadSize, layerSizes, err = t.GetImageDownloadSize(m)
+		if err == nil {
+			err = getLayerInfoFromConfig(&version, m, config, layerSizes)
+		}
+		if err != nil {
+			err = fmt.Errorf("Error getting layers: %v", err)
+			return
+		}
+	} else {
+		version, err = getVersionInfo(m)
+	}
+
+	version.Manifest = string(manifestBytes)
+	return
+}
+
+// getPlatformVersions gets a version for each platform in a manifest list or OCI index. The
+// versions are returned without tags, and the default platform is also returned so the
+// tag can refer to it. This is linux/amd64 if it's available, otherwise the first platform.
+func getPlatformVersions(t *registry.TokenAuthClient, imageName string, list registry.Manifest) (version database.ImageVersion, platforms []database.Platform, versions []database.ImageVersion, err error) {
+	for _, d := range list.Manifests {
+		// Skip build attestations and anything else that isn't an image
+		if d.Platform == nil || d.Platform.OS == "unknown" {
+			continue
+		}
+
+		m, manifestBytes, err := t.GetManifest(d.Digest)
+		if err != nil {
+			return version, platforms, versions, fmt.Errorf("Error getting manifest for platform %s/%s: %v", d.Platform.OS, d.Platform.Architecture, err)
+		}
+
+		pv, err := getImageVersion(t, imageName, m, manifestBytes)
+		if err != nil {
+			return version, platforms, versions, fmt.Errorf("Error getting version for platform %s/%s: %v", d.Platform.OS, d.Platform.Architecture, err)
+		}
+
+		// The platform in the list is more reliable than the config
+		pv.OS = d.Platform.OS
+		pv.Architecture = d.Platform.Architecture
+		pv.Variant = d.Platform.Variant
+
+		versions = append(versions, pv)
+
+		platforms = append(platforms, database.Platform{
+			ImageName:    imageName,
+			SHA:          pv.SHA,
+			OS:           pv.OS,
+			Architecture: pv.Architecture,
+			Variant:      pv.Variant,
+		})
+
+		if version.SHA == "" || (isDefaultPlatform(pv) && !isDefaultPlatform(version)) {
+			version = pv
+		}
+	}
+
+	if version.SHA == "" {
+		err = fmt.Errorf("No platforms found in manifest list")
+	}
+
+	return
+}
+
+func isDefaultPlatform(v database.ImageVersion) bool {
+	return v.OS == "linux" && v.Architecture == "amd64"
+}
+
 func getVersionInfo(m registry.Manifest) (version database.ImageVersion, err error) {
 
 	var v1c registry.V1Compatibility
@@ -98,12 +274,44 @@ func getVersionInfo(m registry.Manifest) (version database.ImageVersion, err err
 	}
 
 	version = database.ImageVersion{
-		SHA:        v1c.Id,
-		ImageName:  m.Name,
-		Author:     v1c.Author,
-		Labels:     string(v1c.Config.Labels),
-		Created:    created,
-		LayerCount: len(m.FsLayers),
+		SHA:          v1c.Id,
+		ImageName:    m.Name,
+		Author:       v1c.Author,
+		Labels:       string(v1c.Config.Labels),
+		Created:      created,
+		LayerCount:   len(m.FsLayers),
+		OS:           v1c.OS,
+		Architecture: v1c.Architecture,
+	}
+
+	return
+}
+
+// getVersionInfoFromConfig is the equivalent of getVersionInfo for schema 2 and OCI manifests.
+func getVersionInfoFromConfig(imageName string, m registry.Manifest, config registry.ImageConfig) (version database.ImageVersion, err error) {
+	created, parseErr := time.Parse(time.RFC3339Nano, config.Created)
+	if parseErr != nil {
+		log.Infof("Couldn't get created time for %s from string %s", imageName, config.Created)
+	}
+
+	// Empty layers are included in the history but not in the manifest, so the
+	// history length matches the layer count we get for schema 1 images.
+	layerCount := len(config.History)
+	if layerCount == 0 {
+		layerCount = len(m.Layers)
+	}
+
+	// The config digest is the image ID
+	version = database.ImageVersion{
+		SHA:          strings.TrimPrefix(m.Config.Digest, "sha256:"),
+		ImageName:    imageName,
+		Author:       config.Author,
+		Labels:       string(config.Config.Labels),
+		Created:      created,
+		LayerCount:   layerCount,
+		OS:           config.OS,
+		Architecture: config.Architecture,
+		Variant:      config.Variant,
 	}
 
 	return
@@ -138,6 +346,32 @@ func formatHistory(input []string) (cmd string) {
 	return
 }
 
+// Dockerfile directives that BuildKit includes at the start of the created_by history field.
+var dockerfileDirectives = []string{
+	"ADD", "ARG", "CMD", "COPY", "ENTRYPOINT", "ENV", "EXPOSE", "HEALTHCHECK", "LABEL",
+	"MAINTAINER", "ONBUILD", "SHELL", "STOPSIGNAL", "USER", "VOLUME", "WORKDIR",
+}
+
+// formatCreatedBy takes the created_by field from the history in an image config
+// and makes it human readable in the same way as formatHistory.
+func formatCreatedBy(createdBy string) string {
+	cmd := strings.TrimSpace(createdBy)
+
+	// BuildKit marks its history entries and includes the directive
+	cmd = strings.TrimSpace(strings.TrimSuffix(cmd, "# buildkit"))
+	if strings.HasPrefix(cmd, "RUN ") {
+		cmd = strings.TrimPrefix(cmd, "RUN ")
+	} else {
+		for _, d := range dockerfileDirectives {
+			if strings.HasPrefix(cmd, d+" ") {
+				return cmd
+			}
+		}
+	}
+
+	return formatHistory([]string{cmd})
+}
+
 func updateLatest(img *database.Image) {
 	// See if there's a tag marked 'latest'; if not, find the most recent image
 	var mostRecent time.Time
@@ -152,7 +386,8 @@ func updateLatest(img *database.Image) {
 			}
 		}
 
-		if v.Created.After(mostRecent) {
+		// Versions for other platforms of a multi-architecture tag don't have tags
+		if len(v.Tags) > 0 && v.Created.After(mostRecent) {
 			mostRecent = v.Created
 			img.Latest = v.SHA
 		}
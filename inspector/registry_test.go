@@ -0,0 +1,330 @@
+package inspector
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/microscaling/microbadger/database"
+	"github.com/microscaling/microbadger/registry"
+)
+
+func getTestManifestV2() (registry.Manifest, registry.ImageConfig) {
+	m := registry.Manifest{
+		SchemaVersion: 2,
+		MediaType:     registry.MediaTypeManifestV2,
+		Config:        &registry.Descriptor{Digest: "sha256:abc123", Size: 1000},
+		Layers: []registry.Descriptor{
+			{Digest: "sha256:layer1", Size: 2000},
+			{Digest: "sha256:layer2", Size: 300},
+		},
+	}
+
+	c := registry.ImageConfig{
+		Created: "2020-03-23T21:19:34.196162891Z",
+		Author:  "liz@lizrice.com",
+		History: []registry.ConfigHistory{
+			{CreatedBy: "/bin/sh -c #(nop) ADD file:0c4555f363c2 in / "},
+			{CreatedBy: "/bin/sh -c #(nop)  LABEL org.label-schema.vcs-ref=12345", EmptyLayer: true},
+			{CreatedBy: "RUN /bin/sh -c apk add --no-cache git # buildkit"},
+		},
+	}
+	c.Config.Labels = json.RawMessage(`{"org.label-schema.vcs-ref":"12345"}`)
+
+	return m, c
+}
+
+func TestGetVersionInfoFromConfig(t *testing.T) {
+	m, c := getTestManifestV2()
+
+	v, err := getVersionInfoFromConfig("lizrice/imagetest", m, c)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if v.SHA != "abc123" || v.ImageName != "lizrice/imagetest" || v.Author != "liz@lizrice.com" {
+		t.Errorf("Unexpected version %v", v)
+	}
+
+	if v.LayerCount != 3 {
+		t.Errorf("Unexpected layer count %d", v.LayerCount)
+	}
+
+	if v.Labels != `{"org.label-schema.vcs-ref":"12345"}` {
+		t.Errorf("Unexpected labels %s", v.Labels)
+	}
+
+	if v.Created.Year() != 2020 {
+		t.Errorf("Unexpected created time %v", v.Created)
+	}
+}
+
+func TestGetLayerInfoFromConfig(t *testing.T) {
+	var layers []database.ImageLayer
+	var v database.ImageVersion
+
+	m, c := getTestManifestV2()
+
+	err := getLayerInfoFromConfig(&v, m, c, []int64{2000, 300})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	err = json.Unmarshal([]byte(v.Layers), &layers)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal layers %v", err)
+	}
+
+	expected := []database.ImageLayer{
+		{BlobSum: "sha256:layer1", Command: "ADD file:0c4555f363c2 in /", DownloadSize: 2000},
+		{Command: "LABEL org.label-schema.vcs-ref=12345"},
+		{BlobSum: "sha256:layer2", Command: "RUN apk add --no-cache git", DownloadSize: 300},
+	}
+
+	if len(layers) != len(expected) {
+		t.Fatalf("Unexpected layers %v", layers)
+	}
+
+	for i := range expected {
+		if layers[i] != expected[i] {
+			t.Errorf("#%d expected %v got %v", i, expected[i], layers[i])
+		}
+	}
+
+	if v.Hash != GetHashFromLayers(layers) {
+		t.Errorf("Unexpected hash %s", v.Hash)
+	}
+
+	// More layers in the history than in the manifest
+	m.Layers = m.Layers[:1]
+	err = getLayerInfoFromConfig(&v, m, c, []int64{2000})
+	if err == nil {
+		t.Errorf("Expected error for mismatched history")
+	}
+}
+
+func TestFormatCreatedBy(t *testing.T) {
+	tests := map[string]string{
+		`/bin/sh -c #(nop)  CMD ["/bin/sh"]`:       `CMD ["/bin/sh"]`,
+		`/bin/sh -c apk add --no-cache git`:        `RUN apk add --no-cache git`,
+		`RUN /bin/sh -c go build ./... # buildkit`: `RUN go build ./...`,
+		`COPY /src /app # buildkit`:                `COPY /src /app`,
+		`WORKDIR /app`:                             `WORKDIR /app`,
+		``:                                         ``,
+	}
+
+	for input, expected := range tests {
+		if cmd := formatCreatedBy(input); cmd != expected {
+			t.Errorf("Expected %s got %s", expected, cmd)
+		}
+	}
+}
+
+func TestGetVersionsFromManifestList(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.String() {
+		case "http://fakeauth/token?service=fakeservice&scope=repository:lizrice/multiarch:pull":
+			fmt.Fprintln(w, `{"token": "abctokenabc"}`)
+		case "http://fakereg/v2/lizrice/multiarch/tags/list":
+			fmt.Fprintln(w, `{"name": "lizrice/multiarch", "tags": ["latest", "single"]}`)
+		case "http://fakereg/v2/lizrice/multiarch/manifests/latest":
+			w.Header().Set("Content-Type", registry.MediaTypeOCIIndex)
+			fmt.Fprintln(w, `{"schemaVersion": 2, "manifests": [
+				{"digest": "sha256:arm64manifest", "size": 500, "platform": {"architecture": "arm64", "os": "linux", "variant": "v8"}},
+				{"digest": "sha256:amd64manifest", "size": 500, "platform": {"architecture": "amd64", "os": "linux"}},
+				{"digest": "sha256:attestation", "size": 500, "platform": {"architecture": "unknown", "os": "unknown"}}]}`)
+		case "http://fakereg/v2/lizrice/multiarch/manifests/sha256:arm64manifest":
+			fmt.Fprintln(w, `{"schemaVersion": 2, "mediaType": "application/vnd.oci.image.manifest.v1+json",
+				"config": {"digest": "sha256:arm64config", "size": 100}, "layers": [{"digest": "sha256:arm64layer", "size": 1000}]}`)
+		case "http://fakereg/v2/lizrice/multiarch/manifests/sha256:amd64manifest", "http://fakereg/v2/lizrice/multiarch/manifests/single":
+			fmt.Fprintln(w, `{"schemaVersion": 2, "mediaType": "application/vnd.oci.image.manifest.v1+json",
+				"config": {"digest": "sha256:amd64config", "size": 100}, "layers": [{"digest": "sha256:amd64layer", "size": 1000}]}`)
+		case "http://fakereg/v2/lizrice/multiarch/blobs/sha256:arm64config":
+			fmt.Fprintln(w, `{"architecture": "arm64", "os": "linux", "created": "2020-03-23T21:19:34Z", "history": [{"created_by": "/bin/sh -c #(nop) ADD file:arm in / "}]}`)
+		case "http://fakereg/v2/lizrice/multiarch/blobs/sha256:amd64config":
+			fmt.Fprintln(w, `{"architecture": "amd64", "os": "linux", "created": "2020-03-23T21:19:34Z", "history": [{"created_by": "/bin/sh -c #(nop) ADD file:amd in / "}]}`)
+		default:
+			t.Errorf("Unexpected request to %s", r.URL.String())
+		}
+	}))
+	defer server.Close()
+
+	// Make a transport that reroutes all traffic to the example server
+	transport := &http.Transport{
+		Proxy: func(req *http.Request) (*url.URL, error) {
+			return url.Parse(server.URL)
+		},
+	}
+
+	rs := registry.NewMockService(transport, "http://fakeauth", "http://fakereg", "fakeservice")
+	versions, err := getVersionsFromRegistry(registry.Image{Name: "lizrice/multiarch"}, &rs)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if len(versions) != 2 {
+		t.Fatalf("Expected 2 versions, got %d", len(versions))
+	}
+
+	// The tags both refer to the amd64 version
+	amd64 := versions["amd64config"]
+	if amd64.Architecture != "amd64" || len(amd64.Tags) != 2 {
+		t.Fatalf("Unexpected amd64 version %v", amd64)
+	}
+
+	// Only the multi-architecture tag has platforms
+	if len(amd64.Platforms) != 2 {
+		t.Fatalf("Unexpected platforms %v", amd64.Platforms)
+	}
+
+	if p := amd64.Platforms[0]; p.Tag != "latest" || p.String() != "linux/arm64/v8" || p.SHA != "arm64config" {
+		t.Errorf("Unexpected platform %v", p)
+	}
+
+	if p := amd64.Platforms[1]; p.Tag != "latest" || p.String() != "linux/amd64" || p.SHA != "amd64config" {
+		t.Errorf("Unexpected platform %v", p)
+	}
+
+	// Other platforms are stored without tags
+	arm64 := versions["arm64config"]
+	if arm64.Variant != "v8" || len(arm64.Tags) != 0 || arm64.Manifest == "" {
+		t.Errorf("Unexpected arm64 version %v", arm64)
+	}
+
+	// Layers and sizes come from the config we already have, so size inspection doesn't need it again
+	var layers []database.ImageLayer
+	err = json.Unmarshal([]byte(arm64.Layers), &layers)
+	if err != nil {
+		t.Fatalf("Error unmarshalling layers %s: %v", arm64.Layers, err)
+	}
+
+	if len(layers) != 1 || layers[0].BlobSum != "sha256:arm64layer" || layers[0].DownloadSize != 1000 {
+		t.Errorf("Unexpected arm64 layers %v", layers)
+	}
+
+	if arm64.DownloadSize != 1000 || arm64.Hash != GetHashFromLayers(layers) {
+		t.Errorf("Unexpected arm64 size %d and hash %s", arm64.DownloadSize, arm64.Hash)
+	}
+}
+
+func TestGetVersionsConcurrently(t *testing.T) {
+	var inFlight, maxInFlight int32
+
+	tags := make([]string, 20)
+	for n := range tags {
+		tags[n] = fmt.Sprintf("tag%02d", n)
+	}
+	tags[5] = "gone"
+
+	tagsJSON, _ := json.Marshal(tags)
+
+	tags[12] = "broken"
+	brokenJSON, _ := json.Marshal(tags)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.String()
+		switch {
+		case path == "http://fakeauth/token?service=fakeservice&scope=repository:lizrice/manytags:pull":
+			fmt.Fprintln(w, `{"token": "abctokenabc"}`)
+		case path == "http://fakereg/v2/lizrice/manytags/tags/list":
+			fmt.Fprintf(w, `{"name": "lizrice/manytags", "tags": %s}`, tagsJSON)
+		case path == "http://fakeauth/token?service=fakeservice&scope=repository:lizrice/brokentags:pull":
+			fmt.Fprintln(w, `{"token": "abctokenabc"}`)
+		case path == "http://fakereg/v2/lizrice/brokentags/tags/list":
+			fmt.Fprintf(w, `{"name": "lizrice/brokentags", "tags": %s}`, brokenJSON)
+		case path == "http://fakereg/v2/lizrice/manytags/manifests/gone", path == "http://fakereg/v2/lizrice/brokentags/manifests/gone":
+			w.WriteHeader(http.StatusNotFound)
+		case path == "http://fakereg/v2/lizrice/brokentags/manifests/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		case strings.Contains(path, "/manifests/"):
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				m := atomic.LoadInt32(&maxInFlight)
+				if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+					break
+				}
+			}
+
+			// Pairs of tags share a version
+			var tagNum int
+			fmt.Sscanf(path[strings.Index(path, "/manifests/tag")+len("/manifests/tag"):], "%d", &tagNum)
+			config := fmt.Sprintf("config%d", tagNum/2)
+
+			time.Sleep(20 * time.Millisecond)
+			fmt.Fprintf(w, `{"schemaVersion": 2, "mediaType": "application/vnd.oci.image.manifest.v1+json",
+				"config": {"digest": "sha256:%s", "size": 100}, "layers": [{"digest": "sha256:layer", "size": 1000}]}`, config)
+		case strings.Contains(path, "/blobs/"):
+			fmt.Fprintln(w, `{"architecture": "amd64", "os": "linux", "created": "2020-03-23T21:19:34Z", "history": [{"created_by": "/bin/sh -c #(nop) ADD file:amd in / "}]}`)
+		default:
+			t.Errorf("Unexpected request to %s", path)
+		}
+	}))
+	defer server.Close()
+
+	transport := &http.Transport{
+		Proxy: func(req *http.Request) (*url.URL, error) {
+			return url.Parse(server.URL)
+		},
+	}
+
+	rs := registry.NewMockService(transport, "http://fakeauth", "http://fakereg", "fakeservice")
+	defer func(c int) { registryConcurrency = c }(registryConcurrency)
+
+	getVersions := func(concurrency int) (map[string]database.ImageVersion, int) {
+		registryConcurrency = concurrency
+		atomic.StoreInt32(&maxInFlight, 0)
+
+		versions, err := getVersionsFromRegistry(registry.Image{Name: "lizrice/manytags"}, &rs)
+		if err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+
+		n := int(atomic.LoadInt32(&maxInFlight))
+		if n > concurrency {
+			t.Errorf("Expected at most %d requests in flight, got %d", concurrency, n)
+		}
+
+		return versions, n
+	}
+
+	serial, _ := getVersions(1)
+	parallel, parallelInFlight := getVersions(10)
+
+	// The missing tag is skipped
+	if len(serial) != 10 {
+		t.Errorf("Expected 10 versions, got %d", len(serial))
+	}
+
+	if !reflect.DeepEqual(serial, parallel) {
+		t.Errorf("Parallel versions don't match\n%v\n%v", serial, parallel)
+	}
+
+	// Tags are in the same order as the registry list
+	if v := parallel["config0"]; len(v.Tags) != 2 || v.Tags[0].Tag != "tag00" || v.Tags[1].Tag != "tag01" {
+		t.Errorf("Unexpected tags %v", v.Tags)
+	}
+
+	if v := parallel["config2"]; len(v.Tags) != 1 || v.Tags[0].Tag != "tag04" {
+		t.Errorf("Unexpected tags %v", v.Tags)
+	}
+
+	if parallelInFlight < 2 {
+		t.Errorf("Expected requests in parallel, got at most %d in flight", parallelInFlight)
+	}
+
+	// Other errors fail the whole image
+	registryConcurrency = 4
+
+	_, err := getVersionsFromRegistry(registry.Image{Name: "lizrice/brokentags"}, &rs)
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("Expected error for broken tag, got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package inspector
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/microscaling/microbadger/database"
+)
+
+const (
+	constDefaultMaxAttempts   = 5
+	constDefaultRetryDelay    = time.Minute
+	constDefaultMaxRetryDelay = 6 * time.Hour
+)
+
+// retryPolicy decides when to inspect an image again after it fails, and when to give up
+type retryPolicy struct {
+	maxAttempts int
+	delay       time.Duration
+	maxDelay    time.Duration
+}
+
+var inspectRetries = retryPolicy{
+	maxAttempts: constDefaultMaxAttempts,
+	delay:       constDefaultRetryDelay,
+	maxDelay:    constDefaultMaxRetryDelay,
+}
+
+// backoff doubles the delay after each failed attempt, up to the max delay
+func (p retryPolicy) backoff(attempts int) time.Duration {
+	d := p.delay
+	for i := 1; i < attempts && d < p.maxDelay; i++ {
+		d *= 2
+	}
+
+	if d > p.maxDelay {
+		d = p.maxDelay
+	}
+
+	return d
+}
+
+// InspectionFailed counts a failed inspection of the image. After too many failures the image
+// is dead-lettered, otherwise this returns how long to wait before trying again. If the
+// failures can't be read this returns an error, so that the count isn't lost.
+func InspectionFailed(db *database.PgDB, imageName string, inspectErr error) (deadLettered bool, retryDelay time.Duration, err error) {
+	f, err := db.GetInspectionFailure(imageName)
+	if err == gorm.ErrRecordNotFound {
+		f = database.InspectionFailure{ImageName: imageName}
+	} else if err != nil {
+		return false, 0, err
+	}
+
+	f.Attempts++
+	f.LastError = inspectErr.Error()
+
+	if f.Attempts >= inspectRetries.maxAttempts {
+		log.Errorf("Image %s failed inspection %d times, dead-lettering it: %v", imageName, f.Attempts, inspectErr)
+		return true, 0, db.DeadLetterImage(f)
+	}
+
+	retryDelay = inspectRetries.backoff(f.Attempts)
+	f.NextAttemptAt = time.Now().Add(retryDelay)
+	log.Infof("Image %s failed inspection %d times, retrying in %v", imageName, f.Attempts, retryDelay)
+	return false, retryDelay, db.PutInspectionFailure(f)
+}
+
+// InspectionSucceeded clears the failures for the image
+func InspectionSucceeded(db *database.PgDB, imageName string) error {
+	return db.DeleteInspectionFailure(imageName)
+}
@@ -0,0 +1,46 @@
+// +build dbrequired
+
+package inspector
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/microscaling/microbadger/database"
+)
+
+// $ psql -c 'create database microbadger_inspector_test;' -U postgres
+func getRetryDatabase(t *testing.T) database.PgDB {
+	db, err := database.GetPostgres("localhost", "postgres", "microbadger_inspector_test", "", false)
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+
+	return db
+}
+
+func TestInspectionFailed(t *testing.T) {
+	db := getRetryDatabase(t)
+	db.Exec("DELETE FROM inspection_failures")
+	inspectErr := errors.New("manifest unknown")
+
+	for attempt := 1; attempt < inspectRetries.maxAttempts; attempt++ {
+		deadLettered, delay, err := InspectionFailed(&db, "lizrice/featured", inspectErr)
+		if err != nil || deadLettered || delay != inspectRetries.backoff(attempt) {
+			t.Fatalf("Attempt %d: unexpected dead-lettered %t, delay %v, error %v", attempt, deadLettered, delay, err)
+		}
+	}
+
+	// If the failures can't be read they aren't reset
+	broken := getRetryDatabase(t)
+	broken.SQL().Close()
+	_, _, err := InspectionFailed(&broken, "lizrice/featured", inspectErr)
+	if err == nil {
+		t.Errorf("Expected an error with no database")
+	}
+
+	deadLettered, _, err := InspectionFailed(&db, "lizrice/featured", inspectErr)
+	if err != nil || !deadLettered {
+		t.Errorf("Expected the image to be dead-lettered, error %v", err)
+	}
+}
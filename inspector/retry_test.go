@@ -0,0 +1,28 @@
+package inspector
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	p := retryPolicy{maxAttempts: 5, delay: time.Minute, maxDelay: 10 * time.Minute}
+
+	tests := []struct {
+		attempts int
+		delay    time.Duration
+	}{
+		{attempts: 1, delay: time.Minute},
+		{attempts: 2, delay: 2 * time.Minute},
+		{attempts: 3, delay: 4 * time.Minute},
+		{attempts: 4, delay: 8 * time.Minute},
+		{attempts: 5, delay: 10 * time.Minute},
+		{attempts: 100, delay: 10 * time.Minute},
+	}
+
+	for _, test := range tests {
+		if d := p.backoff(test.attempts); d != test.delay {
+			t.Errorf("Attempt %d: expected delay %v, got %v", test.attempts, test.delay, d)
+		}
+	}
+}
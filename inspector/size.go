@@ -2,8 +2,8 @@ package inspector
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/microscaling/microbadger/database"
 	"github.com/microscaling/microbadger/encryption"
@@ -13,7 +13,6 @@ import (
 // InspectSize inspects the size of an image
 func InspectSize(imgName string, db *database.PgDB, rs *registry.Service, es encryption.Service) (err error) {
 	log.Debugf("Inspecting size of %s", imgName)
-	var m registry.Manifest
 
 	img, err := db.GetImage(imgName)
 	if err != nil || img.Status == "MISSING" {
@@ -44,21 +43,22 @@ func InspectSize(imgName string, db *database.PgDB, rs *registry.Service, es enc
 
 	log.Debugf("Image is %v and has %d versions", img, len(versions))
 	for _, iv := range versions {
-		// No need to get the image size again if we already have it stored in a database
-		if db.ImageVersionNeedsSizeOrLayers(&iv) {
-
-			// Manifest is stored as a string in the database
-			err = json.Unmarshal([]byte(iv.Manifest), &m)
-			if err != nil {
-				err = fmt.Errorf("Error unmarshalling manifest for %s: %v", img.Name, err)
-				return err
-			}
+		// Manifest is stored as a string in the database
+		var m registry.Manifest
+		err = json.Unmarshal([]byte(iv.Manifest), &m)
+		if err != nil {
+			err = fmt.Errorf("Error unmarshalling manifest for %s: %v", img.Name, err)
+			return err
+		}
 
+		// No need to get the image size again if we already have it stored in a database. Versions
+		// with a config have their sizes and layers worked out when they're inspected.
+		if db.ImageVersionNeedsSizeOrLayers(&iv) {
 			// Get the total download size and the sizes of each individual layer.
 			downloadSize, layerSizes, err := t.GetImageDownloadSize(m)
 			if err != nil {
 				log.Infof("Couldn't get download size for %s: %v", img.Name, err)
-				if strings.Contains(err.Error(), "Rate limited") {
+				if errors.Is(err, registry.ErrRateLimited) {
 					// No point immediately trying to get other versions if we're rate limited
 					break
 				}
@@ -69,23 +69,45 @@ func InspectSize(imgName string, db *database.PgDB, rs *registry.Service, es enc
 
 			iv.DownloadSize = downloadSize
 
-			// Check we have the layer sizes and that they match the history length.
-			// Otherwise wait until the next time the size data is inspected.
-			if layerSizes != nil && len(layerSizes) == len(m.History) {
+			if m.HasConfig() {
+				// The history is in the config blob for schema 2 and OCI manifests. We only need
+				// to get it here for versions stored before inspection worked out their layers.
+				config, err := t.GetImageConfig(m)
+				if err != nil {
+					log.Infof("Couldn't get config for %s: %v", img.Name, err)
+					if errors.Is(err, registry.ErrRateLimited) {
+						break
+					}
+					continue
+				}
+
+				err = getLayerInfoFromConfig(&iv, m, config, layerSizes)
+				if err != nil {
+					err = fmt.Errorf("Error getting layer info from config for %s: %v", img.Name, err)
+					return err
+				}
+			} else if layerSizes != nil && len(layerSizes) == len(m.History) {
+				// Check we have the layer sizes and that they match the history length.
+				// Otherwise wait until the next time the size data is inspected.
 				err = getLayerInfoFromManifest(&iv, m, layerSizes)
 				if err != nil {
 					err = fmt.Errorf("Error getting layer info from manifest for %s: %v", img.Name, err)
 					return err
 				}
 			}
+		}
 
-			// We have got all the information we could need from this manifest string, so we can
-			// delete it and free up some space in the database
-			iv.Manifest = ""
-
-			log.Debugf("Updating image %s version %s with size %d", iv.ImageName, iv.SHA, iv.DownloadSize)
-			db.PutImageVersion(iv)
+		// Optionally download the layers to see what's in them
+		if inspectLayerContents && iv.Layers != "" {
+			addLayerContents(t, db, &iv)
 		}
+
+		// We have got all the information we could need from this manifest string, so we can
+		// delete it and free up some space in the database
+		iv.Manifest = ""
+
+		log.Debugf("Updating image %s version %s with size %d", iv.ImageName, iv.SHA, iv.DownloadSize)
+		db.PutImageVersion(iv)
 	}
 
 	// Don't move this image to inspected if we got rate-limited. It should stay in SIZE state so we'll
@@ -128,6 +150,48 @@ func getLayerInfoFromManifest(v *database.ImageVersion, m registry.Manifest, lay
 		layers[i], layers[j] = layers[j], layers[i]
 	}
 
+	return setVersionLayers(v, layers)
+}
+
+// getLayerInfoFromConfig builds the layers for schema 2 and OCI manifests. The config history
+// is already in human readable order and includes empty layers that aren't in the manifest.
+func getLayerInfoFromConfig(v *database.ImageVersion, m registry.Manifest, config registry.ImageConfig, layerSizes []int64) (err error) {
+	layers := make([]database.ImageLayer, 0, len(config.History))
+
+	blob := 0
+	for _, h := range config.History {
+		layer := database.ImageLayer{
+			Command: formatCreatedBy(h.CreatedBy),
+		}
+
+		if !h.EmptyLayer {
+			if blob >= len(m.Layers) {
+				return fmt.Errorf("History has more layers than the manifest")
+			}
+
+			layer.BlobSum = m.Layers[blob].Digest
+			layer.DownloadSize = layerSizes[blob]
+			blob++
+		}
+
+		layers = append(layers, layer)
+	}
+
+	// History is optional so fall back to just the blobs
+	if len(config.History) == 0 {
+		for i, l := range m.Layers {
+			layers = append(layers, database.ImageLayer{
+				BlobSum:      l.Digest,
+				DownloadSize: layerSizes[i],
+			})
+		}
+	}
+
+	return setVersionLayers(v, layers)
+}
+
+// setVersionLayers hashes the layers and serializes them onto the version.
+func setVersionLayers(v *database.ImageVersion, layers []database.ImageLayer) (err error) {
 	// Hash the layers together to identify this image
 	v.Hash = GetHashFromLayers(layers)
 	log.Debugf("Got hash %s", v.Hash)
@@ -135,7 +199,7 @@ func getLayerInfoFromManifest(v *database.ImageVersion, m registry.Manifest, lay
 	// Serialize the layers as JSON for storage.
 	layersJSON, err := json.Marshal(layers)
 	if err != nil {
-		log.Infof("Couldn't convert layers for %s to string: %v", m.Name, err)
+		log.Infof("Couldn't convert layers for %s to string: %v", v.ImageName, err)
 	}
 
 	v.Layers = string(layersJSON)
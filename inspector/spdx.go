@@ -0,0 +1,229 @@
+package inspector
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/microscaling/microbadger/database"
+)
+
+//go:generate go run gen_licenses.go
+
+const constSPDXURL = "https://spdx.org/licenses/"
+
+// spdxLicense is an entry in the SPDX license list
+type spdxLicense struct {
+	ID          string
+	Name        string
+	OSIApproved bool
+	Deprecated  bool
+}
+
+// licenseExpr is a node in the tree for an SPDX license expression. It's either an AND or OR
+// of two expressions, or a single license with an optional exception.
+type licenseExpr struct {
+	op          string
+	left, right *licenseExpr
+
+	license   string
+	orLater   bool
+	exception string
+}
+
+// leaves are the single licenses in the expression from left to right
+func (e *licenseExpr) leaves() []*licenseExpr {
+	if e.op == "" {
+		return []*licenseExpr{e}
+	}
+
+	return append(e.left.leaves(), e.right.leaves()...)
+}
+
+// String writes the expression with brackets around every compound expression
+func (e *licenseExpr) String() string {
+	if e.op != "" {
+		return "(" + e.left.String() + " " + e.op + " " + e.right.String() + ")"
+	}
+
+	s := e.license
+	if e.orLater {
+		s += "+"
+	}
+	if e.exception != "" {
+		s += " WITH " + e.exception
+	}
+
+	return s
+}
+
+// parseLicenseExpression parses an SPDX license expression such as "MIT OR (Apache-2.0 AND
+// GPL-2.0+ WITH Classpath-exception-2.0)". WITH binds tightest, then AND, then OR. Operators
+// should be upper case but we accept any case, as people aren't always careful.
+func parseLicenseExpression(s string) (*licenseExpr, error) {
+	p := licenseParser{tokens: tokenizeLicense(s)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("Empty license expression")
+	}
+
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("Unexpected %s in license expression %s", p.tokens[p.pos], s)
+	}
+
+	return e, nil
+}
+
+func tokenizeLicense(s string) (tokens []string) {
+	s = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(s)
+	return strings.Fields(s)
+}
+
+type licenseParser struct {
+	tokens []string
+	pos    int
+}
+
+// next returns the next token, or an empty string at the end of the expression
+func (p *licenseParser) next() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+
+	return p.tokens[p.pos]
+}
+
+// accept moves past the next token if it's the operator
+func (p *licenseParser) accept(op string) bool {
+	if strings.ToUpper(p.next()) == op {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+func (p *licenseParser) parseOr() (*licenseExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &licenseExpr{op: "OR", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *licenseParser) parseAnd() (*licenseExpr, error) {
+	left, err := p.parseLicense()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept("AND") {
+		right, err := p.parseLicense()
+		if err != nil {
+			return nil, err
+		}
+		left = &licenseExpr{op: "AND", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseLicense parses a bracketed expression or a single license
+func (p *licenseParser) parseLicense() (*licenseExpr, error) {
+	if p.accept("(") {
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if !p.accept(")") {
+			return nil, fmt.Errorf("Missing ) in license expression")
+		}
+
+		return e, nil
+	}
+
+	id := p.next()
+	if !isLicenseID(id) {
+		return nil, fmt.Errorf("Expected a license but got %q", id)
+	}
+	p.pos++
+
+	e := &licenseExpr{license: id}
+	if strings.HasSuffix(id, "+") {
+		e.license = strings.TrimSuffix(id, "+")
+		e.orLater = true
+	}
+
+	if p.accept("WITH") {
+		exception := p.next()
+		if !isLicenseID(exception) || strings.HasSuffix(exception, "+") {
+			return nil, fmt.Errorf("Expected a license exception but got %q", exception)
+		}
+		p.pos++
+		e.exception = exception
+	}
+
+	return e, nil
+}
+
+// isLicenseID checks the token is made up of the characters allowed in license identifiers
+// and isn't an operator. LicenseRef and DocumentRef identifiers can also contain a colon.
+func isLicenseID(token string) bool {
+	switch strings.ToUpper(token) {
+	case "", "AND", "OR", "WITH", "(", ")":
+		return false
+	}
+
+	for _, c := range token {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune(".-+:", c)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// resolveLicenses looks up each license and exception in the expression in the SPDX license list
+func resolveLicenses(e *licenseExpr) []database.LicenseDetail {
+	leaves := e.leaves()
+	details := make([]database.LicenseDetail, len(leaves))
+
+	for i, leaf := range leaves {
+		d := database.LicenseDetail{Code: leaf.license}
+		if l, ok := spdxLicenses[strings.ToLower(leaf.license)]; ok {
+			d.Code = l.ID
+			d.Name = l.Name
+			d.URL = constSPDXURL + l.ID + ".html"
+			d.OSIApproved = l.OSIApproved
+		}
+
+		if leaf.orLater {
+			d.Code += "+"
+		}
+
+		if leaf.exception != "" {
+			d.Exception = leaf.exception
+			if id, ok := spdxExceptions[strings.ToLower(leaf.exception)]; ok {
+				d.Exception = id
+				d.ExceptionURL = constSPDXURL + id + ".html"
+			}
+		}
+
+		details[i] = d
+	}
+
+	return details
+}
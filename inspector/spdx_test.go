@@ -0,0 +1,89 @@
+package inspector
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/microscaling/microbadger/database"
+)
+
+func TestParseLicenseExpression(t *testing.T) {
+	var tests = []struct {
+		expr   string
+		result string
+		err    bool
+	}{
+		{expr: "MIT", result: "MIT"},
+		{expr: "Apache-2.0 OR MIT", result: "(Apache-2.0 OR MIT)"},
+		{expr: "GPL-2.0-only WITH Classpath-exception-2.0", result: "GPL-2.0-only WITH Classpath-exception-2.0"},
+		{expr: "MIT OR Apache-2.0 AND BSD-3-Clause", result: "(MIT OR (Apache-2.0 AND BSD-3-Clause))"},
+		{expr: "(MIT OR Apache-2.0) AND BSD-3-Clause", result: "((MIT OR Apache-2.0) AND BSD-3-Clause)"},
+		{expr: "LGPL-2.1+ with Classpath-exception-2.0 or MIT", result: "(LGPL-2.1+ WITH Classpath-exception-2.0 OR MIT)"},
+		{expr: "LicenseRef-proprietary AND DocumentRef-spdx:LicenseRef-1", result: "(LicenseRef-proprietary AND DocumentRef-spdx:LicenseRef-1)"},
+		{expr: "", err: true},
+		{expr: "Apache License 2.0", err: true},
+		{expr: "MIT OR", err: true},
+		{expr: "(MIT OR Apache-2.0", err: true},
+		{expr: "MIT WITH", err: true},
+		{expr: "MIT)", err: true},
+	}
+
+	for _, test := range tests {
+		e, err := parseLicenseExpression(test.expr)
+		if (err != nil) != test.err {
+			t.Errorf("Unexpected error for %q: %v", test.expr, err)
+		}
+
+		if err == nil && e.String() != test.result {
+			t.Errorf("%q parsed as %s, expected %s", test.expr, e.String(), test.result)
+		}
+	}
+}
+
+func TestParseLicense(t *testing.T) {
+	var tests = []struct {
+		label   string
+		license *database.License
+	}{
+		{label: "", license: nil},
+		{
+			label: "mit",
+			license: &database.License{Code: "mit", URL: "https://spdx.org/licenses/MIT.html", Licenses: []database.LicenseDetail{
+				{Code: "MIT", Name: "MIT License", URL: "https://spdx.org/licenses/MIT.html", OSIApproved: true},
+			}},
+		},
+		{
+			label: "Apache-2.0 OR MIT",
+			license: &database.License{Code: "Apache-2.0 OR MIT", Licenses: []database.LicenseDetail{
+				{Code: "Apache-2.0", Name: "Apache License 2.0", URL: "https://spdx.org/licenses/Apache-2.0.html", OSIApproved: true},
+				{Code: "MIT", Name: "MIT License", URL: "https://spdx.org/licenses/MIT.html", OSIApproved: true},
+			}},
+		},
+		{
+			label: "GPL-2.0-only WITH Classpath-exception-2.0",
+			license: &database.License{Code: "GPL-2.0-only WITH Classpath-exception-2.0", URL: "https://spdx.org/licenses/GPL-2.0-only.html", Licenses: []database.LicenseDetail{
+				{Code: "GPL-2.0-only", Name: "GNU General Public License v2.0 only", URL: "https://spdx.org/licenses/GPL-2.0-only.html", OSIApproved: true,
+					Exception: "Classpath-exception-2.0", ExceptionURL: "https://spdx.org/licenses/Classpath-exception-2.0.html"},
+			}},
+		},
+		{
+			label: "LicenseRef-ourco AND LGPL-2.1+",
+			license: &database.License{Code: "LicenseRef-ourco AND LGPL-2.1+", Licenses: []database.LicenseDetail{
+				{Code: "LicenseRef-ourco"},
+				{Code: "LGPL-2.1+", Name: "GNU Lesser General Public License v2.1 only", URL: "https://spdx.org/licenses/LGPL-2.1.html", OSIApproved: true},
+			}},
+		},
+		{label: "Apache License 2.0", license: &database.License{Code: "Apache License 2.0"}},
+	}
+
+	for _, test := range tests {
+		license := parseLicense(map[string]string{constOCILicenses: test.label})
+		if test.label == "" {
+			license = parseLicense(map[string]string{})
+		}
+
+		if !reflect.DeepEqual(license, test.license) {
+			t.Errorf("License %q parsed as %+v, expected %+v", test.label, license, test.license)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package inspector
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// vcsProvider builds links to a commit for a source code hosting service
+type vcsProvider struct {
+	name string
+
+	// Public hosts for the service. Self-hosted servers are recognized by name or configured.
+	hosts      []string
+	hostPrefix string
+
+	// commitURL links to the commit from the repository URL
+	commitURL func(repo *url.URL, commit string) string
+}
+
+var vcsProviders = []vcsProvider{
+	{
+		name:      "github",
+		hosts:     []string{"github.com"},
+		commitURL: appendCommitPath("tree"),
+	},
+	{
+		name:       "gitlab",
+		hosts:      []string{"gitlab.com"},
+		hostPrefix: "gitlab.",
+		commitURL:  appendCommitPath("-", "tree"),
+	},
+	{
+		name:      "bitbucket",
+		hosts:     []string{"bitbucket.org"},
+		commitURL: appendCommitPath("commits"),
+	},
+	{
+		name:       "gitea",
+		hosts:      []string{"gitea.com", "codeberg.org"},
+		hostPrefix: "gitea.",
+		commitURL:  appendCommitPath("src", "commit"),
+	},
+	{
+		name:      "azure",
+		hosts:     []string{"dev.azure.com", "ssh.dev.azure.com", "visualstudio.com"},
+		commitURL: azureCommitURL,
+	},
+}
+
+// vcsHosts maps our own servers to the provider they run, configured with MB_VCS_HOSTS
+var vcsHosts map[string]string
+
+// parseVCSHosts reads a list of host=provider pairs, e.g. git.example.com=gitlab,code.example.com=gitea
+func parseVCSHosts(config string) (hosts map[string]string, err error) {
+	hosts = make(map[string]string)
+	for _, entry := range strings.Split(config, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("Bad VCS host %s, expected host=provider", entry)
+		}
+
+		name := strings.ToLower(strings.TrimSpace(parts[1]))
+		if _, ok := getVCSProviderByName(name); !ok {
+			return nil, fmt.Errorf("Unknown VCS provider %s for host %s", parts[1], parts[0])
+		}
+
+		hosts[strings.ToLower(strings.TrimSpace(parts[0]))] = name
+	}
+
+	return hosts, nil
+}
+
+func getVCSProviderByName(name string) (vcsProvider, bool) {
+	for _, p := range vcsProviders {
+		if p.name == name {
+			return p, true
+		}
+	}
+
+	return vcsProvider{}, false
+}
+
+// getVCSProvider finds the provider for the host. Configured hosts come first, then the public
+// hosts, then self-hosted servers named after the software they run e.g. gitlab.example.com.
+func getVCSProvider(host string) (vcsProvider, bool) {
+	host = strings.ToLower(host)
+	if name, ok := vcsHosts[host]; ok {
+		return getVCSProviderByName(name)
+	}
+
+	for _, p := range vcsProviders {
+		for _, h := range p.hosts {
+			if host == h || strings.HasSuffix(host, "."+h) {
+				return p, true
+			}
+		}
+	}
+
+	for _, p := range vcsProviders {
+		if p.hostPrefix != "" && strings.HasPrefix(host, p.hostPrefix) {
+			return p, true
+		}
+	}
+
+	return vcsProvider{}, false
+}
+
+// normalizeRepoURL converts SSH and git:// repository addresses to web URLs
+// e.g. git@gitlab.com:org/repo.git becomes https://gitlab.com/org/repo
+func normalizeRepoURL(repo string) (*url.URL, error) {
+	repo = strings.TrimSpace(repo)
+
+	// scp style SSH addresses have a colon between the host and the path
+	if !strings.Contains(repo, "://") {
+		if at := strings.Index(repo, "@"); at >= 0 {
+			repo = repo[at+1:]
+		}
+		repo = "https://" + strings.Replace(repo, ":", "/", 1)
+	}
+
+	u, err := url.Parse(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Host == "" {
+		return nil, fmt.Errorf("No host in repository URL %s", repo)
+	}
+
+	switch strings.TrimPrefix(u.Scheme, "git+") {
+	case "ssh", "git":
+		// The SSH or git port isn't the port for the web server
+		u.Scheme = "https"
+		u.Host = u.Hostname()
+	case "https":
+		u.Scheme = "https"
+	case "http":
+		u.Scheme = "http"
+	default:
+		return nil, fmt.Errorf("Unsupported scheme %s in repository URL %s", u.Scheme, repo)
+	}
+
+	u.User = nil
+	u.Path = strings.TrimSuffix(strings.TrimSuffix(u.Path, "/"), ".git")
+	u.RawQuery = ""
+	u.Fragment = ""
+
+	return u, nil
+}
+
+// appendCommitPath links to the commit by adding the path elements and the commit
+// to the repository path
+func appendCommitPath(elements ...string) func(repo *url.URL, commit string) string {
+	return func(repo *url.URL, commit string) string {
+		u := *repo
+		u.Path = path.Join(append(append([]string{u.Path}, elements...), commit)...)
+		return u.String()
+	}
+}
+
+// azureCommitURL handles the SSH form of Azure DevOps repository addresses, which has a v3 prefix
+// and no _git element e.g. git@ssh.dev.azure.com:v3/org/project/repo
+func azureCommitURL(repo *url.URL, commit string) string {
+	u := *repo
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if u.Host == "ssh.dev.azure.com" && len(parts) == 4 && parts[0] == "v3" {
+		u.Host = "dev.azure.com"
+		parts = []string{parts[1], parts[2], "_git", parts[3]}
+	}
+
+	u.Path = "/" + path.Join(append(parts, "commit", commit)...)
+	return u.String()
+}
@@ -0,0 +1,68 @@
+package inspector
+
+import (
+	"testing"
+
+	"github.com/microscaling/microbadger/database"
+)
+
+func TestParseVCSURL(t *testing.T) {
+	vcsHosts = map[string]string{"git.ourco.internal": "gitlab"}
+	defer func() { vcsHosts = nil }()
+
+	var tests = []struct {
+		url      string
+		result   string
+		provider string
+	}{
+		{url: "https://github.com/microscaling/microbadger", result: "https://github.com/microscaling/microbadger/tree/12345", provider: "github"},
+		{url: "git://github.com/microscaling/microbadger.git", result: "https://github.com/microscaling/microbadger/tree/12345", provider: "github"},
+		{url: "https://gitlab.com/group/sub/project.git", result: "https://gitlab.com/group/sub/project/-/tree/12345", provider: "gitlab"},
+		{url: "git@gitlab.example.com:group/project.git", result: "https://gitlab.example.com/group/project/-/tree/12345", provider: "gitlab"},
+		{url: "ssh://git@git.ourco.internal:2222/team/project.git", result: "https://git.ourco.internal/team/project/-/tree/12345", provider: "gitlab"},
+		{url: "https://git.ourco.internal:8443/team/project.git", result: "https://git.ourco.internal:8443/team/project/-/tree/12345", provider: "gitlab"},
+		{url: "git://git.example.com:9418/org/repo.git", result: "https://git.example.com/org/repo"},
+		{url: "https://user@bitbucket.org/team/repo.git", result: "https://bitbucket.org/team/repo/commits/12345", provider: "bitbucket"},
+		{url: "git@codeberg.org:forgejo/forgejo.git", result: "https://codeberg.org/forgejo/forgejo/src/commit/12345", provider: "gitea"},
+		{url: "https://gitea.example.com/org/repo/", result: "https://gitea.example.com/org/repo/src/commit/12345", provider: "gitea"},
+		{url: "https://dev.azure.com/org/project/_git/repo", result: "https://dev.azure.com/org/project/_git/repo/commit/12345", provider: "azure"},
+		{url: "git@ssh.dev.azure.com:v3/org/project/repo", result: "https://dev.azure.com/org/project/_git/repo/commit/12345", provider: "azure"},
+		{url: "https://org.visualstudio.com/project/_git/repo", result: "https://org.visualstudio.com/project/_git/repo/commit/12345", provider: "azure"},
+		{url: "https://git.example.com/org/repo.git", result: "https://git.example.com/org/repo"},
+	}
+
+	for _, test := range tests {
+		vcs := parseVCSURL(&database.VersionControl{URL: test.url, Commit: "12345"})
+		if vcs == nil {
+			t.Errorf("No version control for %s", test.url)
+			continue
+		}
+
+		if vcs.URL != test.result || vcs.Provider != test.provider || vcs.Type != "git" {
+			t.Errorf("%s gave %s %s, expected %s %s", test.url, vcs.Provider, vcs.URL, test.provider, test.result)
+		}
+	}
+
+	for _, bad := range []string{"not a url", "ftp://example.com/repo", "/just/a/path"} {
+		if vcs := parseVCSURL(&database.VersionControl{URL: bad, Commit: "12345"}); vcs != nil {
+			t.Errorf("Expected no version control for %s, got %v", bad, vcs)
+		}
+	}
+}
+
+func TestParseVCSHosts(t *testing.T) {
+	hosts, err := parseVCSHosts("git.ourco.internal=gitlab, Code.OurCo.internal = Gitea")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(hosts) != 2 || hosts["git.ourco.internal"] != "gitlab" || hosts["code.ourco.internal"] != "gitea" {
+		t.Errorf("Unexpected hosts %v", hosts)
+	}
+
+	for _, bad := range []string{"git.ourco.internal", "git.ourco.internal=svn", "=gitlab"} {
+		if _, err := parseVCSHosts(bad); err == nil {
+			t.Errorf("Expected error for %s", bad)
+		}
+	}
+}
@@ -2,6 +2,10 @@ package main
 
 import (
 	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
 
 	"github.com/op/go-logging"
 
@@ -12,6 +16,7 @@ import (
 	"github.com/microscaling/microbadger/inspector"
 	"github.com/microscaling/microbadger/queue"
 	"github.com/microscaling/microbadger/registry"
+	"github.com/microscaling/microbadger/scheduler"
 	"github.com/microscaling/microbadger/utils"
 )
 
@@ -20,6 +25,7 @@ var (
 )
 
 const constPollQueueTimeout = 250 // milliseconds - how often to check the queue for images.
+const constDefaultWorkerCount = 4
 
 func init() {
 	utils.InitLogging()
@@ -32,17 +38,10 @@ func main() {
 
 	var image string
 	var db database.PgDB
-	var qs queue.Service
-
-	if os.Getenv("MB_QUEUE_TYPE") == "nats" {
-		qs = queue.NewNatsService()
-	} else {
-		qs = queue.NewSqsService()
-	}
 
 	cmd := utils.GetArgOrLogError("cmd", 1)
 
-	if cmd != "api" && cmd != "inspector" && cmd != "size" {
+	if cmd != "api" && cmd != "inspector" && cmd != "size" && cmd != "scheduler" && cmd != "all" {
 		image = utils.GetArgOrLogError("image", 2)
 	}
 
@@ -56,20 +55,55 @@ func main() {
 	case "api":
 		log.Info("starting microbadger api")
 		rs := registry.NewService()
+		addRegistries(db, &rs)
 		hs := hub.NewService()
 		es := encryption.NewService()
-		api.StartServer(db, qs, rs, hs, es)
+		api.StartServer(db, queue.NewService(db.SQL(), queue.TopicImage, ""), rs, hs, es, stopOnSignal())
 	case "inspector":
 		log.Info("starting inspector")
 		hs := hub.NewService()
 		rs := registry.NewService()
+		addRegistries(db, &rs)
 		es := encryption.NewService()
-		startInspector(db, qs, hs, rs, es)
+		startInspector(db, queue.NewService(db.SQL(), queue.TopicSize, queue.TopicImage), hs, rs, es, stopOnSignal())
 	case "size":
 		log.Info("starting size inspector")
 		rs := registry.NewService()
+		addRegistries(db, &rs)
+		es := encryption.NewService()
+		startSizeInspector(db, queue.NewService(db.SQL(), "", queue.TopicSize), rs, es, stopOnSignal())
+	case "scheduler":
+		log.Info("starting scheduler")
+		s := scheduler.NewScheduler(db, queue.NewService(db.SQL(), queue.TopicImage, ""))
+		s.Run(stopOnSignal())
+	case "all":
+		// Everything in one process, passing images between the inspectors on in-memory queues.
+		// The notifier is a separate process so notifications aren't sent when running like this.
+		log.Info("starting microbadger api and inspectors")
+		rs := registry.NewService()
+		addRegistries(db, &rs)
+		hs := hub.NewService()
 		es := encryption.NewService()
-		startSizeInspector(db, qs, rs, es)
+		mq := queue.NewMemoryService()
+		stop := stopOnSignal()
+
+		var wg sync.WaitGroup
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			startSizeInspector(db, queue.NewServiceAdapter(mq, "", queue.TopicSize), rs, es, stop)
+		}()
+		go func() {
+			defer wg.Done()
+			startInspector(db, queue.NewServiceAdapter(mq, queue.TopicSize, queue.TopicImage), hs, rs, es, stop)
+		}()
+		go func() {
+			defer wg.Done()
+			scheduler.NewScheduler(db, queue.NewServiceAdapter(mq, queue.TopicImage, "")).Run(stop)
+		}()
+
+		api.StartServer(db, queue.NewServiceAdapter(mq, queue.TopicImage, ""), rs, hs, es, stop)
+		wg.Wait()
 	case "feature":
 		log.Infof("Feature image %s", image)
 		err := db.FeatureImage(image, true)
@@ -87,39 +121,206 @@ func main() {
 	}
 }
 
-func startInspector(db database.PgDB, qs queue.Service, hs hub.InfoService, rs registry.Service, es encryption.Service) {
-	for {
-		img := qs.ReceiveImage()
-		if img != nil && img.ImageName != "" {
-			log.Infof("Received Image: %v", img.ImageName)
-			err := inspector.Inspect(img.ImageName, &db, &rs, &hs, qs, es)
-
-			// If we failed to inspect this item, it might well be because Docker Hub has behaved badly.
-			// By not deleting it, it will get resent again at some point in the future.
-			if err == nil {
-				qs.DeleteImage(img)
-
-				// Getting the size information can take a while so we do this asynchronously.
-				// We have different environment variables for deciding which queue to send & receive on.
-				qs.SendImage(img.ImageName, "Sent for size inspection")
-			} else {
-				log.Errorf("Failed to inspect %s", img.ImageName)
+// addRegistries configures the registry service with the other registries in the database.
+// Registries that are added to the database later are loaded when we first see their images.
+func addRegistries(db database.PgDB, rs *registry.Service) {
+	rs.SetRegistryLoader(func(host string) (registry.Registry, bool) {
+		r, err := db.GetRegistryByHost(host)
+		if err != nil || r.IsDockerHub() {
+			return registry.Registry{}, false
+		}
+
+		log.Infof("Loaded registry %s", r.Host)
+		return serviceRegistry(r), true
+	})
+
+	regs, err := db.GetRegistries()
+	if err != nil {
+		log.Errorf("Failed to get registries: %v", err)
+		return
+	}
+
+	for _, r := range regs {
+		if r.IsDockerHub() {
+			continue
+		}
+
+		log.Debugf("Adding registry %s", r.Host)
+		rs.AddRegistry(serviceRegistry(r))
+	}
+}
+
+func serviceRegistry(r database.Registry) registry.Registry {
+	return registry.Registry{
+		Host:    r.Host,
+		URL:     r.APIURL,
+		AuthURL: r.AuthURL,
+		Service: r.Service,
+	}
+}
+
+func startInspector(db database.PgDB, qs queue.Service, hs hub.InfoService, rs registry.Service, es encryption.Service, stop <-chan struct{}) {
+	runImageWorkers(qs, getWorkerCount(), stop, func(img *queue.ImageQueueMessage) {
+		log.Infof("Received Image: %v", img.ImageName)
+
+		// Dead-lettered images aren't inspected again until they're retried through the admin API
+		if db.IsDeadLettered(img.ImageName) {
+			log.Infof("Image %s is dead-lettered, not inspecting it", img.ImageName)
+			qs.DeleteImage(img)
+			return
+		}
+
+		err := inspector.Inspect(img.ImageName, &db, &rs, &hs, qs, es)
+		if err == nil {
+			err = inspector.InspectionSucceeded(&db, img.ImageName)
+			if err != nil {
+				log.Errorf("Failed to clear inspection failures for %s: %v", img.ImageName, err)
 			}
+
+			qs.DeleteImage(img)
+
+			// Getting the size information can take a while so we do this asynchronously.
+			// We have different environment variables for deciding which queue to send & receive on.
+			qs.SendImageFrom(img, "Sent for size inspection")
+			return
+		}
+
+		// If we failed to inspect this item, it might well be because Docker Hub has behaved badly.
+		// Retry it with backoff, until it has failed too many times and is dead-lettered.
+		log.Errorf("Failed to inspect %s: %v", img.ImageName, err)
+		deadLettered, delay, err := inspector.InspectionFailed(&db, img.ImageName, err)
+		if err != nil {
+			// Leave the message alone so the queue delivers it again
+			log.Errorf("Failed to record inspection failure for %s: %v", img.ImageName, err)
+			return
 		}
+
+		if deadLettered {
+			qs.DeleteImage(img)
+		} else {
+			qs.RetryImage(img, delay)
+		}
+	})
+}
+
+func startSizeInspector(db database.PgDB, qs queue.Service, rs registry.Service, es encryption.Service, stop <-chan struct{}) {
+	runImageWorkers(qs, getWorkerCount(), stop, func(img *queue.ImageQueueMessage) {
+		log.Debugf("Received Image for size processing: %v", img.ImageName)
+		err := inspector.InspectSize(img.ImageName, &db, &rs, es)
+		if err == nil {
+			qs.DeleteImage(img)
+		} else {
+			log.Errorf("size inspection of %s: %v", img.ImageName, err)
+		}
+	})
+}
+
+// getWorkerCount is how many images each inspector process works on at the same time
+func getWorkerCount() int {
+	workers, err := strconv.Atoi(utils.GetEnvOrDefault("MB_WORKER_COUNT", strconv.Itoa(constDefaultWorkerCount)))
+	if err != nil || workers < 1 {
+		log.Errorf("Invalid worker count %s, using %d", os.Getenv("MB_WORKER_COUNT"), constDefaultWorkerCount)
+		workers = constDefaultWorkerCount
 	}
+
+	return workers
 }
 
-func startSizeInspector(db database.PgDB, qs queue.Service, rs registry.Service, es encryption.Service) {
-	for {
-		img := qs.ReceiveImage()
-		if img != nil {
-			log.Debugf("Received Image for size processing: %v", img.ImageName)
-			err := inspector.InspectSize(img.ImageName, &db, &rs, es)
-			if err == nil {
-				qs.DeleteImage(img)
-			} else {
-				log.Errorf("size inspection of %s: %v", img.ImageName, err)
+// stopOnSignal returns a channel that's closed when we're asked to shut down. Call it once
+// and share the channel, as each call takes over the signals.
+func stopOnSignal() <-chan struct{} {
+	stop := make(chan struct{})
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGTERM, syscall.SIGINT)
+
+	go func() {
+		sig := <-sigs
+		log.Infof("Received %v, waiting for inspections to finish", sig)
+		close(stop)
+	}()
+
+	return stop
+}
+
+// runImageWorkers receives images from the queue and processes them using a pool of workers
+// until the stop channel is closed. Inspections in progress are allowed to finish, and
+// messages received after we're told to stop are released back to the queue.
+func runImageWorkers(qs queue.Service, workers int, stop <-chan struct{}, process func(img *queue.ImageQueueMessage)) {
+	var wg sync.WaitGroup
+	locks := newImageLocks()
+
+	log.Infof("Starting %d workers", workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				img := qs.ReceiveImage()
+				if img == nil || img.ImageName == "" {
+					continue
+				}
+
+				select {
+				case <-stop:
+					qs.ReleaseImage(img)
+					return
+				default:
+				}
+
+				// Wait if another worker is already inspecting this image
+				unlock := locks.lock(img.ImageName)
+				process(img)
+				unlock()
 			}
+		}()
+	}
+
+	wg.Wait()
+	log.Info("All workers stopped")
+}
+
+// imageLocks stops two workers from inspecting the same image at the same time
+type imageLocks struct {
+	mu    sync.Mutex
+	locks map[string]*imageLock
+}
+
+type imageLock struct {
+	sync.Mutex
+	waiting int
+}
+
+func newImageLocks() *imageLocks {
+	return &imageLocks{locks: make(map[string]*imageLock)}
+}
+
+// lock blocks until no other worker holds the lock for this image, and returns the unlock function
+func (l *imageLocks) lock(imageName string) func() {
+	l.mu.Lock()
+	il, ok := l.locks[imageName]
+	if !ok {
+		il = &imageLock{}
+		l.locks[imageName] = il
+	}
+	il.waiting++
+	l.mu.Unlock()
+
+	il.Lock()
+
+	return func() {
+		il.Unlock()
+
+		l.mu.Lock()
+		il.waiting--
+		if il.waiting == 0 {
+			delete(l.locks, imageName)
 		}
+		l.mu.Unlock()
 	}
 }
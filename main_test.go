@@ -1,9 +1,12 @@
 package main
 
 import (
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/microscaling/microbadger/database"
+	"github.com/microscaling/microbadger/queue"
 )
 
 func getTestDB(t *testing.T) database.PgDB {
@@ -18,3 +21,99 @@ func getTestDB(t *testing.T) database.PgDB {
 
 	return db
 }
+
+// testQueue hands out images from a channel
+type testQueue struct {
+	queue.MockService
+	images    chan string
+	onReceive func()
+	mu        sync.Mutex
+	released  []string
+}
+
+func (q *testQueue) ReceiveImage() *queue.ImageQueueMessage {
+	select {
+	case name := <-q.images:
+		if q.onReceive != nil {
+			q.onReceive()
+		}
+		return &queue.ImageQueueMessage{ImageName: name}
+	case <-time.After(10 * time.Millisecond):
+		return nil
+	}
+}
+
+func (q *testQueue) ReleaseImage(img *queue.ImageQueueMessage) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.released = append(q.released, img.ImageName)
+	return nil
+}
+
+func TestRunImageWorkers(t *testing.T) {
+	images := []string{"org/a", "org/b", "org/a", "org/c", "org/a", "org/d", "org/b", "org/e"}
+	qs := &testQueue{MockService: queue.NewMockService(), images: make(chan string, len(images))}
+	for _, name := range images {
+		qs.images <- name
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var running, maxRunning int
+	inspecting := make(map[string]bool)
+	processed := 0
+
+	wg.Add(len(images))
+	stop := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(stop)
+	}()
+
+	runImageWorkers(qs, 4, stop, func(img *queue.ImageQueueMessage) {
+		mu.Lock()
+		if inspecting[img.ImageName] {
+			t.Errorf("Image %s is already being inspected", img.ImageName)
+		}
+		inspecting[img.ImageName] = true
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		inspecting[img.ImageName] = false
+		running--
+		processed++
+		mu.Unlock()
+		wg.Done()
+	})
+
+	if processed != len(images) {
+		t.Errorf("Expected %d images processed, got %d", len(images), processed)
+	}
+
+	if maxRunning < 2 || maxRunning > 4 {
+		t.Errorf("Unexpected number of concurrent inspections %d", maxRunning)
+	}
+}
+
+func TestRunImageWorkersStop(t *testing.T) {
+	qs := &testQueue{MockService: queue.NewMockService(), images: make(chan string, 1)}
+	qs.images <- "org/late"
+
+	// Stop while we're waiting to receive a message
+	stop := make(chan struct{})
+	qs.onReceive = func() { close(stop) }
+
+	runImageWorkers(qs, 1, stop, func(img *queue.ImageQueueMessage) {
+		t.Errorf("Unexpectedly processed %s", img.ImageName)
+	})
+
+	if len(qs.released) != 1 || qs.released[0] != "org/late" {
+		t.Errorf("Unexpected images released %v", qs.released)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"io/ioutil"
 	"net/http"
-	"os"
 	"time"
 
 	"github.com/op/go-logging"
@@ -37,11 +36,7 @@ func main() {
 		return
 	}
 
-	if os.Getenv("MB_QUEUE_TYPE") == "nats" {
-		qs = queue.NewNatsService()
-	} else {
-		qs = queue.NewSqsService()
-	}
+	qs = queue.NewService(db.SQL(), "", "")
 
 	log.Info("starting notifier")
 	startNotifier(db, qs)
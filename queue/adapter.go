@@ -0,0 +1,222 @@
+package queue
+
+import (
+	"fmt"
+	"time"
+)
+
+// ServiceAdapter implements Service on a Queue. Image send & receive topics are different
+// for the inspector & size processes, so that's why we need both. They can be empty for
+// processes that don't send or receive images.
+type ServiceAdapter struct {
+	queue             Queue
+	imageSendTopic    Topic
+	imageReceiveTopic Topic
+}
+
+// make sure it satisfies the interface
+var _ Service = (*ServiceAdapter)(nil)
+
+// NewServiceAdapter sends and receives images on the topics, and notifications on TopicNotify.
+func NewServiceAdapter(q Queue, imageSendTopic Topic, imageReceiveTopic Topic) ServiceAdapter {
+	return ServiceAdapter{
+		queue:             q,
+		imageSendTopic:    imageSendTopic,
+		imageReceiveTopic: imageReceiveTopic,
+	}
+}
+
+// SendImage to the queue for processing by the inspector.
+func (a ServiceAdapter) SendImage(imageName string, state string) (err error) {
+	return a.sendImage(imageName, state, nil)
+}
+
+// SendImageFrom sends an image we received on to the next topic, in the same trace.
+func (a ServiceAdapter) SendImageFrom(parent *ImageQueueMessage, state string) (err error) {
+	return a.sendImage(parent.ImageName, state, parent.envelope)
+}
+
+func (a ServiceAdapter) sendImage(imageName string, state string, parent *Envelope) (err error) {
+	log.Debugf("Sending image %s to queue", imageName)
+
+	if a.imageSendTopic == "" {
+		return fmt.Errorf("No topic for sending image %s", imageName)
+	}
+
+	var env *Envelope
+	if parent != nil {
+		env, err = NewChildEnvelope(parent, a.imageSendTopic, ImageQueueMessage{ImageName: imageName})
+	} else {
+		env, err = NewEnvelope(a.imageSendTopic, ImageQueueMessage{ImageName: imageName})
+	}
+	if err != nil {
+		log.Errorf("Error: %v", err)
+		return err
+	}
+
+	err = a.queue.Publish(env)
+	if err != nil {
+		log.Errorf("Failed to send image %s: %v", imageName, err)
+		return
+	}
+
+	log.Infof("%s image %s to queue", state, imageName)
+	return err
+}
+
+// ReceiveImage from the queue for processing by the inspector.
+func (a ServiceAdapter) ReceiveImage() *ImageQueueMessage {
+	if a.imageReceiveTopic == "" {
+		log.Error("No topic for receiving images")
+		return nil
+	}
+
+	env, err := a.queue.Receive(a.imageReceiveTopic)
+	if err != nil {
+		log.Errorf("Error receiving image from queue: %v", err)
+		return nil
+	}
+
+	if env == nil {
+		return nil
+	}
+
+	var img ImageQueueMessage
+	err = env.Decode(&img)
+	if err != nil {
+		log.Errorf("Error unmarshaling from %s, error is %v", env.Payload, err)
+		a.queue.Ack(env)
+		return nil
+	}
+
+	log.Infof("Received image %s from queue, attempt %d.", img.ImageName, env.Attempt)
+	img.ReceiptHandle = &env.ReceiptHandle
+	img.envelope = env
+	return &img
+}
+
+// DeleteImage from the queue once it has successfully been inspected.
+func (a ServiceAdapter) DeleteImage(img *ImageQueueMessage) error {
+	env := a.received(img.envelope, a.imageReceiveTopic, img.ReceiptHandle)
+	if env == nil {
+		return nil
+	}
+
+	err := a.queue.Ack(env)
+	if err != nil {
+		log.Errorf("Error deleting image %s from queue: %v", img.ImageName, err)
+		return err
+	}
+
+	log.Infof("Deleted image %s from queue.", img.ImageName)
+	return nil
+}
+
+// ReleaseImage back to the queue without inspecting it, so it can be received straight away.
+func (a ServiceAdapter) ReleaseImage(img *ImageQueueMessage) error {
+	env := a.received(img.envelope, a.imageReceiveTopic, img.ReceiptHandle)
+	if env == nil {
+		return nil
+	}
+
+	err := a.queue.Nack(env, 0)
+	if err != nil {
+		log.Errorf("Error releasing image %s to queue: %v", img.ImageName, err)
+		return err
+	}
+
+	log.Infof("Released image %s to queue.", img.ImageName)
+	return nil
+}
+
+// RetryImage releases the image back to the queue to be received again after the delay.
+func (a ServiceAdapter) RetryImage(img *ImageQueueMessage, delay time.Duration) error {
+	env := a.received(img.envelope, a.imageReceiveTopic, img.ReceiptHandle)
+	if env == nil {
+		return nil
+	}
+
+	err := a.queue.Nack(env, delay)
+	if err != nil {
+		log.Errorf("Error releasing image %s to queue: %v", img.ImageName, err)
+		return err
+	}
+
+	log.Infof("Image %s will be retried in %v.", img.ImageName, delay)
+	return nil
+}
+
+// SendNotification to the queue for processing by the notifier.
+func (a ServiceAdapter) SendNotification(notificationID uint) error {
+	log.Debugf("Sending notification %d to queue", notificationID)
+
+	env, err := NewEnvelope(TopicNotify, NotificationQueueMessage{NotificationID: notificationID})
+	if err != nil {
+		log.Errorf("Error: %v", err)
+		return err
+	}
+
+	err = a.queue.Publish(env)
+	if err != nil {
+		log.Errorf("Failed to send notification %d: %v", notificationID, err)
+	}
+
+	return err
+}
+
+// ReceiveNotification from the queue for sending by the notifier.
+func (a ServiceAdapter) ReceiveNotification() *NotificationQueueMessage {
+	env, err := a.queue.Receive(TopicNotify)
+	if err != nil {
+		log.Errorf("Error receiving notification from queue: %v", err)
+		return nil
+	}
+
+	if env == nil {
+		return nil
+	}
+
+	var notification NotificationQueueMessage
+	err = env.Decode(&notification)
+	if err != nil {
+		log.Errorf("Error unmarshaling from %s, error is %v", env.Payload, err)
+		a.queue.Ack(env)
+		return nil
+	}
+
+	log.Infof("Received message for notification %d from queue, attempt %d.", notification.NotificationID, env.Attempt)
+	notification.ReceiptHandle = &env.ReceiptHandle
+	notification.envelope = env
+	return &notification
+}
+
+// DeleteNotification from the queue once it has been sent.
+func (a ServiceAdapter) DeleteNotification(notification *NotificationQueueMessage) error {
+	env := a.received(notification.envelope, TopicNotify, notification.ReceiptHandle)
+	if env == nil {
+		return nil
+	}
+
+	err := a.queue.Ack(env)
+	if err != nil {
+		log.Errorf("Error deleting notification %d from queue: %v", notification.NotificationID, err)
+		return err
+	}
+
+	log.Infof("Deleted notification %d from queue.", notification.NotificationID)
+	return nil
+}
+
+// received gets the envelope for a message we received. Messages can also be made up with
+// just a receipt handle.
+func (a ServiceAdapter) received(env *Envelope, topic Topic, receiptHandle *string) *Envelope {
+	if env != nil {
+		return env
+	}
+
+	if receiptHandle == nil {
+		return nil
+	}
+
+	return &Envelope{Topic: topic, ReceiptHandle: *receiptHandle}
+}
@@ -0,0 +1,122 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/microscaling/microbadger/utils"
+)
+
+const (
+	// Messages that are received but not deleted are delivered again after this long
+	constDefaultVisibilityTimeout = 5 * time.Minute
+
+	// Receiving waits this long for a message before giving up, like SQS long polling
+	constReceiveWait = 5 * time.Second
+)
+
+// backend has the basic queue operations. Receiving returns a nil body if there are no
+// messages, a receipt handle that identifies this delivery of the message, and how many
+// times it has been received.
+type backend interface {
+	send(queueName string, body []byte) error
+	receive(queueName string) (body []byte, receiptHandle string, receiveCount int, err error)
+	delete(queueName string, receiptHandle string) error
+	release(queueName string, receiptHandle string, delay time.Duration) error
+}
+
+// backendQueue implements Queue on top of a backend
+type backendQueue struct {
+	backend   backend
+	queueName func(topic Topic) string
+}
+
+func newBackendQueue(b backend, queueName func(topic Topic) string) backendQueue {
+	return backendQueue{backend: b, queueName: queueName}
+}
+
+// getQueueName for the topic is configured with MB_<TOPIC>_QUEUE_NAME e.g. MB_IMAGE_QUEUE_NAME,
+// and defaults to the topic name
+func getQueueName(topic Topic) string {
+	return utils.GetEnvOrDefault("MB_"+topic.envName()+"_QUEUE_NAME", string(topic))
+}
+
+// getVisibilityTimeout is how long received messages are hidden from other receivers
+func getVisibilityTimeout() time.Duration {
+	if s, err := strconv.Atoi(os.Getenv("MB_QUEUE_VISIBILITY_TIMEOUT")); err == nil && s > 0 {
+		return time.Duration(s) * time.Second
+	}
+
+	return constDefaultVisibilityTimeout
+}
+
+// Publish the envelope on its topic
+func (q backendQueue) Publish(env *Envelope) error {
+	queueName := q.queueName(env.Topic)
+	log.Debugf("Sending message %s on queue %s", env.ID, queueName)
+
+	bytes, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+
+	return q.backend.send(queueName, bytes)
+}
+
+// Receive the next message on the topic. Messages that can't be read are deleted, as
+// they'll never succeed.
+func (q backendQueue) Receive(topic Topic) (*Envelope, error) {
+	queueName := q.queueName(topic)
+	body, handle, receiveCount, err := q.backend.receive(queueName)
+	if err != nil || body == nil {
+		return nil, err
+	}
+
+	env, err := decodeEnvelope(topic, body)
+	if err != nil {
+		q.backend.delete(queueName, handle)
+		return nil, err
+	}
+
+	env.ReceiptHandle = handle
+	env.Attempt = receiveCount
+
+	return env, nil
+}
+
+// Ack deletes the message from the queue once it has been dealt with
+func (q backendQueue) Ack(env *Envelope) error {
+	return q.backend.delete(q.queueName(env.Topic), env.ReceiptHandle)
+}
+
+// Nack releases the message so it can be received again after the delay
+func (q backendQueue) Nack(env *Envelope, delay time.Duration) error {
+	return q.backend.release(q.queueName(env.Topic), env.ReceiptHandle, delay)
+}
+
+// formatReceiptHandle is different each time a message is received, so a receiver can't
+// delete a message once it has been passed to someone else
+func formatReceiptHandle(id uint64, receiveCount int) string {
+	return strconv.FormatUint(id, 10) + ":" + strconv.Itoa(receiveCount)
+}
+
+// parseReceiptHandle gets the message ID and receive count from the receipt handle
+func parseReceiptHandle(receiptHandle string) (id uint64, receiveCount int, err error) {
+	parts := strings.SplitN(receiptHandle, ":", 2)
+	if len(parts) == 2 {
+		id, err = strconv.ParseUint(parts[0], 10, 64)
+		if err == nil {
+			receiveCount, err = strconv.Atoi(parts[1])
+		}
+	}
+
+	if len(parts) != 2 || err != nil {
+		return 0, 0, fmt.Errorf("Bad receipt handle %s", receiptHandle)
+	}
+
+	return id, receiveCount, nil
+}
@@ -0,0 +1,106 @@
+package queue
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TraceContext follows a piece of work through the queues, in W3C Trace Context format
+type TraceContext struct {
+	TraceParent string `json:"traceparent,omitempty"`
+	TraceState  string `json:"tracestate,omitempty"`
+}
+
+// Envelope wraps a message with the details the queues need
+type Envelope struct {
+	ID         string          `json:"id"`
+	Topic      Topic           `json:"topic"`
+	Attempt    int             `json:"attempt"`
+	EnqueuedAt time.Time       `json:"enqueuedAt"`
+	Trace      TraceContext    `json:"trace"`
+	Payload    json.RawMessage `json:"payload"`
+
+	// ReceiptHandle identifies this delivery of the message, for acking it
+	ReceiptHandle string `json:"-"`
+}
+
+// NewEnvelope wraps the payload for sending on the topic, starting a new trace
+func NewEnvelope(topic Topic, payload interface{}) (*Envelope, error) {
+	return newEnvelope(topic, payload, newTraceContext())
+}
+
+// NewChildEnvelope is for a message sent while handling another one, and continues its trace
+func NewChildEnvelope(parent *Envelope, topic Topic, payload interface{}) (*Envelope, error) {
+	return newEnvelope(topic, payload, parent.Trace.child())
+}
+
+func newEnvelope(topic Topic, payload interface{}, trace TraceContext) (*Envelope, error) {
+	bytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Envelope{
+		ID:         newID(16),
+		Topic:      topic,
+		EnqueuedAt: time.Now().UTC(),
+		Trace:      trace,
+		Payload:    bytes,
+	}, nil
+}
+
+// Decode unmarshals the payload into v
+func (e *Envelope) Decode(v interface{}) error {
+	return json.Unmarshal(e.Payload, v)
+}
+
+// decodeEnvelope reads a message body from a queue. Messages sent before we had envelopes
+// are just the payload, so they get a new envelope.
+func decodeEnvelope(topic Topic, body []byte) (*Envelope, error) {
+	var env Envelope
+	err := json.Unmarshal(body, &env)
+	if err != nil {
+		return nil, fmt.Errorf("Error unmarshaling from %s: %v", body, err)
+	}
+
+	if len(env.Payload) == 0 {
+		env = Envelope{
+			ID:      newID(16),
+			Payload: json.RawMessage(body),
+			Trace:   newTraceContext(),
+		}
+	}
+
+	env.Topic = topic
+	return &env, nil
+}
+
+func newTraceContext() TraceContext {
+	return TraceContext{TraceParent: "00-" + newID(16) + "-" + newID(8) + "-01"}
+}
+
+// child keeps the trace ID with a new parent ID, or starts a new trace if there isn't a valid one
+func (t TraceContext) child() TraceContext {
+	parts := strings.Split(t.TraceParent, "-")
+	if len(parts) != 4 || len(parts[1]) != 32 {
+		return newTraceContext()
+	}
+
+	parts[2] = newID(8)
+	return TraceContext{TraceParent: strings.Join(parts, "-"), TraceState: t.TraceState}
+}
+
+// newID is n random bytes in hex
+func newID(n int) string {
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	if err != nil {
+		log.Errorf("Error generating ID: %v", err)
+	}
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,72 @@
+package queue
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestEnvelope(t *testing.T) {
+	env, err := NewEnvelope(TopicImage, ImageQueueMessage{ImageName: "lizrice/featured"})
+	if err != nil {
+		t.Fatalf("Error creating envelope: %v", err)
+	}
+
+	if len(env.ID) != 32 || env.Topic != TopicImage || env.EnqueuedAt.IsZero() {
+		t.Errorf("Unexpected envelope %v", env)
+	}
+
+	parts := strings.Split(env.Trace.TraceParent, "-")
+	if len(parts) != 4 || parts[0] != "00" || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		t.Errorf("Bad trace parent %s", env.Trace.TraceParent)
+	}
+
+	child, err := NewChildEnvelope(env, TopicSize, ImageQueueMessage{ImageName: "lizrice/featured"})
+	if err != nil {
+		t.Fatalf("Error creating envelope: %v", err)
+	}
+
+	childParts := strings.Split(child.Trace.TraceParent, "-")
+	if childParts[1] != parts[1] || childParts[2] == parts[2] || child.ID == env.ID {
+		t.Errorf("Expected the same trace with a new parent, got %s from %s", child.Trace.TraceParent, env.Trace.TraceParent)
+	}
+
+	var img ImageQueueMessage
+	err = child.Decode(&img)
+	if err != nil || img.ImageName != "lizrice/featured" {
+		t.Errorf("Decoded %v, error %v", img, err)
+	}
+}
+
+func TestDecodeEnvelope(t *testing.T) {
+	env, _ := NewEnvelope(TopicNotify, NotificationQueueMessage{NotificationID: 42})
+	env.Attempt = 2
+	bytes, _ := json.Marshal(env)
+
+	// Wrapped in an envelope
+	decoded, err := decodeEnvelope(TopicNotify, bytes)
+	if err != nil {
+		t.Fatalf("Error decoding envelope: %v", err)
+	}
+
+	if decoded.ID != env.ID || decoded.Attempt != 2 || decoded.Trace != env.Trace || !decoded.EnqueuedAt.Equal(env.EnqueuedAt) {
+		t.Errorf("Expected %v, got %v", env, decoded)
+	}
+
+	// Sent before we had envelopes
+	decoded, err = decodeEnvelope(TopicNotify, []byte(`{"NotificationID":42}`))
+	if err != nil {
+		t.Fatalf("Error decoding message: %v", err)
+	}
+
+	var n NotificationQueueMessage
+	err = decoded.Decode(&n)
+	if err != nil || n.NotificationID != 42 || decoded.ID == "" || decoded.Topic != TopicNotify {
+		t.Errorf("Decoded %v from %v, error %v", n, decoded, err)
+	}
+
+	_, err = decodeEnvelope(TopicNotify, []byte("not json"))
+	if err == nil {
+		t.Errorf("Expected an error")
+	}
+}
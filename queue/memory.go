@@ -0,0 +1,187 @@
+package queue
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const constMemoryQueueSize = 10000
+
+// MemoryService for sending and receiving messages on Go channels. The queues are shared
+// by everything in the process, so this is for running all of microbadger in one binary
+// and for tests. Messages are lost when the process exits.
+type MemoryService struct {
+	backendQueue
+}
+
+// make sure it satisfies the interface
+var _ Queue = (*MemoryService)(nil)
+
+// NewMemoryService uses the queue names from the environment.
+func NewMemoryService() MemoryService {
+	return MemoryService{newBackendQueue(memoryQueues, getQueueName)}
+}
+
+var memoryQueues = newMemoryBackend(getVisibilityTimeout())
+
+// memoryBackend holds the named queues
+type memoryBackend struct {
+	mu                sync.Mutex
+	queues            map[string]*memoryQueue
+	visibilityTimeout time.Duration
+}
+
+type memoryMessage struct {
+	id           uint64
+	body         []byte
+	receiveCount int
+}
+
+// memoryQueue has a channel of messages waiting to be received, and the messages that have
+// been received but not yet deleted. These go back on the channel if they aren't deleted
+// before the visibility timeout.
+type memoryQueue struct {
+	messages chan *memoryMessage
+
+	mu       sync.Mutex
+	nextID   uint64
+	inFlight map[string]*memoryInFlight
+}
+
+type memoryInFlight struct {
+	msg   *memoryMessage
+	timer *time.Timer
+}
+
+func newMemoryBackend(visibilityTimeout time.Duration) *memoryBackend {
+	return &memoryBackend{
+		queues:            make(map[string]*memoryQueue),
+		visibilityTimeout: visibilityTimeout,
+	}
+}
+
+func (b *memoryBackend) queue(queueName string) *memoryQueue {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, ok := b.queues[queueName]
+	if !ok {
+		q = &memoryQueue{
+			messages: make(chan *memoryMessage, constMemoryQueueSize),
+			inFlight: make(map[string]*memoryInFlight),
+		}
+		b.queues[queueName] = q
+	}
+
+	return q
+}
+
+func (b *memoryBackend) send(queueName string, body []byte) error {
+	q := b.queue(queueName)
+
+	q.mu.Lock()
+	q.nextID++
+	msg := &memoryMessage{id: q.nextID, body: body}
+	q.mu.Unlock()
+
+	select {
+	case q.messages <- msg:
+		return nil
+	default:
+		return fmt.Errorf("Queue %s is full", queueName)
+	}
+}
+
+func (b *memoryBackend) receive(queueName string) (body []byte, receiptHandle string, receiveCount int, err error) {
+	q := b.queue(queueName)
+
+	timeout := time.NewTimer(constReceiveWait)
+	defer timeout.Stop()
+
+	var msg *memoryMessage
+	select {
+	case msg = <-q.messages:
+	case <-timeout.C:
+		return nil, "", 0, nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	msg.receiveCount++
+	receiptHandle = formatReceiptHandle(msg.id, msg.receiveCount)
+	q.inFlight[receiptHandle] = &memoryInFlight{
+		msg: msg,
+		timer: time.AfterFunc(b.visibilityTimeout, func() {
+			q.requeue(receiptHandle)
+		}),
+	}
+
+	return msg.body, receiptHandle, msg.receiveCount, nil
+}
+
+// delete the message for good, as long as it hasn't gone back on the queue after
+// the visibility timeout
+func (b *memoryBackend) delete(queueName string, receiptHandle string) error {
+	q := b.queue(queueName)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	f, ok := q.inFlight[receiptHandle]
+	if !ok {
+		return fmt.Errorf("Receipt handle %s is not valid for queue %s", receiptHandle, queueName)
+	}
+
+	f.timer.Stop()
+	delete(q.inFlight, receiptHandle)
+	return nil
+}
+
+// release the message so it can be received again after the delay
+func (b *memoryBackend) release(queueName string, receiptHandle string, delay time.Duration) error {
+	q := b.queue(queueName)
+
+	q.mu.Lock()
+	f, ok := q.inFlight[receiptHandle]
+	if ok {
+		f.timer.Stop()
+		if delay > 0 {
+			f.timer = time.AfterFunc(delay, func() {
+				q.requeue(receiptHandle)
+			})
+		}
+	}
+	q.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("Receipt handle %s is not valid for queue %s", receiptHandle, queueName)
+	}
+
+	if delay <= 0 {
+		q.requeue(receiptHandle)
+	}
+	return nil
+}
+
+// requeue puts an in flight message back on the channel
+func (q *memoryQueue) requeue(receiptHandle string) {
+	q.mu.Lock()
+	f, ok := q.inFlight[receiptHandle]
+	delete(q.inFlight, receiptHandle)
+	q.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	select {
+	case q.messages <- f.msg:
+	default:
+		// Don't lose the message if the channel is full, it'll go back on when there's room
+		go func() {
+			q.messages <- f.msg
+		}()
+	}
+}
@@ -0,0 +1,211 @@
+package queue
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestMemoryService(visibilityTimeout time.Duration) ServiceAdapter {
+	q := MemoryService{newBackendQueue(newMemoryBackend(visibilityTimeout), getQueueName)}
+	return NewServiceAdapter(q, TopicImage, TopicImage)
+}
+
+func TestMemoryImages(t *testing.T) {
+	q := newTestMemoryService(time.Minute)
+
+	for _, name := range []string{"lizrice/featured", "microscaling/microscaling"} {
+		err := q.SendImage(name, "Sent")
+		if err != nil {
+			t.Fatalf("Error sending %s: %v", name, err)
+		}
+	}
+
+	// Images come off the queue in order
+	for _, name := range []string{"lizrice/featured", "microscaling/microscaling"} {
+		img := q.ReceiveImage()
+		if img == nil || img.ImageName != name {
+			t.Fatalf("Expected %s, got %v", name, img)
+		}
+
+		if img.ReceiptHandle == nil {
+			t.Fatalf("No receipt handle for %s", name)
+		}
+
+		err := q.DeleteImage(img)
+		if err != nil {
+			t.Errorf("Error deleting %s: %v", name, err)
+		}
+
+		err = q.DeleteImage(img)
+		if err == nil {
+			t.Errorf("Expected an error deleting %s twice", name)
+		}
+	}
+}
+
+func TestMemoryVisibilityTimeout(t *testing.T) {
+	q := newTestMemoryService(50 * time.Millisecond)
+
+	q.SendImage("lizrice/featured", "Sent")
+	first := q.ReceiveImage()
+	if first == nil {
+		t.Fatalf("Expected an image")
+	}
+
+	// Not deleted in time so it goes back on the queue
+	second := q.ReceiveImage()
+	if second == nil || second.ImageName != "lizrice/featured" {
+		t.Fatalf("Expected the image to be received again, got %v", second)
+	}
+
+	if *first.ReceiptHandle == *second.ReceiptHandle {
+		t.Errorf("Expected a new receipt handle, got %s twice", *first.ReceiptHandle)
+	}
+
+	if first.envelope.Attempt != 1 || second.envelope.Attempt != 2 {
+		t.Errorf("Expected attempts 1 and 2, got %d and %d", first.envelope.Attempt, second.envelope.Attempt)
+	}
+
+	if first.envelope.ID != second.envelope.ID {
+		t.Errorf("Expected the same message ID, got %s and %s", first.envelope.ID, second.envelope.ID)
+	}
+
+	err := q.DeleteImage(first)
+	if err == nil {
+		t.Errorf("Expected an error deleting with an expired receipt handle")
+	}
+
+	err = q.DeleteImage(second)
+	if err != nil {
+		t.Errorf("Error deleting image: %v", err)
+	}
+}
+
+func TestMemoryRelease(t *testing.T) {
+	q := newTestMemoryService(time.Minute)
+
+	q.SendImage("lizrice/featured", "Sent")
+	img := q.ReceiveImage()
+	if img == nil {
+		t.Fatalf("Expected an image")
+	}
+
+	err := q.ReleaseImage(img)
+	if err != nil {
+		t.Errorf("Error releasing image: %v", err)
+	}
+
+	start := time.Now()
+	img = q.ReceiveImage()
+	if img == nil || img.ImageName != "lizrice/featured" {
+		t.Fatalf("Expected the released image, got %v", img)
+	}
+
+	if time.Since(start) > time.Second {
+		t.Errorf("Released image took %v to be received", time.Since(start))
+	}
+}
+
+func TestMemoryRetry(t *testing.T) {
+	q := newTestMemoryService(time.Minute)
+
+	q.SendImage("lizrice/featured", "Sent")
+	img := q.ReceiveImage()
+	if img == nil {
+		t.Fatalf("Expected an image")
+	}
+
+	start := time.Now()
+	err := q.RetryImage(img, 200*time.Millisecond)
+	if err != nil {
+		t.Errorf("Error retrying image: %v", err)
+	}
+
+	img = q.ReceiveImage()
+	if img == nil || img.ImageName != "lizrice/featured" {
+		t.Fatalf("Expected the retried image, got %v", img)
+	}
+
+	if time.Since(start) < 200*time.Millisecond {
+		t.Errorf("Retried image was received after %v, before the delay", time.Since(start))
+	}
+
+	if img.envelope.Attempt != 2 {
+		t.Errorf("Expected attempt 2, got %d", img.envelope.Attempt)
+	}
+}
+
+func TestMemoryNotifications(t *testing.T) {
+	q := newTestMemoryService(time.Minute)
+
+	q.SendImage("lizrice/featured", "Sent")
+	err := q.SendNotification(42)
+	if err != nil {
+		t.Fatalf("Error sending notification: %v", err)
+	}
+
+	// Notifications are on their own queue
+	n := q.ReceiveNotification()
+	if n == nil || n.NotificationID != 42 {
+		t.Fatalf("Expected notification 42, got %v", n)
+	}
+
+	err = q.DeleteNotification(n)
+	if err != nil {
+		t.Errorf("Error deleting notification: %v", err)
+	}
+}
+
+func TestMemoryTopics(t *testing.T) {
+	q := MemoryService{newBackendQueue(newMemoryBackend(time.Minute), getQueueName)}
+	inspector := NewServiceAdapter(q, TopicSize, TopicImage)
+	size := NewServiceAdapter(q, "", TopicSize)
+
+	err := NewServiceAdapter(q, TopicImage, "").SendImage("lizrice/featured", "Sent")
+	if err != nil {
+		t.Fatalf("Error sending image: %v", err)
+	}
+
+	img := inspector.ReceiveImage()
+	if img == nil || img.ImageName != "lizrice/featured" {
+		t.Fatalf("Expected image for inspection, got %v", img)
+	}
+
+	inspector.DeleteImage(img)
+	err = inspector.SendImageFrom(img, "Sent for size inspection")
+	if err != nil {
+		t.Fatalf("Error sending image for size inspection: %v", err)
+	}
+
+	traceParent := img.envelope.Trace.TraceParent
+	img = size.ReceiveImage()
+	if img == nil || img.ImageName != "lizrice/featured" {
+		t.Fatalf("Expected image for size inspection, got %v", img)
+	}
+
+	// Size inspection is part of the same trace
+	traceID := strings.Split(traceParent, "-")[1]
+	if strings.Split(img.envelope.Trace.TraceParent, "-")[1] != traceID || img.envelope.Trace.TraceParent == traceParent {
+		t.Errorf("Expected a child of %s, got %s", traceParent, img.envelope.Trace.TraceParent)
+	}
+
+	err = size.SendImage(img.ImageName, "Sent")
+	if err == nil {
+		t.Errorf("Expected an error sending with no topic")
+	}
+}
+
+func TestParseReceiptHandle(t *testing.T) {
+	id, count, err := parseReceiptHandle(formatReceiptHandle(123, 4))
+	if err != nil || id != 123 || count != 4 {
+		t.Errorf("Got %d %d %v", id, count, err)
+	}
+
+	for _, handle := range []string{"", "123", "abc:1", "1:abc"} {
+		_, _, err = parseReceiptHandle(handle)
+		if err == nil {
+			t.Errorf("Expected an error for receipt handle %q", handle)
+		}
+	}
+}
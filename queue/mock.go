@@ -1,47 +1,45 @@
 package queue
 
-// MockService for tests
-type MockService struct{}
+import (
+	"encoding/json"
+	"time"
+)
 
-// make sure it satisfies the interface
-var _ Service = (*MockService)(nil)
+// MockQueue for tests
+type MockQueue struct{}
 
-func NewMockService() MockService {
-	return MockService{}
-}
+// make sure it satisfies the interface
+var _ Queue = (*MockQueue)(nil)
 
-// SendImage on mock queue always succeeds
-func (q MockService) SendImage(imageName string, state string) (err error) {
-	log.Infof("Sending %s image %s to queue", state, imageName)
+// Publish on mock queue always succeeds
+func (q MockQueue) Publish(env *Envelope) error {
+	log.Infof("Sending message %s on topic %s", env.ID, env.Topic)
 	return nil
 }
 
-// ReceiveImage on mock queue always succeeds
-func (q MockService) ReceiveImage() *ImageQueueMessage {
-	log.Infof("Received image with no name from mock queue.")
-	return &ImageQueueMessage{}
+// Receive on mock queue always gets an empty message
+func (q MockQueue) Receive(topic Topic) (*Envelope, error) {
+	log.Infof("Received empty message from mock queue.")
+	return &Envelope{Topic: topic, Attempt: 1, Payload: json.RawMessage("{}")}, nil
 }
 
-// DeleteImage on mock queue always succeeds
-func (q MockService) DeleteImage(img *ImageQueueMessage) error {
-	log.Infof("Deleted image %s from queue.", img.ImageName)
+// Ack on mock queue always succeeds
+func (q MockQueue) Ack(env *Envelope) error {
+	log.Infof("Deleted message %s from queue.", env.ID)
 	return nil
 }
 
-// SendNotification on mock queue always succeeds
-func (q MockService) SendNotification(id uint) (err error) {
-	log.Infof("Sending notification message %d to queue", id)
+// Nack on mock queue always succeeds
+func (q MockQueue) Nack(env *Envelope, delay time.Duration) error {
+	log.Infof("Released message %s to queue with delay %v.", env.ID, delay)
 	return nil
 }
 
-// ReceiveNotification on mock queue always succeeds
-func (q MockService) ReceiveNotification() *NotificationQueueMessage {
-	log.Infof("Received notification from mock queue.")
-	return &NotificationQueueMessage{}
+// MockService for tests
+type MockService struct {
+	ServiceAdapter
 }
 
-// DeleteNotification on mock queue always succeeds
-func (q MockService) DeleteNotification(notify *NotificationQueueMessage) error {
-	log.Info("Deleted notification from queue.")
-	return nil
+func NewMockService() MockService {
+	return MockService{NewServiceAdapter(MockQueue{}, TopicImage, TopicImage)}
 }
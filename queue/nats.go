@@ -3,156 +3,207 @@ package queue
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	nats "github.com/nats-io/nats.go"
 )
 
-// NatsService for sending and receiving messages to Nats.
+const (
+	// Messages that haven't been acked after this many deliveries aren't delivered again
+	constDefaultMaxDeliver = 10
+
+	// How long to wait for the server to confirm acks
+	constNatsAckWait = 5 * time.Second
+
+	// Ack subjects are $JS.ACK.<stream>.<consumer>.<delivered>.<stream seq>.<consumer seq>...
+	constNatsAckPrefix = "$JS.ACK."
+
+	// The server sends this advisory, with the stream & consumer appended, when a message
+	// reaches the max deliveries
+	constNatsMaxDeliveriesPrefix = "$JS.EVENT.ADVISORY.CONSUMER.MAX_DELIVERIES."
+)
+
+// NatsService for sending and receiving messages with NATS JetStream. Topics use the queue
+// names from the environment as subjects. Each queue has its own stream, which keeps messages
+// until they're acked, and a durable pull consumer shared by all the receivers.
 type NatsService struct {
-	nc *nats.Conn
-	// Image send & receive queues are different for inspector & size processes,
-	// so that's why we need both send & receive
-	imageSendQueueName    string
-	imageReceiveQueueName string
-	notificationQueueName string
+	backendQueue
 }
 
+// make sure it satisfies the interface
+var _ Queue = (*NatsService)(nil)
+
 // NewNatsService opens a new session with Nats.
 func NewNatsService() NatsService {
 	baseURL := os.Getenv("NATS_BASE_URL")
 
-	nc, err := nats.Connect(baseURL)
+	b, err := newNatsBackend(baseURL, getVisibilityTimeout(), getMaxDeliver())
 	if err != nil {
-		log.Errorf("Unable to connect to queue at %s: %v", nats.DefaultURL, err)
+		log.Errorf("Unable to connect to queue at %s: %v", baseURL, err)
 	}
 
-	receiveQueueURL := os.Getenv("NATS_RECEIVE_QUEUE_NAME")
+	return NatsService{newBackendQueue(b, getQueueName)}
+}
 
-	return NatsService{
-		nc:                    nc,
-		imageSendQueueName:    os.Getenv("NATS_SEND_QUEUE_NAME"),
-		imageReceiveQueueName: receiveQueueURL,
-		notificationQueueName: os.Getenv("MB_NOTIFY_QUEUE_NAME"),
+// getMaxDeliver is how many times a message is delivered before giving up on it
+func getMaxDeliver() int {
+	if n, err := strconv.Atoi(os.Getenv("MB_QUEUE_MAX_DELIVER")); err == nil && n > 0 {
+		return n
 	}
+
+	return constDefaultMaxDeliver
 }
 
-// SendImage to the Nats queue for processing by the Inspector.
-func (q NatsService) SendImage(imageName string, state string) (err error) {
-	log.Debugf("Sending image %s to queue", imageName)
+var errNatsNotConnected = errors.New("Not connected to NATS")
 
-	msg := ImageQueueMessage{
-		ImageName: imageName,
-	}
+type natsBackend struct {
+	nc *nats.Conn
+	js nats.JetStreamContext
 
-	bytes, err := json.Marshal(msg)
-	if err != nil {
-		log.Errorf("Error: %v", err)
-		return err
+	// Unacked messages are redelivered after the ack wait
+	ackWait    time.Duration
+	maxDeliver int
+
+	mu   sync.Mutex
+	subs map[string]*nats.Subscription
+}
+
+func newNatsBackend(url string, ackWait time.Duration, maxDeliver int) (*natsBackend, error) {
+	b := &natsBackend{
+		ackWait:    ackWait,
+		maxDeliver: maxDeliver,
+		subs:       make(map[string]*nats.Subscription),
 	}
 
-	err = q.natsSend(q.imageSendQueueName, bytes)
+	var err error
+	b.nc, err = nats.Connect(url)
 	if err != nil {
-		log.Errorf("Failed to send image %s: %v", imageName, err)
-		return
+		return b, err
 	}
 
-	log.Infof("%s image %s to queue", state, imageName)
-	return err
+	b.js, err = b.nc.JetStream()
+	return b, err
 }
 
-// ReceiveImage from the Nats queue for processing by the inspector.
-func (q NatsService) ReceiveImage() *ImageQueueMessage {
-	log.Debugf("Receiving on queue %s", q.imageReceiveQueueName)
+// natsStreamName for the queue. Stream names can't have dots or wildcards like subjects can.
+func natsStreamName(queueName string) string {
+	return strings.NewReplacer(".", "_", "*", "_", ">", "_", " ", "_").Replace(queueName)
+}
 
-	msg, err := q.natsReceive(q.imageReceiveQueueName)
-	if err != nil {
-		log.Errorf("Error receiving image from queue: %v", err)
+// addStream creates the stream for the queue if it's not already there. It keeps each
+// message until it's acked.
+func (b *natsBackend) addStream(queueName string) error {
+	stream := natsStreamName(queueName)
+	_, err := b.js.StreamInfo(stream)
+	if err == nil {
 		return nil
 	}
 
-	if msg != nil {
-		var img ImageQueueMessage
-
-		err = json.Unmarshal(msg, &img)
-		if err != nil {
-			log.Errorf("Error unmarshaling from %v, error is %v", img, err)
-			return nil
-		}
-
-		log.Infof("Received image %s from queue.", img.ImageName)
+	if !errors.Is(err, nats.ErrStreamNotFound) {
+		return err
+	}
 
-		return &ImageQueueMessage{
-			ImageName: img.ImageName,
-		}
+	log.Infof("Creating stream %s", stream)
+	_, err = b.js.AddStream(&nats.StreamConfig{
+		Name:      stream,
+		Subjects:  []string{queueName},
+		Retention: nats.WorkQueuePolicy,
+		Storage:   nats.FileStorage,
+	})
+	if errors.Is(err, nats.ErrStreamNameAlreadyInUse) {
+		// Someone else created it first
+		return nil
 	}
 
-	return nil
+	return err
 }
 
-// DeleteImage from the queue once it has successfully been inspected.
-func (q NatsService) DeleteImage(img *ImageQueueMessage) error {
-	log.Infof("Deleting image %s not implemented for NATS.", img.ImageName)
-	return nil
-}
+// subscription to the durable consumer for the queue, creating it the first time
+func (b *natsBackend) subscription(queueName string) (*nats.Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
 
-// SendNotification  to the SQS queue for processing by the Notifier.
-func (q NatsService) SendNotification(notificationID uint) (err error) {
-	log.Debugf("Sending notification %s to queue", notificationID)
+	if sub, ok := b.subs[queueName]; ok {
+		return sub, nil
+	}
 
-	msg := NotificationQueueMessage{
-		NotificationID: notificationID,
+	err := b.addStream(queueName)
+	if err != nil {
+		return nil, err
 	}
 
-	bytes, err := json.Marshal(msg)
+	stream := natsStreamName(queueName)
+	consumer := stream + "-workers"
+	sub, err := b.js.PullSubscribe(queueName, consumer,
+		nats.BindStream(stream),
+		nats.AckExplicit(),
+		nats.AckWait(b.ackWait),
+		nats.MaxDeliver(b.maxDeliver),
+	)
 	if err != nil {
-		log.Errorf("Error: %v", err)
-		return err
+		return nil, err
 	}
 
-	q.natsSend(q.notificationQueueName, bytes)
+	b.subs[queueName] = sub
 
-	return err
+	// With work queue retention a message that reaches the max deliveries would stay in the
+	// stream for ever, so we delete it. The queue group means only one receiver does this.
+	_, err = b.nc.QueueSubscribe(constNatsMaxDeliveriesPrefix+stream+"."+consumer, consumer, b.maxDeliveries)
+	if err != nil {
+		log.Errorf("NATS max deliveries subscribe error: %v", err)
+	}
+
+	return sub, nil
 }
 
-// ReceiveNotification from the SQS queue for sending by the notifier
-func (q NatsService) ReceiveNotification() *NotificationQueueMessage {
-	log.Debug("Checking queue for notification messages.")
+// natsMaxDeliveriesAdvisory is the part of the advisory we need
+type natsMaxDeliveriesAdvisory struct {
+	Stream     string `json:"stream"`
+	StreamSeq  uint64 `json:"stream_seq"`
+	Deliveries uint64 `json:"deliveries"`
+}
 
-	msg, err := q.natsReceive(q.notificationQueueName)
+// maxDeliveries removes a message that has been delivered too many times from its stream
+func (b *natsBackend) maxDeliveries(msg *nats.Msg) {
+	var advisory natsMaxDeliveriesAdvisory
+	err := json.Unmarshal(msg.Data, &advisory)
 	if err != nil {
-		log.Errorf("Error receiving notification from queue: %v", err)
-		return nil
+		log.Errorf("Error unmarshaling max deliveries advisory %s: %v", msg.Data, err)
+		return
 	}
 
-	if msg != nil {
-		var notification NotificationQueueMessage
-
-		err = json.Unmarshal(msg, &notification)
-		if err != nil {
-			log.Errorf("Error unmarshaling from %v, error is %v", notification, err)
-			return nil
-		}
+	log.Errorf("Message %d on stream %s was delivered %d times, deleting it", advisory.StreamSeq, advisory.Stream, advisory.Deliveries)
+	err = b.js.DeleteMsg(advisory.Stream, advisory.StreamSeq)
+	if err != nil {
+		log.Errorf("Error deleting message %d from stream %s: %v", advisory.StreamSeq, advisory.Stream, err)
+	}
+}
 
-		log.Infof("Received message for notification %d from queue.", notification.NotificationID)
+func (b *natsBackend) send(queueName string, message []byte) (err error) {
+	log.Debugf("Sending on queue %s", queueName)
 
-		return &notification
+	if b.js == nil {
+		return errNatsNotConnected
 	}
 
-	return nil
-}
-
-// DeleteNotification from the queue once it has been sent.
-func (q NatsService) DeleteNotification(notification *NotificationQueueMessage) error {
-	log.Infof("Deleting notification %d not implemented for NATS.", notification.NotificationID)
-	return nil
-}
+	b.mu.Lock()
+	_, subscribed := b.subs[queueName]
+	b.mu.Unlock()
 
-func (q NatsService) natsSend(queueName string, message []byte) (err error) {
-	log.Debugf("Sending on queue %s", queueName)
+	if !subscribed {
+		err = b.addStream(queueName)
+		if err != nil {
+			log.Errorf("Nats stream Error: %v", err)
+			return err
+		}
+	}
 
-	err = q.nc.Publish(queueName, message)
+	_, err = b.js.Publish(queueName, message)
 	if err != nil {
 		log.Errorf("Nats send Error: %v", err)
 	}
@@ -160,21 +211,73 @@ func (q NatsService) natsSend(queueName string, message []byte) (err error) {
 	return err
 }
 
-func (q NatsService) natsReceive(queueName string) (message []byte, err error) {
-	sub, err := q.nc.SubscribeSync(queueName)
+// receive the next message. The receipt handle is the ack subject, which has the JetStream
+// metadata for the message, so it can be acked by any connection.
+func (b *natsBackend) receive(queueName string) (message []byte, receiptHandle string, receiveCount int, err error) {
+	if b.js == nil {
+		return nil, "", 0, errNatsNotConnected
+	}
+
+	sub, err := b.subscription(queueName)
 	if err != nil {
 		log.Errorf("NATS subscribe error: %v", err)
 		return
 	}
 
-	msg, err := sub.NextMsg(30 * time.Second)
-	if errors.Is(err, nats.ErrTimeout) {
+	msgs, err := sub.Fetch(1, nats.MaxWait(constReceiveWait))
+	if errors.Is(err, nats.ErrTimeout) || (err == nil && len(msgs) == 0) {
 		// Waiting for a message timed out. We return nil and will retry.
-		return nil, nil
+		return nil, "", 0, nil
 	} else if err != nil {
-		log.Errorf("NATS next message error: %v", err)
+		log.Errorf("NATS fetch error: %v", err)
 		return
 	}
 
-	return msg.Data, nil
+	msg := msgs[0]
+	meta, err := msg.Metadata()
+	if err != nil {
+		log.Errorf("NATS message metadata error: %v", err)
+		return
+	}
+
+	log.Debugf("Received message %d from stream %s, delivered %d times", meta.Sequence.Stream, meta.Stream, meta.NumDelivered)
+	return msg.Data, msg.Reply, int(meta.NumDelivered), nil
+}
+
+// delete acks the message and waits for the server to confirm it
+func (b *natsBackend) delete(queueName string, receiptHandle string) error {
+	err := b.checkReceiptHandle(receiptHandle)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.nc.Request(receiptHandle, []byte("+ACK"), constNatsAckWait)
+	return err
+}
+
+// release naks the message so it's redelivered after the delay
+func (b *natsBackend) release(queueName string, receiptHandle string, delay time.Duration) error {
+	err := b.checkReceiptHandle(receiptHandle)
+	if err != nil {
+		return err
+	}
+
+	nak := "-NAK"
+	if delay > 0 {
+		nak = fmt.Sprintf(`-NAK {"delay": %d}`, delay.Nanoseconds())
+	}
+
+	return b.nc.Publish(receiptHandle, []byte(nak))
+}
+
+func (b *natsBackend) checkReceiptHandle(receiptHandle string) error {
+	if b.nc == nil {
+		return errNatsNotConnected
+	}
+
+	if !strings.HasPrefix(receiptHandle, constNatsAckPrefix) {
+		return fmt.Errorf("Receipt handle %s is not a JetStream ack subject", receiptHandle)
+	}
+
+	return nil
 }
@@ -0,0 +1,162 @@
+package queue
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	server "github.com/nats-io/nats-server/v2/server"
+)
+
+// startNatsServer runs an embedded NATS server with JetStream
+func startNatsServer(t *testing.T) *server.Server {
+	s, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	if err != nil {
+		t.Fatalf("Error creating NATS server: %v", err)
+	}
+
+	go s.Start()
+	if !s.ReadyForConnections(10 * time.Second) {
+		t.Fatalf("NATS server not ready")
+	}
+
+	t.Cleanup(s.Shutdown)
+	return s
+}
+
+func newTestNatsService(t *testing.T, s *server.Server, ackWait time.Duration, maxDeliver int) ServiceAdapter {
+	b, err := newNatsBackend(s.ClientURL(), ackWait, maxDeliver)
+	if err != nil {
+		t.Fatalf("Error connecting to NATS: %v", err)
+	}
+	t.Cleanup(b.nc.Close)
+
+	return NewServiceAdapter(NatsService{newBackendQueue(b, getQueueName)}, TopicImage, TopicImage)
+}
+
+func TestNatsImages(t *testing.T) {
+	s := startNatsServer(t)
+	q := newTestNatsService(t, s, time.Minute, 5)
+
+	for _, name := range []string{"lizrice/featured", "microscaling/microscaling"} {
+		err := q.SendImage(name, "Sent")
+		if err != nil {
+			t.Fatalf("Error sending %s: %v", name, err)
+		}
+	}
+
+	for _, name := range []string{"lizrice/featured", "microscaling/microscaling"} {
+		img := q.ReceiveImage()
+		if img == nil || img.ImageName != name {
+			t.Fatalf("Expected %s, got %v", name, img)
+		}
+
+		if !strings.HasPrefix(*img.ReceiptHandle, "$JS.ACK.image.image-workers.1.") || img.envelope.Attempt != 1 {
+			t.Errorf("Unexpected receipt handle %s for attempt %d", *img.ReceiptHandle, img.envelope.Attempt)
+		}
+
+		err := q.DeleteImage(img)
+		if err != nil {
+			t.Errorf("Error deleting %s: %v", name, err)
+		}
+	}
+
+	// Acked messages are removed from the stream
+	info, err := q.queue.(NatsService).backend.(*natsBackend).js.StreamInfo("image")
+	if err != nil {
+		t.Fatalf("Error getting stream info: %v", err)
+	}
+
+	if info.State.Msgs != 0 {
+		t.Errorf("Expected no messages left in the stream, got %d", info.State.Msgs)
+	}
+}
+
+func TestNatsRedelivery(t *testing.T) {
+	s := startNatsServer(t)
+	q := newTestNatsService(t, s, 500*time.Millisecond, 3)
+
+	q.SendImage("lizrice/featured", "Sent")
+
+	// Released messages come back straight away
+	img := q.ReceiveImage()
+	if img == nil {
+		t.Fatalf("Expected an image")
+	}
+	q.ReleaseImage(img)
+
+	img = q.ReceiveImage()
+	if img == nil || img.envelope.Attempt != 2 {
+		t.Fatalf("Expected the released image on attempt 2, got %v", img)
+	}
+
+	// Messages that aren't acked come back after the ack wait
+	start := time.Now()
+	img = q.ReceiveImage()
+	if img == nil || img.envelope.Attempt != 3 {
+		t.Fatalf("Expected the image again on attempt 3, got %v", img)
+	}
+
+	if time.Since(start) < 400*time.Millisecond {
+		t.Errorf("Image was redelivered after %v, before the ack wait", time.Since(start))
+	}
+
+	// Not delivered again after the max deliveries
+	img = q.ReceiveImage()
+	if img != nil {
+		t.Errorf("Expected no more deliveries, got attempt %d", img.envelope.Attempt)
+	}
+
+	// and deleted from the stream
+	js := q.queue.(NatsService).backend.(*natsBackend).js
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		info, err := js.StreamInfo("image")
+		if err != nil {
+			t.Fatalf("Error getting stream info: %v", err)
+		}
+
+		if info.State.Msgs == 0 {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the image to be deleted from the stream, %d messages left", info.State.Msgs)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func TestNatsNotifications(t *testing.T) {
+	s := startNatsServer(t)
+	q := newTestNatsService(t, s, time.Minute, 5)
+
+	err := q.SendNotification(42)
+	if err != nil {
+		t.Fatalf("Error sending notification: %v", err)
+	}
+
+	// A different connection can ack with the receipt handle
+	other := newTestNatsService(t, s, time.Minute, 5)
+	n := other.ReceiveNotification()
+	if n == nil || n.NotificationID != 42 {
+		t.Fatalf("Expected notification 42, got %v", n)
+	}
+
+	err = q.DeleteNotification(&NotificationQueueMessage{NotificationID: 42, ReceiptHandle: n.ReceiptHandle})
+	if err != nil {
+		t.Errorf("Error deleting notification: %v", err)
+	}
+
+	err = q.DeleteNotification(&NotificationQueueMessage{NotificationID: 42, ReceiptHandle: &[]string{"1:1"}[0]})
+	if err == nil {
+		t.Errorf("Expected an error deleting with a bad receipt handle")
+	}
+}
@@ -0,0 +1,134 @@
+package queue
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// How often to look for messages while waiting to receive
+const constPostgresPollInterval = 500 * time.Millisecond
+
+const constCreateQueueTable = `CREATE TABLE IF NOT EXISTS queue_messages (
+	id BIGSERIAL PRIMARY KEY,
+	queue_name TEXT NOT NULL,
+	body TEXT NOT NULL,
+	receive_count INTEGER NOT NULL DEFAULT 0,
+	enqueued_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	visible_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_queue_messages_visible ON queue_messages (queue_name, visible_at, id)`
+
+// Receiving takes the oldest visible message and hides it for the visibility timeout. SKIP LOCKED
+// means receivers in other processes don't wait for each other or get the same message.
+const constReceiveQueueMessage = `UPDATE queue_messages
+SET visible_at = now() + $2 * interval '1 second', receive_count = receive_count + 1
+WHERE id = (
+	SELECT id FROM queue_messages
+	WHERE queue_name = $1 AND visible_at <= now()
+	ORDER BY id
+	LIMIT 1
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING id, body, receive_count`
+
+// PostgresService for sending and receiving messages using a table in the database. Messages
+// survive restarts, and any number of processes can share the queues.
+type PostgresService struct {
+	backendQueue
+}
+
+// make sure it satisfies the interface
+var _ Queue = (*PostgresService)(nil)
+
+// NewPostgresService creates the queue table if it's not already there.
+func NewPostgresService(db *sql.DB) PostgresService {
+	_, err := db.Exec(constCreateQueueTable)
+	if err != nil {
+		log.Errorf("Unable to create queue table: %v", err)
+	}
+
+	return PostgresService{newBackendQueue(&postgresBackend{
+		db:                db,
+		visibilityTimeout: getVisibilityTimeout(),
+	}, getQueueName)}
+}
+
+type postgresBackend struct {
+	db                *sql.DB
+	visibilityTimeout time.Duration
+}
+
+func (b *postgresBackend) send(queueName string, body []byte) error {
+	_, err := b.db.Exec("INSERT INTO queue_messages (queue_name, body) VALUES ($1, $2)", queueName, string(body))
+	return err
+}
+
+func (b *postgresBackend) receive(queueName string) (body []byte, receiptHandle string, receiveCount int, err error) {
+	deadline := time.Now().Add(constReceiveWait)
+
+	for {
+		var id uint64
+		var s string
+
+		err = b.db.QueryRow(constReceiveQueueMessage, queueName, b.visibilityTimeout.Seconds()).Scan(&id, &s, &receiveCount)
+		if err == nil {
+			return []byte(s), formatReceiptHandle(id, receiveCount), receiveCount, nil
+		}
+
+		if err != sql.ErrNoRows {
+			return nil, "", 0, err
+		}
+
+		if time.Now().Add(constPostgresPollInterval).After(deadline) {
+			return nil, "", 0, nil
+		}
+
+		time.Sleep(constPostgresPollInterval)
+	}
+}
+
+// delete the message for good. The receive count has to match, so we can't delete a message
+// that has been received by someone else after the visibility timeout.
+func (b *postgresBackend) delete(queueName string, receiptHandle string) error {
+	id, receiveCount, err := parseReceiptHandle(receiptHandle)
+	if err != nil {
+		return err
+	}
+
+	res, err := b.db.Exec("DELETE FROM queue_messages WHERE id = $1 AND queue_name = $2 AND receive_count = $3",
+		id, queueName, receiveCount)
+
+	return checkReceiptHandle(res, err, queueName, receiptHandle)
+}
+
+// release the message so it can be received again after the delay
+func (b *postgresBackend) release(queueName string, receiptHandle string, delay time.Duration) error {
+	id, receiveCount, err := parseReceiptHandle(receiptHandle)
+	if err != nil {
+		return err
+	}
+
+	res, err := b.db.Exec("UPDATE queue_messages SET visible_at = now() + $4 * interval '1 second' WHERE id = $1 AND queue_name = $2 AND receive_count = $3",
+		id, queueName, receiveCount, delay.Seconds())
+
+	return checkReceiptHandle(res, err, queueName, receiptHandle)
+}
+
+// checkReceiptHandle returns an error if the statement didn't find the message
+func checkReceiptHandle(res sql.Result, err error, queueName string, receiptHandle string) error {
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("Receipt handle %s is not valid for queue %s", receiptHandle, queueName)
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+// +build dbrequired
+
+package queue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/microscaling/microbadger/database"
+)
+
+// Setting up test database for this package
+// $ psql -c 'create database microbadger_queue_test;' -U postgres
+
+func getTestPostgresService(t *testing.T, visibilityTimeout time.Duration) ServiceAdapter {
+	db, err := database.GetPostgres("localhost", "postgres", "microbadger_queue_test", "", false)
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+
+	q := NewPostgresService(db.SQL())
+	q.backend.(*postgresBackend).visibilityTimeout = visibilityTimeout
+
+	db.Exec("DELETE FROM queue_messages")
+	return NewServiceAdapter(q, TopicImage, TopicImage)
+}
+
+func TestPostgresImages(t *testing.T) {
+	q := getTestPostgresService(t, time.Minute)
+
+	q.SendImage("lizrice/featured", "Sent")
+	q.SendImage("microscaling/microscaling", "Sent")
+
+	first := q.ReceiveImage()
+	second := q.ReceiveImage()
+	if first == nil || first.ImageName != "lizrice/featured" || second == nil || second.ImageName != "microscaling/microscaling" {
+		t.Fatalf("Images received out of order: %v %v", first, second)
+	}
+
+	// Both are hidden until they're deleted or the visibility timeout
+	if img := q.ReceiveImage(); img != nil {
+		t.Errorf("Unexpected image %s", img.ImageName)
+	}
+
+	err := q.DeleteImage(first)
+	if err != nil {
+		t.Errorf("Error deleting image: %v", err)
+	}
+
+	err = q.DeleteImage(first)
+	if err == nil {
+		t.Errorf("Expected an error deleting twice")
+	}
+
+	err = q.ReleaseImage(second)
+	if err != nil {
+		t.Errorf("Error releasing image: %v", err)
+	}
+
+	img := q.ReceiveImage()
+	if img == nil || img.ImageName != "microscaling/microscaling" {
+		t.Fatalf("Expected the released image, got %v", img)
+	}
+	q.DeleteImage(img)
+}
+
+func TestPostgresVisibilityTimeout(t *testing.T) {
+	q := getTestPostgresService(t, time.Second)
+
+	q.SendNotification(42)
+	first := q.ReceiveNotification()
+	if first == nil || first.NotificationID != 42 {
+		t.Fatalf("Expected notification 42, got %v", first)
+	}
+
+	// Not deleted in time so it's received again
+	second := q.ReceiveNotification()
+	if second == nil || second.NotificationID != 42 {
+		t.Fatalf("Expected notification 42 again, got %v", second)
+	}
+
+	err := q.DeleteNotification(first)
+	if err == nil {
+		t.Errorf("Expected an error deleting with an expired receipt handle")
+	}
+
+	err = q.DeleteNotification(second)
+	if err != nil {
+		t.Errorf("Error deleting notification: %v", err)
+	}
+}
@@ -1,27 +1,84 @@
 package queue
 
-// TODO!! Find a better way of structuring this. Split of responsiblities between here and sender/receivers doesn't seem right
-// and I don't like the asymmetry of messages we pass in and out of queues, this all seems a bit long-winded.
-// Also, tests.
+import (
+	"database/sql"
+	"os"
+	"strings"
+	"time"
+)
+
+// Topic is a stream of one type of message, such as images waiting to be inspected. Each
+// queue implementation maps topics to its own queues, configured from the environment.
+type Topic string
+
+const (
+	// TopicImage has images for the inspector
+	TopicImage Topic = "image"
+	// TopicSize has images for the size inspector
+	TopicSize Topic = "size"
+	// TopicNotify has notifications for the notifier
+	TopicNotify Topic = "notify"
+)
+
+// envName is the topic as used in environment variable names e.g. MB_IMAGE_QUEUE_NAME
+func (t Topic) envName() string {
+	return strings.ToUpper(strings.Replace(string(t), "-", "_", -1))
+}
+
+// Queue sends and receives messages on topics. Received messages are delivered again if
+// they aren't acked, and Nack makes them available to receive again after a delay.
+type Queue interface {
+	Publish(env *Envelope) error
+	// Receive returns nil if there are no messages
+	Receive(topic Topic) (*Envelope, error)
+	Ack(env *Envelope) error
+	Nack(env *Envelope, delay time.Duration) error
+}
+
+// NewService creates the queue for MB_QUEUE_TYPE, which can be sqs (the default), nats,
+// memory or postgres, and sends and receives images on it on the topics. The database is
+// only used by the postgres queue.
+func NewService(db *sql.DB, imageSendTopic Topic, imageReceiveTopic Topic) ServiceAdapter {
+	var q Queue
+	switch os.Getenv("MB_QUEUE_TYPE") {
+	case "nats":
+		q = NewNatsService()
+	case "memory":
+		q = NewMemoryService()
+	case "postgres":
+		q = NewPostgresService(db)
+	default:
+		q = NewSqsService(imageSendTopic, imageReceiveTopic)
+	}
+
+	return NewServiceAdapter(q, imageSendTopic, imageReceiveTopic)
+}
 
 // ImageQueueMessage is sent to the queue to trigger an inspection.
 type ImageQueueMessage struct {
 	ImageName     string `json:"ImageName"`
 	ReceiptHandle *string
+
+	envelope *Envelope
 }
 
 // NotificationQueueMessage is sent to the queue to trigger a notification.
 type NotificationQueueMessage struct {
 	NotificationID uint `json:"NotificationID"`
 	ReceiptHandle  *string
+
+	envelope *Envelope
 }
 
-// Service interface so we can mock it out for tests
-// TODO!! There must be a cleaner way that doesn't involve a different set of methods for each type of message
+// Service has methods for each type of message. ServiceAdapter implements it on a Queue
+// for the inspectors and the notifier.
 type Service interface {
 	SendImage(imageName string, state string) (err error)
+	SendImageFrom(parent *ImageQueueMessage, state string) (err error)
 	ReceiveImage() *ImageQueueMessage
 	DeleteImage(img *ImageQueueMessage) error
+	ReleaseImage(img *ImageQueueMessage) error
+	RetryImage(img *ImageQueueMessage, delay time.Duration) error
 	SendNotification(notificationID uint) error
 	ReceiveNotification() *NotificationQueueMessage
 	DeleteNotification(notify *NotificationQueueMessage) error
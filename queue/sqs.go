@@ -1,9 +1,9 @@
 package queue
 
 import (
-	"encoding/json"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/op/go-logging"
 
@@ -15,183 +15,74 @@ import (
 const (
 	constImagePagePath    = "/images/"
 	constSentStateMessage = "Sent"
+
+	constSqsMaxVisibilityTimeout = 12 * time.Hour
 )
 
 var (
 	log = logging.MustGetLogger("mmqueue")
 )
 
-// SqsService for sending and receiving messages on SQS
+// SqsService for sending and receiving messages on SQS. The queue URL for each topic is
+// configured with SQS_<TOPIC>_QUEUE_URL e.g. SQS_IMAGE_QUEUE_URL.
+//
+// Before topics each process had SQS_SEND_QUEUE_URL and SQS_RECEIVE_QUEUE_URL for images,
+// so these are still used for the image send & receive topics if the topic's URL isn't set.
+// See the README for the order to upgrade in.
 type SqsService struct {
-	svc *sqs.SQS
-	// Image send & receive queues are different for inspector & size processes,
-	// so that's why we need both send & receive
-	imageSendQueueURL    string
-	imageReceiveQueueURL string
-	notificationQueueURL string
+	backendQueue
 }
 
 // make sure it satisfies the interface
-var _ Service = (*SqsService)(nil)
-
-// NewSqsService opens a new session with SQS
-func NewSqsService() SqsService {
-	svc := sqs.New(session.New())
-	receiveQueueURL := os.Getenv("SQS_RECEIVE_QUEUE_URL")
-
-	// Enable long polling of up to 5 seconds on the queue we're receiving on
-	_, err := svc.SetQueueAttributes(&sqs.SetQueueAttributesInput{
-		QueueUrl: aws.String(receiveQueueURL),
-		Attributes: aws.StringMap(map[string]string{
-			"ReceiveMessageWaitTimeSeconds": strconv.Itoa(5),
-		}),
-	})
-	if err != nil {
-		log.Errorf("Unable to update queue at %s: %v", receiveQueueURL, err)
-	}
+var _ Queue = (*SqsService)(nil)
 
-	return SqsService{
-		svc:                  svc,
-		imageSendQueueURL:    os.Getenv("SQS_SEND_QUEUE_URL"),
-		imageReceiveQueueURL: receiveQueueURL,
-		notificationQueueURL: os.Getenv("SQS_NOTIFY_QUEUE_URL"),
-	}
+// NewSqsService opens a new session with SQS. The image topics are only needed for the
+// deprecated queue URL settings.
+func NewSqsService(imageSendTopic Topic, imageReceiveTopic Topic) SqsService {
+	queueURL := getSqsQueueURLs(imageSendTopic, imageReceiveTopic)
+	return SqsService{newBackendQueue(&sqsBackend{svc: sqs.New(session.New())}, queueURL)}
 }
 
-// SendImage to the SQS queue for processing by the Inspector.
-func (q SqsService) SendImage(imageName string, state string) (err error) {
-	log.Debugf("Sending image %s to queue", imageName)
-
-	msg := ImageQueueMessage{
-		ImageName: imageName,
+// getSqsQueueURLs looks up the queue URL for each topic, falling back to SQS_SEND_QUEUE_URL
+// and SQS_RECEIVE_QUEUE_URL for the image topics
+func getSqsQueueURLs(imageSendTopic Topic, imageReceiveTopic Topic) func(topic Topic) string {
+	deprecated := map[Topic]string{}
+	if imageSendTopic != "" {
+		deprecated[imageSendTopic] = "SQS_SEND_QUEUE_URL"
 	}
-
-	bytes, err := json.Marshal(msg)
-	if err != nil {
-		log.Errorf("Error: %v", err)
-		return err
+	if imageReceiveTopic != "" {
+		deprecated[imageReceiveTopic] = "SQS_RECEIVE_QUEUE_URL"
 	}
 
-	err = q.sqsSend(q.imageSendQueueURL, string(bytes))
-	if err != nil {
-		log.Errorf("Failed to send image %s: %v", imageName, err)
-		return
-	}
-
-	log.Infof("%s image %s to queue", state, imageName)
-	return err
-}
-
-// ReceiveImage from the SQS queue for processing by the inspector.
-func (q SqsService) ReceiveImage() *ImageQueueMessage {
-	log.Debugf("Receiving on queue %s", q.imageReceiveQueueURL)
+	urls := map[Topic]string{}
+	for _, topic := range []Topic{TopicImage, TopicSize, TopicNotify} {
+		name := "SQS_" + topic.envName() + "_QUEUE_URL"
+		urls[topic] = os.Getenv(name)
 
-	msg, err := q.sqsReceive(q.imageReceiveQueueURL)
-	if err != nil {
-		log.Errorf("Error receiving image from queue: %v", err)
-		return nil
-	}
-
-	if msg != nil {
-		var img ImageQueueMessage
-
-		err = json.Unmarshal([]byte(*msg.Body), &img)
-		if err != nil {
-			log.Errorf("Error unmarshaling from %v, error is %v", img, err)
-			return nil
+		if old, ok := deprecated[topic]; ok && urls[topic] == "" && os.Getenv(old) != "" {
+			log.Warningf("%s is deprecated, set %s instead", old, name)
+			urls[topic] = os.Getenv(old)
 		}
-
-		log.Infof("Received image %s from queue.", img.ImageName)
-
-		img.ReceiptHandle = msg.ReceiptHandle
-
-		return &img
 	}
 
-	return nil
-}
-
-// DeleteImage from the queue once it has successfully been inspected.
-func (q SqsService) DeleteImage(img *ImageQueueMessage) error {
-	log.Debugf("Deleting image %s from queue.", img.ImageName)
-
-	err := q.sqsDelete(q.imageReceiveQueueURL, img.ReceiptHandle)
-	if err == nil {
-		log.Infof("Deleted image %s from queue.", img.ImageName)
+	return func(topic Topic) string {
+		return urls[topic]
 	}
-
-	return err
 }
 
-// SendNotification  to the SQS queue for processing by the Notifier.
-func (q SqsService) SendNotification(notificationID uint) (err error) {
-	log.Debugf("Sending notification %s to queue", notificationID)
-
-	msg := NotificationQueueMessage{
-		NotificationID: notificationID,
-	}
-
-	bytes, err := json.Marshal(msg)
-	if err != nil {
-		log.Errorf("Error: %v", err)
-		return err
-	}
-
-	q.sqsSend(q.notificationQueueURL, string(bytes))
-
-	return err
-}
-
-// ReceiveNotification from the SQS queue for sending by the notifier
-func (q SqsService) ReceiveNotification() *NotificationQueueMessage {
-	log.Debug("Checking queue for notification messages.")
-
-	msg, err := q.sqsReceive(q.notificationQueueURL)
-	if err != nil {
-		log.Errorf("Error receiving notification from queue: %v", err)
-		return nil
-	}
-
-	if msg != nil {
-		var notification NotificationQueueMessage
-
-		err = json.Unmarshal([]byte(*msg.Body), &notification)
-		if err != nil {
-			log.Errorf("Error unmarshaling from %v, error is %v", notification, err)
-			return nil
-		}
-
-		log.Infof("Received message for notification %d from queue.", notification.NotificationID)
-
-		notification.ReceiptHandle = msg.ReceiptHandle
-
-		return &notification
-	}
-
-	return nil
-}
-
-// DeleteNotification from the queue once it has been sent.
-func (q SqsService) DeleteNotification(notification *NotificationQueueMessage) error {
-	log.Debugf("Deleting message %s from queue.", notification.NotificationID)
-
-	err := q.sqsDelete(q.notificationQueueURL, notification.ReceiptHandle)
-	if err == nil {
-		log.Infof("Deleted message %s from queue.", notification.NotificationID)
-	}
-
-	return err
+type sqsBackend struct {
+	svc *sqs.SQS
 }
 
-func (q SqsService) sqsSend(queueURL string, message string) (err error) {
+func (b *sqsBackend) send(queueURL string, body []byte) (err error) {
 	log.Debugf("Sending on queue %s", queueURL)
 
 	params := &sqs.SendMessageInput{
-		MessageBody: aws.String(message),
+		MessageBody: aws.String(string(body)),
 		QueueUrl:    aws.String(queueURL),
 	}
 
-	_, err = q.svc.SendMessage(params)
+	_, err = b.svc.SendMessage(params)
 	if err != nil {
 		log.Errorf("SQS send Error: %v", err)
 	}
@@ -199,35 +90,59 @@ func (q SqsService) sqsSend(queueURL string, message string) (err error) {
 	return err
 }
 
-func (q SqsService) sqsReceive(queueURL string) (msg *sqs.Message, err error) {
+// receive long polls for up to 5 seconds
+func (b *sqsBackend) receive(queueURL string) (body []byte, receiptHandle string, receiveCount int, err error) {
 	params := &sqs.ReceiveMessageInput{
 		QueueUrl:            aws.String(queueURL),
 		MaxNumberOfMessages: aws.Int64(1),
 		WaitTimeSeconds:     aws.Int64(5),
+		AttributeNames:      aws.StringSlice([]string{sqs.MessageSystemAttributeNameApproximateReceiveCount}),
 	}
 
-	resp, err := q.svc.ReceiveMessage(params)
+	resp, err := b.svc.ReceiveMessage(params)
 	if err != nil {
 		log.Errorf("SQS receive error: %v", err)
 		return
 	}
 
-	if len(resp.Messages) > 0 {
-		msg = resp.Messages[0]
+	if len(resp.Messages) == 0 {
+		return
 	}
 
-	return
+	msg := resp.Messages[0]
+	receiveCount, _ = strconv.Atoi(aws.StringValue(msg.Attributes[sqs.MessageSystemAttributeNameApproximateReceiveCount]))
+	return []byte(aws.StringValue(msg.Body)), aws.StringValue(msg.ReceiptHandle), receiveCount, nil
 }
 
-func (q SqsService) sqsDelete(queueURL string, receiptHandle *string) error {
+func (b *sqsBackend) delete(queueURL string, receiptHandle string) error {
 	params := &sqs.DeleteMessageInput{
 		QueueUrl:      aws.String(queueURL),
-		ReceiptHandle: aws.String(*receiptHandle),
+		ReceiptHandle: aws.String(receiptHandle),
 	}
 
-	_, err := q.svc.DeleteMessage(params)
+	_, err := b.svc.DeleteMessage(params)
 	if err != nil {
 		log.Errorf("Error deleting SQS message: %v", err)
 	}
 	return err
 }
+
+// release makes the message visible again after the delay
+func (b *sqsBackend) release(queueURL string, receiptHandle string, delay time.Duration) error {
+	// SQS doesn't allow more than 12 hours
+	if delay > constSqsMaxVisibilityTimeout {
+		delay = constSqsMaxVisibilityTimeout
+	}
+
+	params := &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(queueURL),
+		ReceiptHandle:     aws.String(receiptHandle),
+		VisibilityTimeout: aws.Int64(int64(delay.Seconds())),
+	}
+
+	_, err := b.svc.ChangeMessageVisibility(params)
+	if err != nil {
+		log.Errorf("Error releasing SQS message: %v", err)
+	}
+	return err
+}
@@ -0,0 +1,41 @@
+package queue
+
+import (
+	"testing"
+)
+
+func TestGetSqsQueueURLs(t *testing.T) {
+	t.Setenv("SQS_IMAGE_QUEUE_URL", "")
+	t.Setenv("SQS_SIZE_QUEUE_URL", "")
+	t.Setenv("SQS_NOTIFY_QUEUE_URL", "https://sqs/notify")
+	t.Setenv("SQS_SEND_QUEUE_URL", "https://sqs/send")
+	t.Setenv("SQS_RECEIVE_QUEUE_URL", "https://sqs/receive")
+
+	var tests = []struct {
+		send    Topic
+		receive Topic
+		urls    map[Topic]string
+	}{
+		// api & scheduler
+		{send: TopicImage, urls: map[Topic]string{TopicImage: "https://sqs/send", TopicSize: "", TopicNotify: "https://sqs/notify"}},
+		// inspector
+		{send: TopicSize, receive: TopicImage, urls: map[Topic]string{TopicImage: "https://sqs/receive", TopicSize: "https://sqs/send", TopicNotify: "https://sqs/notify"}},
+		// size inspector
+		{receive: TopicSize, urls: map[Topic]string{TopicImage: "", TopicSize: "https://sqs/receive", TopicNotify: "https://sqs/notify"}},
+	}
+
+	for _, test := range tests {
+		queueURL := getSqsQueueURLs(test.send, test.receive)
+		for topic, url := range test.urls {
+			if queueURL(topic) != url {
+				t.Errorf("Sending %s and receiving %s, expected %s for %s, got %s", test.send, test.receive, url, topic, queueURL(topic))
+			}
+		}
+	}
+
+	// The topic's own URL is used if it's set
+	t.Setenv("SQS_IMAGE_QUEUE_URL", "https://sqs/image")
+	if url := getSqsQueueURLs(TopicImage, "")(TopicImage); url != "https://sqs/image" {
+		t.Errorf("Expected the image queue URL, got %s", url)
+	}
+}
@@ -0,0 +1,139 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	constMaxRetries       = 5
+	constBaseBackoff      = 1 * time.Second
+	constMaxBackoff       = 60 * time.Second
+	constMaxRateLimitWait = 5 * time.Minute
+)
+
+// ErrRateLimited is returned when the registry is rate limiting us and waiting for it would take too long
+var ErrRateLimited = errors.New("Rate limited")
+
+// rateLimiter is shared by all the clients for a registry so they back off together
+type rateLimiter struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+// wait blocks until the registry stops rate limiting us. If that would take too long
+// we fail straight away rather than tying up the inspector.
+func (l *rateLimiter) wait(maxWait time.Duration) error {
+	l.mu.Lock()
+	delay := time.Until(l.until)
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	if delay > maxWait {
+		return fmt.Errorf("%w for another %v", ErrRateLimited, delay.Round(time.Second))
+	}
+
+	log.Infof("Rate limited, waiting %v", delay)
+	time.Sleep(delay)
+	return nil
+}
+
+// backoff stops requests until the delay has passed. An existing longer delay is kept.
+func (l *rateLimiter) backoff(delay time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	until := time.Now().Add(delay)
+	if until.After(l.until) {
+		l.until = until
+	}
+}
+
+// update backs off until the quota resets if the response says there are no requests remaining
+func (l *rateLimiter) update(h http.Header) {
+	remaining, ok := parseRateLimitHeader(h.Get("RateLimit-Remaining"))
+	if !ok || remaining > 0 {
+		return
+	}
+
+	if reset, ok := parseRateLimitHeader(h.Get("RateLimit-Reset")); ok {
+		log.Infof("No requests remaining, waiting %d seconds for reset", reset)
+		l.backoff(time.Duration(reset) * time.Second)
+	}
+}
+
+// getRetryDelay works out how long to wait before retrying after a 429. We use the Retry-After
+// or RateLimit-Reset headers if the registry sends them, otherwise exponential backoff.
+func getRetryDelay(h http.Header, retries int, base time.Duration, max time.Duration) time.Duration {
+	if delay, ok := parseRetryAfter(h.Get("Retry-After")); ok {
+		return delay
+	}
+
+	if reset, ok := parseRateLimitHeader(h.Get("RateLimit-Reset")); ok {
+		return time.Duration(reset) * time.Second
+	}
+
+	return getBackoff(retries, base, max)
+}
+
+// getBackoff doubles the delay for each retry. Half of it is random so that clients that
+// were rate limited at the same time don't all retry together.
+func getBackoff(retries int, base time.Duration, max time.Duration) time.Duration {
+	delay := base << uint(retries)
+	if delay > max || delay <= 0 {
+		delay = max
+	}
+
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+
+	return half + time.Duration(rand.Int63n(int64(half)))
+}
+
+// Retry-After is either a number of seconds or an HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		delay := time.Until(t)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+
+	return 0, false
+}
+
+// The RateLimit headers can include the quota window, e.g. Docker Hub sends RateLimit-Remaining: 76;w=21600
+func parseRateLimitHeader(value string) (int, bool) {
+	value = strings.TrimSpace(strings.SplitN(value, ";", 2)[0])
+	if value == "" {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+
+	return n, true
+}
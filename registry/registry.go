@@ -1,10 +1,13 @@
 package registry
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"sync"
@@ -19,40 +22,163 @@ const registryURL = "https://registry.hub.docker.com"
 const authURL = "https://auth.docker.io"
 const serviceURL = "registry.docker.io"
 
+// Layer blobs can take much longer to download than the client timeout, so instead they
+// fail if the registry stops sending data for this long
+const constBlobIdleTimeout = 30 * time.Second
+
+// Media types for the manifest formats we can inspect.
+const (
+	MediaTypeManifestV1       = "application/vnd.docker.distribution.manifest.v1+json"
+	MediaTypeSignedManifestV1 = "application/vnd.docker.distribution.manifest.v1+prettyjws"
+	MediaTypeManifestV2       = "application/vnd.docker.distribution.manifest.v2+json"
+	MediaTypeOCIManifest      = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeManifestList     = "application/vnd.docker.distribution.manifest.list.v2+json"
+	MediaTypeOCIIndex         = "application/vnd.oci.image.index.v1+json"
+)
+
+// Schema 2 and OCI manifests are preferred, but we still accept schema 1 for
+// images that were pushed with old versions of Docker. Multi-architecture
+// images return a manifest list or OCI index.
+var manifestMediaTypes = []string{
+	MediaTypeManifestList,
+	MediaTypeOCIIndex,
+	MediaTypeManifestV2,
+	MediaTypeOCIManifest,
+	MediaTypeSignedManifestV1,
+	MediaTypeManifestV1,
+}
+
 var log = logging.MustGetLogger("mminspect")
 
-// Service connects to the Docker Hub over the internet
+// Registry is a registry that supports the Docker Registry HTTP API V2. If AuthURL isn't
+// set the token realm and service are discovered from the WWW-Authenticate challenge.
+type Registry struct {
+	Host    string
+	URL     string
+	AuthURL string
+	Service string
+
+	discovered bool
+	basicAuth  bool
+	limiter    *rateLimiter
+}
+
+// Service connects to the Docker Hub and any other registries over the internet
 type Service struct {
-	client      *http.Client
-	authURL     string
-	serviceURL  string
-	registryURL string
+	client     *http.Client
+	blobClient *http.Client
+	registries map[string]Registry
+	rm         *sync.RWMutex
+	tokens     *tokenCache
+	loader     func(host string) (Registry, bool)
+
+	maxRetries       int
+	baseBackoff      time.Duration
+	maxBackoff       time.Duration
+	maxRateLimitWait time.Duration
+	blobIdleTimeout  time.Duration
 }
 
 // NewService is a real info service
 func NewService() Service {
-	return Service{
-		client: &http.Client{
-			// TODO Make timeout configurable.
-			Timeout: 10 * time.Second,
-		},
-		authURL:     authURL,
-		registryURL: registryURL,
-		serviceURL:  serviceURL,
-	}
+	rs := newService(&http.Client{
+		// TODO Make timeout configurable.
+		Timeout: 10 * time.Second,
+	}, authURL, registryURL, serviceURL)
+
+	// No overall timeout for blobs, but we still don't wait forever for the registry to respond
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = 10 * time.Second
+	rs.blobClient = &http.Client{Transport: transport}
+
+	return rs
 }
 
-// NewMockService is for testing
+// NewMockService is for testing. It backs off for milliseconds rather than seconds.
 func NewMockService(transport *http.Transport, aurl string, rurl string, surl string) Service {
+	rs := newService(&http.Client{
+		Transport: transport,
+	}, aurl, rurl, surl)
+
+	rs.baseBackoff = 10 * time.Millisecond
+	rs.maxBackoff = 100 * time.Millisecond
+	rs.maxRateLimitWait = time.Second
+	rs.blobIdleTimeout = 100 * time.Millisecond
+
+	return rs
+}
+
+func newService(client *http.Client, aurl string, rurl string, surl string) Service {
+	rs := Service{
+		client:     client,
+		blobClient: &http.Client{Transport: client.Transport},
+		registries: make(map[string]Registry),
+		rm:         &sync.RWMutex{},
+		tokens:     newTokenCache(),
+
+		maxRetries:       constMaxRetries,
+		baseBackoff:      constBaseBackoff,
+		maxBackoff:       constMaxBackoff,
+		maxRateLimitWait: constMaxRateLimitWait,
+		blobIdleTimeout:  constBlobIdleTimeout,
+	}
+
+	rs.AddRegistry(Registry{
+		Host:    utils.DockerHubHost,
+		URL:     rurl,
+		AuthURL: aurl + "/token",
+		Service: surl,
+	})
+
+	return rs
+}
+
+// AddRegistry adds or replaces the registry for a host
+func (rs *Service) AddRegistry(r Registry) {
+	rs.rm.Lock()
+	defer rs.rm.Unlock()
+
+	// Keep backing off if we're replacing a registry that is rate limiting us
+	if existing, ok := rs.registries[r.Host]; ok {
+		r.limiter = existing.limiter
+	} else {
+		r.limiter = &rateLimiter{}
+	}
+
+	r.URL = strings.TrimSuffix(r.URL, "/")
+	if r.URL == "" {
+		r.URL = "https://" + r.Host
+	}
+
+	rs.registries[r.Host] = r
+}
 
-	return Service{
-		client: &http.Client{
-			Transport: transport,
-		},
-		authURL:     aurl,
-		registryURL: rurl,
-		serviceURL:  surl,
+// SetRegistryLoader sets how to find registries that weren't added when we started
+func (rs *Service) SetRegistryLoader(loader func(host string) (Registry, bool)) {
+	rs.loader = loader
+}
+
+func (rs *Service) getRegistry(host string) (r Registry, ok bool) {
+	rs.rm.RLock()
+	r, ok = rs.registries[host]
+	rs.rm.RUnlock()
+
+	if ok || rs.loader == nil {
+		return
+	}
+
+	r, ok = rs.loader(host)
+	if !ok {
+		return
 	}
+
+	rs.AddRegistry(r)
+
+	rs.rm.RLock()
+	defer rs.rm.RUnlock()
+
+	r, ok = rs.registries[host]
+	return
 }
 
 type Image struct {
@@ -81,6 +207,8 @@ type V1Compatibility struct {
 	ContainerConfig containerConfig `json:"container_config,omitempty"`
 	Created         string          `json:"created,omitempty"`
 	Author          string          `json:"author,omitempty"`
+	Architecture    string          `json:"architecture,omitempty"`
+	OS              string          `json:"os,omitempty"`
 }
 
 type registryHistory struct {
@@ -91,59 +219,259 @@ type registryFsLayers struct {
 	BlobSum string `json:"blobSum"`
 }
 
+// Platform is the architecture and OS of an image in a manifest list.
+type Platform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// Descriptor points to a blob in the registry from a schema 2 or OCI manifest,
+// or to a platform manifest from a manifest list or OCI index.
+type Descriptor struct {
+	MediaType string    `json:"mediaType,omitempty"`
+	Size      int64     `json:"size"`
+	Digest    string    `json:"digest"`
+	Platform  *Platform `json:"platform,omitempty"`
+}
+
+// Manifest can hold a schema 1, schema 2 or OCI image manifest. Schema 1
+// manifests use History and FsLayers, the others use Config and Layers.
+// Manifest lists and OCI indexes only have Manifests.
 type Manifest struct {
 	SchemaVersion int                `json:"schemaVersion"`
-	Name          string             `json:"name"`
-	History       []registryHistory  `json:"history"`
-	FsLayers      []registryFsLayers `json:"fsLayers"`
+	MediaType     string             `json:"mediaType,omitempty"`
+	Name          string             `json:"name,omitempty"`
+	History       []registryHistory  `json:"history,omitempty"`
+	FsLayers      []registryFsLayers `json:"fsLayers,omitempty"`
+	Config        *Descriptor        `json:"config,omitempty"`
+	Layers        []Descriptor       `json:"layers,omitempty"`
+	Manifests     []Descriptor       `json:"manifests,omitempty"`
 }
 
-// DockerAuth is returned by the Docker Auth API.
-type dockerAuth struct {
-	Token string `json:"token"`
+// IsList is true for manifest lists and OCI indexes, which point to a manifest for each platform.
+func (m Manifest) IsList() bool {
+	return m.MediaType == MediaTypeManifestList || m.MediaType == MediaTypeOCIIndex || len(m.Manifests) > 0
 }
 
-// TokenAuthClient is associated with a particular org / image
-type TokenAuthClient struct {
-	org      string
-	image    string
-	user     string
-	password string
-	token    string
-	tokenURL string
+// HasConfig is true for schema 2 and OCI manifests, where the image metadata
+// is held in a separate config blob.
+func (m Manifest) HasConfig() bool {
+	return m.SchemaVersion == 2 && m.Config != nil && m.Config.Digest != ""
+}
+
+// ConfigHistory is an entry in the history of an image config blob.
+type ConfigHistory struct {
+	Created    string `json:"created,omitempty"`
+	CreatedBy  string `json:"created_by,omitempty"`
+	Author     string `json:"author,omitempty"`
+	Comment    string `json:"comment,omitempty"`
+	EmptyLayer bool   `json:"empty_layer,omitempty"`
+}
+
+// ImageConfig is the config blob referenced by a schema 2 or OCI manifest.
+type ImageConfig struct {
+	Architecture string          `json:"architecture,omitempty"`
+	OS           string          `json:"os,omitempty"`
+	Variant      string          `json:"variant,omitempty"`
+	Created      string          `json:"created,omitempty"`
+	Author       string          `json:"author,omitempty"`
+	Config       registryConfig  `json:"config,omitempty"`
+	History      []ConfigHistory `json:"history,omitempty"`
+}
 
-	service *Service
+// DockerAuth is returned by the Docker Auth API. Some registries use access_token
+// as in OAuth 2.0 rather than token.
+type dockerAuth struct {
+	Token       string `json:"token"`
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+	IssuedAt    string `json:"issued_at"`
+}
 
-	rl             sync.RWMutex
-	rateLimited    bool
-	rateLimitDelay int
+// TokenAuthClient is associated with a particular repository in a registry. It can
+// be shared by goroutines fetching manifests for the same image.
+type TokenAuthClient struct {
+	repository string
+	user       string
+	password   string
+	tokenURL   string
+	tokenKey   string
+
+	tm        sync.RWMutex
+	token     string
+	refreshAt time.Time
+	fetch     sync.Mutex
+
+	service  *Service
+	registry Registry
 }
 
 func NewTokenAuth(i Image, rs *Service) (t *TokenAuthClient, err error) {
-	org, image, _ := utils.ParseDockerImage(i.Name)
+	host, repository := utils.ParseImageName(i.Name)
+
+	reg, ok := rs.getRegistry(host)
+	if !ok {
+		err = fmt.Errorf("Registry %s is not supported", host)
+		return
+	}
 
 	t = &TokenAuthClient{
-		org:            org,
-		image:          image,
-		user:           i.User,
-		password:       i.Password,
-		rateLimitDelay: 10,
+		repository: repository,
+		user:       i.User,
+		password:   i.Password,
+		service:    rs,
+	}
+
+	if reg.AuthURL == "" && !reg.discovered {
+		reg, err = rs.discoverAuth(reg)
+		if err != nil {
+			log.Errorf("Failed to discover auth for registry %s: %v", host, err)
+			return t, err
+		}
 	}
 
-	t.tokenURL = fmt.Sprintf("%s/token?service=%s&scope=repository:%s/%s:pull", rs.authURL, rs.serviceURL, org, image)
-	t.service = rs
+	t.registry = reg
+
+	// Registries that allow anonymous access or use basic auth don't need a token
+	if reg.AuthURL == "" {
+		return t, nil
+	}
+
+	t.tokenURL = getTokenURL(reg, repository)
+	t.tokenKey = getTokenKey(reg.AuthURL, getScope(repository), i.User, i.Password)
 
 	err = t.getToken()
 	if err != nil {
-		log.Errorf("Failed to get auth token for %s/%s", org, image)
+		log.Errorf("Failed to get auth token for %s", i.Name)
 	}
 
 	return t, err
 }
 
+// discoverAuth finds the token realm and service for a registry from the challenge it sends
+// for an unauthenticated request, and stores them so this only happens once per registry.
+func (rs *Service) discoverAuth(reg Registry) (Registry, error) {
+	pingURL := reg.URL + "/v2/"
+	log.Debugf("Discovering auth at URL %s", pingURL)
+
+	resp, err := rs.client.Get(pingURL)
+	if err != nil {
+		return reg, fmt.Errorf("Error pinging registry: %v", err)
+	}
+
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// Anonymous access so there's no auth needed
+	case http.StatusUnauthorized:
+		scheme, params := parseChallenge(resp.Header.Get("WWW-Authenticate"))
+		switch strings.ToLower(scheme) {
+		case "bearer":
+			if params["realm"] == "" {
+				return reg, fmt.Errorf("No realm in auth challenge")
+			}
+			reg.AuthURL = params["realm"]
+			reg.Service = params["service"]
+		case "basic":
+			reg.basicAuth = true
+		default:
+			return reg, fmt.Errorf("Unsupported auth challenge %s", scheme)
+		}
+	default:
+		return reg, fmt.Errorf("Unexpected response pinging registry: %d %s", resp.StatusCode, resp.Status)
+	}
+
+	reg.discovered = true
+
+	rs.rm.Lock()
+	rs.registries[reg.Host] = reg
+	rs.rm.Unlock()
+
+	return reg, nil
+}
+
+// parseChallenge parses a WWW-Authenticate header such as
+// Bearer realm="https://auth.docker.io/token",service="registry.docker.io"
+func parseChallenge(header string) (scheme string, params map[string]string) {
+	params = make(map[string]string)
+
+	header = strings.TrimSpace(header)
+	parts := strings.SplitN(header, " ", 2)
+	scheme = parts[0]
+	if len(parts) == 1 {
+		return
+	}
+
+	rest := parts[1]
+	for rest != "" {
+		rest = strings.TrimLeft(rest, " ,")
+		eq := strings.Index(rest, "=")
+		if eq < 0 {
+			return
+		}
+
+		key := strings.ToLower(strings.TrimSpace(rest[:eq]))
+		rest = rest[eq+1:]
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			// Quoted values can contain commas, e.g. in the scope
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				value, rest = rest[1:], ""
+			} else {
+				value, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			end := strings.Index(rest, ",")
+			if end < 0 {
+				value, rest = rest, ""
+			} else {
+				value, rest = rest[:end], rest[end+1:]
+			}
+		}
+
+		params[key] = strings.TrimSpace(value)
+	}
+
+	return
+}
+
+func getTokenURL(reg Registry, repository string) string {
+	sep := "?"
+	if strings.Contains(reg.AuthURL, "?") {
+		sep = "&"
+	}
+
+	tokenURL := reg.AuthURL + sep
+	if reg.Service != "" {
+		tokenURL += "service=" + url.QueryEscape(reg.Service) + "&"
+	}
+
+	return tokenURL + "scope=" + getScope(repository)
+}
+
+func getScope(repository string) string {
+	return fmt.Sprintf("repository:%s:pull", repository)
+}
+
+// getToken uses a cached token if there's one for this realm, scope and credentials,
+// otherwise it gets a new one from the auth server.
 func (t *TokenAuthClient) getToken() (err error) {
 	var auth dockerAuth
 
+	// Only one request at a time for each client gets a new token
+	t.fetch.Lock()
+	defer t.fetch.Unlock()
+
+	if ct, ok := t.service.tokens.get(t.tokenKey); ok {
+		log.Debug("Using cached auth token")
+		t.setToken(ct)
+		return
+	}
+
 	log.Debugf("Getting Auth Token URL for %s", t.tokenURL)
 
 	req, err := http.NewRequest("GET", t.tokenURL, nil)
@@ -167,56 +495,141 @@ func (t *TokenAuthClient) getToken() (err error) {
 
 	if resp.StatusCode != http.StatusOK {
 		log.Errorf("Error getting auth token %d: %s", resp.StatusCode, resp.Status)
+
+		// Don't ask again for every request - a 401 will still make us retry
+		t.setToken(cachedToken{refreshAt: time.Now().Add(constDefaultTokenExpiry)})
 		return
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		log.Errorf("Error reading auth token for image %s - %v", t.repository, err)
+		return
+	}
 
 	err = json.Unmarshal(body, &auth)
 	if err != nil {
-		log.Errorf("Error getting auth token for image %s/%s - %v", t.org, t.image, err)
+		log.Errorf("Error getting auth token for image %s - %v", t.repository, err)
 		return
 	}
 
 	log.Debug("Got new auth token")
-	t.token = auth.Token
+	ct := cachedToken{
+		token:     auth.Token,
+		refreshAt: getRefreshTime(auth.ExpiresIn, auth.IssuedAt, time.Now()),
+	}
+	if ct.token == "" {
+		ct.token = auth.AccessToken
+	}
+
+	t.service.tokens.put(t.tokenKey, ct)
+	t.setToken(ct)
 
 	return
 }
 
+func (t *TokenAuthClient) setToken(ct cachedToken) {
+	t.tm.Lock()
+	defer t.tm.Unlock()
+
+	t.token = ct.token
+	t.refreshAt = ct.refreshAt
+}
+
+// getCurrentToken refreshes the token if it's about to expire
+func (t *TokenAuthClient) getCurrentToken() string {
+	t.tm.RLock()
+	token, refreshAt := t.token, t.refreshAt
+	t.tm.RUnlock()
+
+	if t.tokenURL != "" && !time.Now().Before(refreshAt) {
+		log.Debug("Auth token is about to expire")
+		if err := t.getToken(); err != nil {
+			log.Errorf("Failed to refresh auth token for %s: %v", t.repository, err)
+			return token
+		}
+
+		t.tm.RLock()
+		token = t.token
+		t.tm.RUnlock()
+	}
+
+	return token
+}
+
 // ReqWithAuth sets the Authorization header on the request to use the provided token,
-func (t *TokenAuthClient) reqWithAuth(reqType string, reqURL string) (resp *http.Response, err error) {
-	req, err := http.NewRequest(reqType, reqURL, nil)
+// and sets the Accept header if any media types are given.
+func (t *TokenAuthClient) reqWithAuth(reqType string, reqURL string, accept ...string) (resp *http.Response, err error) {
+	return t.reqWithAuthClient(context.Background(), t.service.client, reqType, reqURL, accept...)
+}
+
+// reqWithAuthClient is reqWithAuth using the client and context, for requests that need
+// different timeouts
+func (t *TokenAuthClient) reqWithAuthClient(ctx context.Context, client *http.Client, reqType string, reqURL string, accept ...string) (resp *http.Response, err error) {
+	req, err := http.NewRequestWithContext(ctx, reqType, reqURL, nil)
 	if err != nil {
 		log.Errorf("Failed to build API GET request err %v", err)
 		return
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", t.token))
-
-	resp, err = t.service.client.Do(req)
-	if err != nil {
-		log.Errorf("Error sending request: %v", err)
-		return
+	if len(accept) > 0 {
+		req.Header.Set("Accept", strings.Join(accept, ", "))
 	}
 
-	if resp.StatusCode == http.StatusUnauthorized {
-		log.Debug("Unauthorized on first attempt")
+	rs := t.service
+	limiter := t.registry.limiter
+	reauthorized := false
+	retries := 0
 
-		// Perhaps this token has expired - try getting a new one and retrying the request
-		err = t.getToken()
+	for {
+		// Wait if we or any other client for this registry have been rate limited
+		err = limiter.wait(rs.maxRateLimitWait)
 		if err != nil {
-			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", t.token))
-			resp, err = t.service.client.Do(req)
-			if err != nil {
-				log.Errorf("Error sending request the second time: %v", err)
-				return
+			log.Infof("Not sending request to %s: %v", t.registry.Host, err)
+			return nil, err
+		}
+
+		t.setAuthorization(req)
+
+		resp, err = client.Do(req)
+		if err != nil {
+			log.Errorf("Error sending request: %v", err)
+			return
+		}
+
+		limiter.update(resp.Header)
+
+		if resp.StatusCode == http.StatusUnauthorized && t.tokenURL != "" && !reauthorized {
+			log.Debug("Unauthorized on first attempt")
+			reauthorized = true
+
+			// Perhaps this token has expired or been revoked - try getting a new one and retrying the request
+			t.service.tokens.delete(t.tokenKey)
+			t.setToken(cachedToken{})
+			if t.getToken() == nil {
+				resp.Body.Close()
+				continue
 			}
 		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && retries < rs.maxRetries {
+			delay := getRetryDelay(resp.Header, retries, rs.baseBackoff, rs.maxBackoff)
+			log.Infof("Rate limited by %s, retrying in %v", t.registry.Host, delay)
+
+			limiter.backoff(delay)
+			resp.Body.Close()
+			retries++
+			continue
+		}
+
+		break
 	}
 
-	if resp.StatusCode != http.StatusOK {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		log.Infof("Still rate limited by %s after %d retries", t.registry.Host, retries)
+		err = fmt.Errorf("Req failed: %d %s: %w", resp.StatusCode, resp.Status, ErrRateLimited)
+	} else if resp.StatusCode != http.StatusOK {
 		log.Debugf("Req failed: %d %s", resp.StatusCode, resp.Status)
 		err = fmt.Errorf("Req failed: %d %s", resp.StatusCode, resp.Status)
 	}
@@ -224,10 +637,19 @@ func (t *TokenAuthClient) reqWithAuth(reqType string, reqURL string) (resp *http
 	return
 }
 
+func (t *TokenAuthClient) setAuthorization(req *http.Request) {
+	token := t.getCurrentToken()
+	if token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	} else if t.registry.basicAuth && t.user != "" && t.password != "" {
+		req.SetBasicAuth(t.user, t.password)
+	}
+}
+
 func (t *TokenAuthClient) GetTags() (tags []string, err error) {
 	var tagList registryTagsList
 
-	tagsURL := fmt.Sprintf("%s/v2/%s/%s/tags/list", t.service.registryURL, t.org, t.image)
+	tagsURL := fmt.Sprintf("%s/v2/%s/tags/list", t.registry.URL, t.repository)
 	log.Debugf("Getting tags at URL %s", tagsURL)
 
 	resp, err := t.reqWithAuth("GET", tagsURL)
@@ -239,6 +661,11 @@ func (t *TokenAuthClient) GetTags() (tags []string, err error) {
 	defer resp.Body.Close()
 
 	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		log.Errorf("Error reading tags: %v", err)
+		return
+	}
+
 	err = json.Unmarshal(body, &tagList)
 	if err != nil {
 		log.Errorf("Error unmarshalling tags: %v", err)
@@ -250,12 +677,14 @@ func (t *TokenAuthClient) GetTags() (tags []string, err error) {
 	return
 }
 
-func (t *TokenAuthClient) GetManifest(tag string) (manifest Manifest, body []byte, err error) {
+// GetManifest gets the manifest for a tag or digest. For multi-architecture images this will
+// be a manifest list, and the platform manifests can be fetched using their digests.
+func (t *TokenAuthClient) GetManifest(reference string) (manifest Manifest, body []byte, err error) {
 
-	manifestURL := fmt.Sprintf("%s/v2/%s/%s/manifests/%s", t.service.registryURL, t.org, t.image, tag)
+	manifestURL := fmt.Sprintf("%s/v2/%s/manifests/%s", t.registry.URL, t.repository, reference)
 	log.Debugf("Getting manifest at URL %s", manifestURL)
 
-	resp, err := t.reqWithAuth("GET", manifestURL)
+	resp, err := t.reqWithAuth("GET", manifestURL, manifestMediaTypes...)
 	if err != nil {
 		log.Errorf("Failed to get manifest: %v", err)
 		return
@@ -264,17 +693,107 @@ func (t *TokenAuthClient) GetManifest(tag string) (manifest Manifest, body []byt
 	defer resp.Body.Close()
 
 	body, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		log.Errorf("Error reading manifest: %v", err)
+		return
+	}
+
 	err = json.Unmarshal(body, &manifest)
 	if err != nil {
 		log.Errorf("Error unmarshalling manifest: %v", err)
 		return
 	}
 
+	// OCI manifests don't have to include the media type so we use the response header
+	if manifest.MediaType == "" {
+		manifest.MediaType = resp.Header.Get("Content-Type")
+	}
+
+	return
+}
+
+// GetImageConfig gets the config blob for a schema 2 or OCI manifest. This holds
+// the labels and history that are in the v1Compatibility data for schema 1.
+func (t *TokenAuthClient) GetImageConfig(m Manifest) (config ImageConfig, err error) {
+	if !m.HasConfig() {
+		err = fmt.Errorf("Manifest has no config blob")
+		return
+	}
+
+	configURL := fmt.Sprintf("%s/v2/%s/blobs/%s", t.registry.URL, t.repository, m.Config.Digest)
+	log.Debugf("Getting config at URL %s", configURL)
+
+	resp, err := t.reqWithAuth("GET", configURL)
+	if err != nil {
+		log.Errorf("Failed to get config: %v", err)
+		return
+	}
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		log.Errorf("Error reading config: %v", err)
+		return
+	}
+
+	err = json.Unmarshal(body, &config)
+	if err != nil {
+		log.Errorf("Error unmarshalling config: %v", err)
+		return
+	}
+
 	return
 }
 
+// GetBlob streams a blob from the registry. The caller must close it. There's no overall
+// timeout as layers can be large, but reading fails if the registry stops sending data.
+func (t *TokenAuthClient) GetBlob(digest string) (blob io.ReadCloser, err error) {
+	blobURL := fmt.Sprintf("%s/v2/%s/blobs/%s", t.registry.URL, t.repository, digest)
+	log.Debugf("Getting blob at URL %s", blobURL)
+
+	// Cancelling the context stops the body being read
+	ctx, cancel := context.WithCancel(context.Background())
+
+	resp, err := t.reqWithAuthClient(ctx, t.service.blobClient, "GET", blobURL)
+	if err != nil {
+		log.Infof("Failed to get blob: %v", err)
+		if resp != nil {
+			resp.Body.Close()
+		}
+		cancel()
+		return
+	}
+
+	idle := t.service.blobIdleTimeout
+	return &idleTimeoutReader{body: resp.Body, timer: time.AfterFunc(idle, cancel), idle: idle, cancel: cancel}, nil
+}
+
+// idleTimeoutReader cancels the request if it has to wait too long for data
+type idleTimeoutReader struct {
+	body   io.ReadCloser
+	timer  *time.Timer
+	idle   time.Duration
+	cancel context.CancelFunc
+}
+
+func (r *idleTimeoutReader) Read(p []byte) (n int, err error) {
+	n, err = r.body.Read(p)
+	if n > 0 {
+		r.timer.Reset(r.idle)
+	}
+	return n, err
+}
+
+func (r *idleTimeoutReader) Close() error {
+	r.timer.Stop()
+	err := r.body.Close()
+	r.cancel()
+	return err
+}
+
 func (t *TokenAuthClient) getBlobDownloadSize(blobSum string) (size int64, err error) {
-	blobURL := fmt.Sprintf("%s/v2/%s/%s/blobs/%s", t.service.registryURL, t.org, t.image, blobSum)
+	blobURL := fmt.Sprintf("%s/v2/%s/blobs/%s", t.registry.URL, t.repository, blobSum)
 	log.Debugf("Getting blob at URL %s", blobURL)
 
 	// Make a HEAD request because only the response headers are needed.
@@ -298,10 +817,12 @@ func (t *TokenAuthClient) getBlobDownloadSize(blobSum string) (size int64, err e
 // GetImageDownloadSize gets the download size of the image. The total size is returned as well as an array
 // with the size of each layer. Only layers that affect the filesystem generate a new blob. If the blob already
 // exists for the image the layer size will be 0. The blobs are gzip compressed so the size on disk will be larger.
+// For schema 1 the layer sizes match FsLayers, and for schema 2 and OCI they match Layers.
 func (t *TokenAuthClient) GetImageDownloadSize(m Manifest) (size int64, layerSizes []int64, err error) {
 
-	if t.getRateLimited() {
-		return 0, nil, fmt.Errorf("Rate limited")
+	if m.HasConfig() {
+		size, layerSizes = getLayerDownloadSizes(m.Layers)
+		return
 	}
 
 	blobs := make(map[string]int64)
@@ -317,13 +838,7 @@ func (t *TokenAuthClient) GetImageDownloadSize(m Manifest) (size int64, layerSiz
 			// Blob is new so get the download size from the Registry API.
 			blobSize, err := t.getBlobDownloadSize(l.BlobSum)
 			if err != nil {
-				log.Infof("Error getting blob size for image %s/%s blob %s: %v", t.org, t.image, l.BlobSum, err)
-
-				if strings.Contains(err.Error(), "HAP429") {
-					log.Info("Rate limited")
-					t.setRateLimited()
-				}
-
+				log.Infof("Error getting blob size for image %s blob %s: %v", t.repository, l.BlobSum, err)
 				return 0, nil, err
 			}
 
@@ -339,27 +854,19 @@ func (t *TokenAuthClient) GetImageDownloadSize(m Manifest) (size int64, layerSiz
 	return
 }
 
-func (t *TokenAuthClient) getRateLimited() bool {
-	t.rl.RLock()
-	defer t.rl.RUnlock()
-
-	return t.rateLimited
-}
-
-func (t *TokenAuthClient) setRateLimited() {
-	t.rl.Lock()
-	defer t.rl.Unlock()
-
-	log.Debug("Setting rate limiter")
-	if t.rateLimited {
-		log.Fatal("Shouldn't call setRateLimited when already rate limited!!")
+// Schema 2 and OCI manifests already include the size of each layer so there's no
+// need to ask the registry. Layers that reuse an earlier blob have size 0.
+func getLayerDownloadSizes(layers []Descriptor) (size int64, layerSizes []int64) {
+	blobs := make(map[string]bool)
+	layerSizes = make([]int64, len(layers))
+
+	for i, l := range layers {
+		if !blobs[l.Digest] {
+			blobs[l.Digest] = true
+			layerSizes[i] = l.Size
+			size += l.Size
+		}
 	}
 
-	t.rateLimited = true
-	time.AfterFunc(time.Duration(t.rateLimitDelay)*time.Second, func() {
-		log.Debug("Unsetting rate limiter")
-		t.rl.Lock()
-		t.rateLimited = false
-		t.rl.Unlock()
-	})
+	return
 }
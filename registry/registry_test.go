@@ -2,11 +2,17 @@ package registry
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestDecode(t *testing.T) {
@@ -73,3 +79,565 @@ func TestRegistry(t *testing.T) {
 		t.Errorf("Unexpected number of tags %d", len(tags))
 	}
 }
+
+// Check that schema 2 manifests are requested and that we can get the config blob
+func TestManifestV2(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.String() {
+		case "http://fakeauth/token?service=fake.service&scope=repository:org/image:pull":
+			fmt.Fprintln(w, `{"token": "abctokenabc"}`)
+		case "http://fakereg/v2/org/image/manifests/tag1":
+			if !strings.Contains(r.Header.Get("Accept"), MediaTypeManifestV2) || !strings.Contains(r.Header.Get("Accept"), MediaTypeOCIManifest) {
+				t.Errorf("Unexpected accept header %s", r.Header.Get("Accept"))
+			}
+			w.Header().Set("Content-Type", MediaTypeManifestV2)
+			fmt.Fprintln(w, `{"schemaVersion": 2, "mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+				"config": {"mediaType": "application/vnd.docker.container.image.v1+json", "size": 1000, "digest": "sha256:abc123"},
+				"layers": [{"mediaType": "application/vnd.docker.image.rootfs.diff.tar.gzip", "size": 2000, "digest": "sha256:layer1"},
+				           {"mediaType": "application/vnd.docker.image.rootfs.diff.tar.gzip", "size": 300, "digest": "sha256:layer2"},
+				           {"mediaType": "application/vnd.docker.image.rootfs.diff.tar.gzip", "size": 300, "digest": "sha256:layer2"}]}`)
+		case "http://fakereg/v2/org/image/blobs/sha256:abc123":
+			fmt.Fprintln(w, `{"architecture": "amd64", "os": "linux", "created": "2020-03-23T21:19:34.196162891Z",
+				"config": {"Labels": {"org.label-schema.vcs-ref": "12345"}},
+				"history": [{"created": "2020-03-23T21:19:34.027725872Z", "created_by": "/bin/sh -c #(nop) ADD file:0c4555f363c2672e350001f1293e689875a3760afe7b3f9146886afe67121cba in / "},
+				            {"created": "2020-03-23T21:19:34.196162891Z", "created_by": "/bin/sh -c #(nop)  CMD [\"/bin/sh\"]", "empty_layer": true}]}`)
+		case "http://fakereg/v2/org/image/blobs/sha256:cutoff":
+			// The connection closes before all the config has been sent
+			w.Header().Set("Content-Length", "1000")
+			fmt.Fprint(w, `{"architecture": "amd64", "os": "linux"}`)
+		default:
+			t.Errorf("Unexpected request to %s", r.URL.String())
+		}
+	}))
+	defer server.Close()
+
+	transport := &http.Transport{
+		Proxy: func(req *http.Request) (*url.URL, error) {
+			return url.Parse(server.URL)
+		},
+	}
+
+	rs := NewMockService(transport, "http://fakeauth", "http://fakereg", "fake.service")
+	tac, err := NewTokenAuth(Image{Name: "org/image"}, &rs)
+	if err != nil {
+		t.Fatalf("Unexpectedly failed to get token: %v", err)
+	}
+
+	m, _, err := tac.GetManifest("tag1")
+	if err != nil {
+		t.Fatalf("Unexpectedly failed to get manifest: %v", err)
+	}
+
+	if !m.HasConfig() || len(m.Layers) != 3 {
+		t.Fatalf("Unexpected manifest %v", m)
+	}
+
+	config, err := tac.GetImageConfig(m)
+	if err != nil {
+		t.Fatalf("Unexpectedly failed to get config: %v", err)
+	}
+
+	if len(config.History) != 2 || !config.History[1].EmptyLayer {
+		t.Errorf("Unexpected history %v", config.History)
+	}
+
+	if string(config.Config.Labels) != `{"org.label-schema.vcs-ref": "12345"}` {
+		t.Errorf("Unexpected labels %s", config.Config.Labels)
+	}
+
+	cutoff := m
+	cutoff.Config.Digest = "sha256:cutoff"
+	_, err = tac.GetImageConfig(cutoff)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected an error reading the config, got %v", err)
+	}
+
+	// Sizes come from the manifest so there are no blob requests
+	size, layerSizes, err := tac.GetImageDownloadSize(m)
+	if err != nil {
+		t.Errorf("Unexpectedly failed to get size: %v", err)
+	}
+
+	if size != 2300 || len(layerSizes) != 3 || layerSizes[2] != 0 {
+		t.Errorf("Unexpected sizes %d %v", size, layerSizes)
+	}
+}
+
+func TestGetBlob(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.String() {
+		case "http://fakeauth/token?service=fake.service&scope=repository:org/image:pull":
+			fmt.Fprintln(w, `{"token": "abctokenabc"}`)
+		case "http://fakereg/v2/org/image/blobs/sha256:layer1":
+			if r.Header.Get("Authorization") != "Bearer abctokenabc" {
+				t.Errorf("Unexpected authorization header %s", r.Header.Get("Authorization"))
+			}
+			fmt.Fprint(w, "layer contents")
+		case "http://fakereg/v2/org/image/blobs/sha256:missing":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			t.Errorf("Unexpected request to %s", r.URL.String())
+		}
+	}))
+	defer server.Close()
+
+	transport := &http.Transport{
+		Proxy: func(req *http.Request) (*url.URL, error) {
+			return url.Parse(server.URL)
+		},
+	}
+
+	rs := NewMockService(transport, "http://fakeauth", "http://fakereg", "fake.service")
+	tac, err := NewTokenAuth(Image{Name: "org/image"}, &rs)
+	if err != nil {
+		t.Fatalf("Unexpectedly failed to get token: %v", err)
+	}
+
+	blob, err := tac.GetBlob("sha256:layer1")
+	if err != nil {
+		t.Fatalf("Unexpectedly failed to get blob: %v", err)
+	}
+
+	body, err := ioutil.ReadAll(blob)
+	blob.Close()
+	if err != nil || string(body) != "layer contents" {
+		t.Errorf("Unexpected blob %s: %v", body, err)
+	}
+
+	_, err = tac.GetBlob("sha256:missing")
+	if err == nil {
+		t.Errorf("Expected error getting missing blob")
+	}
+}
+
+// Blobs can take longer than the client timeout as long as data keeps arriving
+func TestGetBlobIdleTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.String() {
+		case "http://fakereg/v2/org/image/blobs/sha256:slow":
+			for i := 0; i < 5; i++ {
+				fmt.Fprint(w, "layer")
+				w.(http.Flusher).Flush()
+				time.Sleep(50 * time.Millisecond)
+			}
+		case "http://fakereg/v2/org/image/blobs/sha256:stalled":
+			fmt.Fprint(w, "layer")
+			w.(http.Flusher).Flush()
+			time.Sleep(time.Second)
+		default:
+			fmt.Fprintln(w, `{"token": "abctokenabc"}`)
+		}
+	}))
+	defer server.Close()
+
+	transport := &http.Transport{
+		Proxy: func(req *http.Request) (*url.URL, error) {
+			return url.Parse(server.URL)
+		},
+	}
+
+	rs := NewMockService(transport, "http://fakeauth", "http://fakereg", "fake.service")
+	rs.client.Timeout = 100 * time.Millisecond
+	tac, err := NewTokenAuth(Image{Name: "org/image"}, &rs)
+	if err != nil {
+		t.Fatalf("Unexpectedly failed to get token: %v", err)
+	}
+
+	blob, err := tac.GetBlob("sha256:slow")
+	if err != nil {
+		t.Fatalf("Unexpectedly failed to get blob: %v", err)
+	}
+
+	body, err := ioutil.ReadAll(blob)
+	blob.Close()
+	if err != nil || string(body) != "layerlayerlayerlayerlayer" {
+		t.Errorf("Unexpected blob %s: %v", body, err)
+	}
+
+	blob, err = tac.GetBlob("sha256:stalled")
+	if err != nil {
+		t.Fatalf("Unexpectedly failed to get blob: %v", err)
+	}
+
+	_, err = ioutil.ReadAll(blob)
+	blob.Close()
+	if err == nil {
+		t.Errorf("Expected an error when the registry stops sending data")
+	}
+}
+
+// Registries added after we started are loaded when we first see their images
+func TestRegistryLoader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.String() {
+		case "http://new.test/v2/":
+		case "http://new.test/v2/org/image/tags/list":
+			fmt.Fprintln(w, `{"name": "org/image", "tags": ["tag1"]}`)
+		default:
+			t.Errorf("Unexpected request to %s", r.URL.String())
+		}
+	}))
+	defer server.Close()
+
+	transport := &http.Transport{
+		Proxy: func(req *http.Request) (*url.URL, error) {
+			return url.Parse(server.URL)
+		},
+	}
+
+	rs := NewMockService(transport, "http://fakeauth", "http://fakereg", "fake.service")
+
+	_, err := NewTokenAuth(Image{Name: "new.test/org/image"}, &rs)
+	if err == nil {
+		t.Errorf("Expected an error for a registry we don't know about")
+	}
+
+	loads := 0
+	rs.SetRegistryLoader(func(host string) (Registry, bool) {
+		loads++
+		if host != "new.test" {
+			return Registry{}, false
+		}
+		return Registry{Host: host, URL: "http://new.test"}, true
+	})
+
+	tac, err := NewTokenAuth(Image{Name: "new.test/org/image"}, &rs)
+	if err != nil {
+		t.Fatalf("Unexpectedly failed to get client: %v", err)
+	}
+
+	tags, err := tac.GetTags()
+	if err != nil || len(tags) != 1 {
+		t.Errorf("Unexpected tags %v: %v", tags, err)
+	}
+
+	// Once it's loaded we don't need to look for it again
+	_, err = NewTokenAuth(Image{Name: "new.test/org/image"}, &rs)
+	if err != nil || loads != 1 {
+		t.Errorf("Expected registry to be loaded once, loaded %d times: %v", loads, err)
+	}
+
+	_, err = NewTokenAuth(Image{Name: "other.test/org/image"}, &rs)
+	if err == nil {
+		t.Errorf("Expected an error for a registry that isn't in the database")
+	}
+}
+
+// Check that the realm for other registries is discovered from the auth challenge
+func TestRegistryAuthDiscovery(t *testing.T) {
+	pings := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.String() {
+		case "http://ghcr.test/v2/":
+			pings++
+			w.Header().Set("WWW-Authenticate", `Bearer realm="http://ghcr.test/token",service="ghcr.test",scope="repository:user/image:pull,push"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		case "http://ghcr.test/token?service=ghcr.test&scope=repository:org/sub/image:pull":
+			fmt.Fprintln(w, `{"access_token": "abctokenabc"}`)
+		case "http://ghcr.test/v2/org/sub/image/tags/list":
+			if r.Header.Get("Authorization") != "Bearer abctokenabc" {
+				t.Errorf("Unexpected authorization header %s", r.Header.Get("Authorization"))
+			}
+			fmt.Fprintln(w, `{"name": "org/sub/image", "tags": ["tag1"]}`)
+		default:
+			t.Errorf("Unexpected request to %s", r.URL.String())
+		}
+	}))
+	defer server.Close()
+
+	transport := &http.Transport{
+		Proxy: func(req *http.Request) (*url.URL, error) {
+			return url.Parse(server.URL)
+		},
+	}
+
+	rs := NewMockService(transport, "http://fakeauth", "http://fakereg", "fake.service")
+	rs.AddRegistry(Registry{Host: "ghcr.test", URL: "http://ghcr.test/"})
+
+	// The challenge is only needed the first time
+	for i := 0; i < 2; i++ {
+		tac, err := NewTokenAuth(Image{Name: "ghcr.test/org/sub/image"}, &rs)
+		if err != nil {
+			t.Fatalf("Unexpectedly failed to get token: %v", err)
+		}
+
+		tags, err := tac.GetTags()
+		if err != nil || len(tags) != 1 {
+			t.Errorf("Unexpected tags %v: %v", tags, err)
+		}
+	}
+
+	if pings != 1 {
+		t.Errorf("Unexpected number of pings %d", pings)
+	}
+
+	_, err := NewTokenAuth(Image{Name: "quay.test/org/image"}, &rs)
+	if err == nil {
+		t.Errorf("Expected error for unsupported registry")
+	}
+}
+
+func TestParseChallenge(t *testing.T) {
+	scheme, params := parseChallenge(`Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:org/image:pull,push"`)
+	if scheme != "Bearer" || params["realm"] != "https://auth.docker.io/token" || params["service"] != "registry.docker.io" || params["scope"] != "repository:org/image:pull,push" {
+		t.Errorf("Unexpected challenge %s %v", scheme, params)
+	}
+
+	scheme, params = parseChallenge(`Basic realm=registry`)
+	if scheme != "Basic" || params["realm"] != "registry" {
+		t.Errorf("Unexpected challenge %s %v", scheme, params)
+	}
+}
+
+// Check that rate limited requests are retried and that clients for the same registry back off together
+func TestRateLimiting(t *testing.T) {
+	var requests int
+	var mu sync.Mutex
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.URL.String() {
+		case "http://fakeauth/token?service=fake.service&scope=repository:org/retry:pull",
+			"http://fakeauth/token?service=fake.service&scope=repository:org/backoff:pull",
+			"http://fakeauth/token?service=fake.service&scope=repository:org/limited:pull",
+			"http://fakeauth/token?service=fake.service&scope=repository:org/quota:pull":
+			fmt.Fprintln(w, `{"token": "abctokenabc"}`)
+		case "http://fakereg/v2/org/retry/tags/list":
+			// Rate limited twice, then OK
+			requests++
+			if requests <= 2 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			fmt.Fprintln(w, `{"name": "org/retry", "tags": ["tag1"]}`)
+		case "http://fakereg/v2/org/backoff/tags/list":
+			requests++
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "http://fakereg/v2/org/limited/tags/list":
+			requests++
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "http://fakereg/v2/org/quota/tags/list":
+			requests++
+			w.Header().Set("RateLimit-Remaining", "0;w=21600")
+			w.Header().Set("RateLimit-Reset", "3600")
+			fmt.Fprintln(w, `{"name": "org/quota", "tags": ["tag1"]}`)
+		default:
+			t.Errorf("Unexpected request to %s", r.URL.String())
+		}
+	}))
+	defer server.Close()
+
+	transport := &http.Transport{
+		Proxy: func(req *http.Request) (*url.URL, error) {
+			return url.Parse(server.URL)
+		},
+	}
+
+	getTags := func(rs *Service, name string) (tags []string, err error) {
+		tac, err := NewTokenAuth(Image{Name: name}, rs)
+		if err != nil {
+			t.Fatalf("Unexpectedly failed to get token: %v", err)
+		}
+
+		return tac.GetTags()
+	}
+
+	// Retry-After is respected and the request succeeds
+	rs := NewMockService(transport, "http://fakeauth", "http://fakereg", "fake.service")
+	tags, err := getTags(&rs, "org/retry")
+	if err != nil || len(tags) != 1 || requests != 3 {
+		t.Errorf("Unexpected result after %d requests: %v %v", requests, tags, err)
+	}
+
+	// Without any headers we use exponential backoff until we run out of retries
+	requests = 0
+	_, err = getTags(&rs, "org/backoff")
+	if !errors.Is(err, ErrRateLimited) || requests != constMaxRetries+1 {
+		t.Errorf("Unexpected result after %d requests: %v", requests, err)
+	}
+
+	// A long Retry-After fails straight away, for this and any other image in the registry
+	rs = NewMockService(transport, "http://fakeauth", "http://fakereg", "fake.service")
+	requests = 0
+	start := time.Now()
+	_, err = getTags(&rs, "org/limited")
+	if !errors.Is(err, ErrRateLimited) || requests != 1 {
+		t.Errorf("Unexpected result after %d requests: %v", requests, err)
+	}
+
+	_, err = getTags(&rs, "org/retry")
+	if !errors.Is(err, ErrRateLimited) || requests != 1 {
+		t.Errorf("Unexpected result after %d requests: %v", requests, err)
+	}
+
+	if time.Since(start) > time.Second {
+		t.Errorf("Shouldn't wait when the delay is too long")
+	}
+
+	// When there are no requests remaining we back off until the quota resets
+	rs = NewMockService(transport, "http://fakeauth", "http://fakereg", "fake.service")
+	requests = 0
+	_, err = getTags(&rs, "org/quota")
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	_, err = getTags(&rs, "org/quota")
+	if !errors.Is(err, ErrRateLimited) || requests != 1 {
+		t.Errorf("Unexpected result after %d requests: %v", requests, err)
+	}
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	if d, ok := parseRetryAfter("120"); !ok || d != 2*time.Minute {
+		t.Errorf("Unexpected Retry-After %v %t", d, ok)
+	}
+
+	if d, ok := parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)); !ok || d < 59*time.Minute || d > time.Hour {
+		t.Errorf("Unexpected Retry-After %v %t", d, ok)
+	}
+
+	if _, ok := parseRetryAfter("soon"); ok {
+		t.Errorf("Expected invalid Retry-After")
+	}
+
+	if n, ok := parseRateLimitHeader("76;w=21600"); !ok || n != 76 {
+		t.Errorf("Unexpected RateLimit header %d %t", n, ok)
+	}
+
+	if _, ok := parseRateLimitHeader(""); ok {
+		t.Errorf("Expected missing RateLimit header")
+	}
+
+	for retries := 0; retries < 10; retries++ {
+		d := getBackoff(retries, time.Second, time.Minute)
+		max := time.Second << uint(retries)
+		if max > time.Minute {
+			max = time.Minute
+		}
+
+		if d < max/2 || d > max {
+			t.Errorf("Unexpected backoff %v for %d retries", d, retries)
+		}
+	}
+}
+
+// Check that tokens are reused until they're about to expire
+func TestTokenCache(t *testing.T) {
+	var tokenRequests int
+	var issuedAt string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.String() {
+		case "http://fakeauth/token?service=fake.service&scope=repository:org/image:pull":
+			tokenRequests++
+			fmt.Fprintf(w, `{"token": "token%d", "expires_in": 300, "issued_at": "%s"}`, tokenRequests, issuedAt)
+		case "http://fakereg/v2/org/image/tags/list":
+			// The first token has been revoked
+			if r.Header.Get("Authorization") == "Bearer token1" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprintln(w, `{"name": "org/image", "tags": ["tag1"]}`)
+		default:
+			t.Errorf("Unexpected request to %s", r.URL.String())
+		}
+	}))
+	defer server.Close()
+
+	transport := &http.Transport{
+		Proxy: func(req *http.Request) (*url.URL, error) {
+			return url.Parse(server.URL)
+		},
+	}
+
+	rs := NewMockService(transport, "http://fakeauth", "http://fakereg", "fake.service")
+	issuedAt = time.Now().UTC().Format(time.RFC3339)
+
+	tac, err := NewTokenAuth(Image{Name: "org/image"}, &rs)
+	if err != nil || tac.token != "token1" {
+		t.Fatalf("Unexpected token %s: %v", tac.token, err)
+	}
+
+	// The revoked token is replaced
+	_, err = tac.GetTags()
+	if err != nil || tac.token != "token2" || tokenRequests != 2 {
+		t.Errorf("Unexpected token %s after %d requests: %v", tac.token, tokenRequests, err)
+	}
+
+	// Clients for the same image and credentials share the token
+	tac, err = NewTokenAuth(Image{Name: "org/image"}, &rs)
+	if err != nil || tac.token != "token2" || tokenRequests != 2 {
+		t.Errorf("Unexpected token %s after %d requests: %v", tac.token, tokenRequests, err)
+	}
+
+	// Different credentials need their own token
+	tac, err = NewTokenAuth(Image{Name: "org/image", User: "user", Password: "pass"}, &rs)
+	if err != nil || tac.token != "token3" || tokenRequests != 3 {
+		t.Errorf("Unexpected token %s after %d requests: %v", tac.token, tokenRequests, err)
+	}
+
+	// Tokens that are about to expire are refreshed before each request
+	issuedAt = time.Now().Add(-290 * time.Second).UTC().Format(time.RFC3339)
+	rs = NewMockService(transport, "http://fakeauth", "http://fakereg", "fake.service")
+	tokenRequests = 1
+
+	tac, err = NewTokenAuth(Image{Name: "org/image"}, &rs)
+	if err != nil || tac.token != "token2" {
+		t.Fatalf("Unexpected token %s: %v", tac.token, err)
+	}
+
+	_, err = tac.GetTags()
+	if err != nil || tac.token != "token3" || tokenRequests != 3 {
+		t.Errorf("Unexpected token %s after %d requests: %v", tac.token, tokenRequests, err)
+	}
+}
+
+// Check that expired tokens for images we don't see again are removed
+func TestTokenCacheSweep(t *testing.T) {
+	c := newTokenCache()
+	c.put("old", cachedToken{token: "old", refreshAt: time.Now().Add(-time.Second)})
+	c.put("current", cachedToken{token: "current", refreshAt: time.Now().Add(time.Minute)})
+
+	// Not swept again until the next sweep is due
+	c.put("new", cachedToken{token: "new", refreshAt: time.Now().Add(time.Minute)})
+	if len(c.tokens) != 3 {
+		t.Errorf("Expected 3 tokens before the sweep, have %d", len(c.tokens))
+	}
+
+	c.nextSweep = time.Now()
+	c.put("newer", cachedToken{token: "newer", refreshAt: time.Now().Add(time.Minute)})
+	if _, ok := c.tokens["old"]; ok || len(c.tokens) != 3 {
+		t.Errorf("Expected the expired token to be removed, have %v", c.tokens)
+	}
+}
+
+func TestGetRefreshTime(t *testing.T) {
+	now := time.Date(2020, 3, 23, 12, 0, 0, 0, time.UTC)
+
+	type test struct {
+		expiresIn int
+		issuedAt  string
+		expected  time.Time
+	}
+
+	tests := []test{
+		// Defaults to 60 seconds, refreshed after 45
+		{expected: now.Add(45 * time.Second)},
+		{expiresIn: 300, issuedAt: "2020-03-23T11:59:00Z", expected: now.Add(210 * time.Second)},
+		{expiresIn: 300, issuedAt: "2020-03-23T11:59:00.5Z", expected: now.Add(210*time.Second + 500*time.Millisecond)},
+		// Issue time in the future because of clock skew
+		{expiresIn: 300, issuedAt: "2020-03-23T12:05:00Z", expected: now.Add(270 * time.Second)},
+		{expiresIn: 300, issuedAt: "invalid", expected: now.Add(270 * time.Second)},
+	}
+
+	for id, tt := range tests {
+		if r := getRefreshTime(tt.expiresIn, tt.issuedAt, now); !r.Equal(tt.expected) {
+			t.Errorf("#%d Expected %v got %v", id, tt.expected, r)
+		}
+	}
+}
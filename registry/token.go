@@ -0,0 +1,107 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+const (
+	// Tokens last 60 seconds if the auth server doesn't say otherwise
+	constDefaultTokenExpiry = 60 * time.Second
+
+	// Tokens are refreshed this long before they expire, or after three quarters
+	// of their lifetime for tokens that don't last long
+	constTokenRefreshMargin = 30 * time.Second
+)
+
+// cachedToken is a bearer token and when it should be refreshed
+type cachedToken struct {
+	token     string
+	refreshAt time.Time
+}
+
+// tokenCache holds tokens for all the images we inspect so we don't need to get a new
+// one from the auth server every time. Expired tokens are swept out periodically so the
+// cache doesn't keep growing as we see more repositories.
+type tokenCache struct {
+	mu        sync.Mutex
+	tokens    map[string]cachedToken
+	nextSweep time.Time
+}
+
+func newTokenCache() *tokenCache {
+	return &tokenCache{tokens: make(map[string]cachedToken)}
+}
+
+// getTokenKey identifies tokens by realm, scope and credentials. We only keep a hash of the
+// credentials so the password isn't held in the cache.
+func getTokenKey(realm string, scope string, user string, password string) string {
+	creds := sha256.Sum256([]byte(user + ":" + password))
+	return realm + " " + scope + " " + hex.EncodeToString(creds[:])
+}
+
+// get returns the token for the key if it doesn't need refreshing yet
+func (c *tokenCache) get(key string) (cachedToken, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ct, ok := c.tokens[key]
+	if !ok {
+		return ct, false
+	}
+
+	if !time.Now().Before(ct.refreshAt) {
+		delete(c.tokens, key)
+		return ct, false
+	}
+
+	return ct, true
+}
+
+func (c *tokenCache) put(key string, ct cachedToken) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if !now.Before(c.nextSweep) {
+		for k, t := range c.tokens {
+			if !now.Before(t.refreshAt) {
+				delete(c.tokens, k)
+			}
+		}
+		c.nextSweep = now.Add(constDefaultTokenExpiry)
+	}
+
+	c.tokens[key] = ct
+}
+
+func (c *tokenCache) delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.tokens, key)
+}
+
+// getRefreshTime works out when to refresh a token using the expires_in and issued_at fields
+// of the token response. If our clock is behind the auth server we use the current time
+// rather than the issue time so tokens aren't kept for too long.
+func getRefreshTime(expiresIn int, issuedAt string, now time.Time) time.Time {
+	lifetime := constDefaultTokenExpiry
+	if expiresIn > 0 {
+		lifetime = time.Duration(expiresIn) * time.Second
+	}
+
+	issued := now
+	if t, err := time.Parse(time.RFC3339Nano, issuedAt); err == nil && t.Before(now) {
+		issued = t
+	}
+
+	margin := constTokenRefreshMargin
+	if lifetime/4 < margin {
+		margin = lifetime / 4
+	}
+
+	return issued.Add(lifetime - margin)
+}
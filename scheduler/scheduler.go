@@ -0,0 +1,140 @@
+package scheduler
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/op/go-logging"
+
+	"github.com/microscaling/microbadger/database"
+	"github.com/microscaling/microbadger/queue"
+	"github.com/microscaling/microbadger/utils"
+)
+
+const (
+	// Advisory lock key so that only one scheduler sends images at a time
+	constSchedulerLockKey = 0x6d627363
+
+	constDefaultScheduleInterval   = 60 // seconds - how often to look for images to re-inspect
+	constDefaultScheduleBatchSize  = 100
+	constDefaultSubscribedInterval = 6   // hours
+	constDefaultPopularInterval    = 24  // hours
+	constDefaultInterval           = 168 // hours
+	constDefaultPopularPullCount   = 10000
+)
+
+var (
+	log = logging.MustGetLogger("mbscheduler")
+)
+
+// Scheduler periodically sends images for re-inspection, so that images whose owners haven't
+// set up webhooks don't go stale
+type Scheduler struct {
+	db        database.PgDB
+	qs        queue.Service
+	policy    database.ReinspectionPolicy
+	interval  time.Duration
+	batchSize int
+}
+
+// NewScheduler creates a scheduler configured from the environment
+func NewScheduler(db database.PgDB, qs queue.Service) Scheduler {
+	return Scheduler{
+		db:        db,
+		qs:        qs,
+		policy:    getPolicy(),
+		interval:  time.Duration(getEnvInt("MB_SCHEDULE_INTERVAL", constDefaultScheduleInterval)) * time.Second,
+		batchSize: getEnvInt("MB_SCHEDULE_BATCH_SIZE", constDefaultScheduleBatchSize),
+	}
+}
+
+// getPolicy reads the re-inspection intervals (in hours) from the environment
+func getPolicy() database.ReinspectionPolicy {
+	return database.ReinspectionPolicy{
+		SubscribedInterval: time.Duration(getEnvInt("MB_SCHEDULE_SUBSCRIBED_HOURS", constDefaultSubscribedInterval)) * time.Hour,
+		PopularInterval:    time.Duration(getEnvInt("MB_SCHEDULE_POPULAR_HOURS", constDefaultPopularInterval)) * time.Hour,
+		DefaultInterval:    time.Duration(getEnvInt("MB_SCHEDULE_DEFAULT_HOURS", constDefaultInterval)) * time.Hour,
+		PopularPullCount:   getEnvInt("MB_SCHEDULE_POPULAR_PULLS", constDefaultPopularPullCount),
+	}
+}
+
+func getEnvInt(name string, defaultValue int) int {
+	value, err := strconv.Atoi(utils.GetEnvOrDefault(name, strconv.Itoa(defaultValue)))
+	if err != nil || value < 1 {
+		log.Errorf("Invalid value %s for %s, using %d", os.Getenv(name), name, defaultValue)
+		value = defaultValue
+	}
+
+	return value
+}
+
+// Run sends images for re-inspection until the stop channel is closed. Only the scheduler
+// that holds the advisory lock sends images, and the others wait to take over from it.
+func (s Scheduler) Run(stop <-chan struct{}) {
+	var lock *database.AdvisoryLock
+	var err error
+
+	log.Infof("Scheduling re-inspections every %v with policy %+v", s.interval, s.policy)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if lock != nil && !lock.Held() {
+			log.Errorf("Lost the scheduler lock")
+			lock.Release()
+			lock = nil
+		}
+
+		if lock == nil {
+			lock, err = s.db.TryAdvisoryLock(constSchedulerLockKey)
+			if err != nil {
+				log.Errorf("Failed to get the scheduler lock: %v", err)
+			} else if lock == nil {
+				log.Debugf("Another scheduler has the lock")
+			} else {
+				log.Infof("Got the scheduler lock")
+			}
+		}
+
+		if lock != nil {
+			s.scheduleImages()
+		}
+
+		select {
+		case <-stop:
+			if lock != nil {
+				lock.Release()
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// scheduleImages sends a batch of images that are due for re-inspection
+func (s Scheduler) scheduleImages() {
+	images, err := s.db.GetImagesDueForReinspection(s.policy, s.batchSize)
+	if err != nil {
+		log.Errorf("Failed to get images for re-inspection: %v", err)
+		return
+	}
+
+	var sent []string
+	for _, image := range images {
+		err = s.qs.SendImage(image, "Scheduled")
+		if err == nil {
+			sent = append(sent, image)
+		}
+	}
+
+	err = s.db.MarkImagesScheduled(sent)
+	if err != nil {
+		log.Errorf("Failed to mark images as scheduled: %v", err)
+	}
+
+	if len(sent) > 0 {
+		log.Infof("Scheduled %d images for re-inspection", len(sent))
+	}
+}
@@ -0,0 +1,31 @@
+package scheduler
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestGetPolicy(t *testing.T) {
+	p := getPolicy()
+	if p.SubscribedInterval != 6*time.Hour || p.PopularInterval != 24*time.Hour || p.DefaultInterval != 168*time.Hour || p.PopularPullCount != 10000 {
+		t.Errorf("Unexpected default policy %+v", p)
+	}
+
+	os.Setenv("MB_SCHEDULE_SUBSCRIBED_HOURS", "1")
+	os.Setenv("MB_SCHEDULE_POPULAR_PULLS", "500")
+	os.Setenv("MB_SCHEDULE_DEFAULT_HOURS", "never")
+	defer os.Unsetenv("MB_SCHEDULE_SUBSCRIBED_HOURS")
+	defer os.Unsetenv("MB_SCHEDULE_POPULAR_PULLS")
+	defer os.Unsetenv("MB_SCHEDULE_DEFAULT_HOURS")
+
+	p = getPolicy()
+	if p.SubscribedInterval != time.Hour || p.PopularPullCount != 500 {
+		t.Errorf("Policy not set from environment %+v", p)
+	}
+
+	// Invalid values fall back to the default
+	if p.DefaultInterval != 168*time.Hour {
+		t.Errorf("Expected default interval for invalid value, got %v", p.DefaultInterval)
+	}
+}
@@ -9,11 +9,62 @@ import (
 	logging "github.com/op/go-logging"
 )
 
+// DockerHubHost is the registry host for images that don't include one in their name.
+const DockerHubHost = "docker.io"
+
+// Other hosts that are used in the names of Docker Hub images
+var dockerHubAliases = map[string]bool{
+	"index.docker.io":         true,
+	"registry-1.docker.io":    true,
+	"registry.hub.docker.com": true,
+}
+
 var (
 	log                = logging.MustGetLogger("microbadger")
 	loggingInitialized bool
 )
 
+// ParseImageName splits an image name into the registry host and the repository. As with
+// the Docker CLI the first part of the name is only a host if it contains a . or : or is
+// localhost. Otherwise the image is on Docker Hub and official images are in the library org.
+func ParseImageName(name string) (host string, repository string) {
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		host = parts[0]
+		repository = parts[1]
+		if dockerHubAliases[host] {
+			host = DockerHubHost
+		}
+	} else {
+		host = DockerHubHost
+		repository = name
+	}
+
+	if host == DockerHubHost && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+
+	return host, repository
+}
+
+// NormalizeImageName gets the name we store for the image, so that each image has one name
+// however the registry host is written. As with the Docker CLI, Docker Hub images are named
+// without the host.
+func NormalizeImageName(name string) string {
+	host, repository := ParseImageName(name)
+	if host == DockerHubHost {
+		return repository
+	}
+
+	return host + "/" + repository
+}
+
+// IsDockerHubImage is true if the image name doesn't include the host of another registry.
+func IsDockerHubImage(name string) bool {
+	host, _ := ParseImageName(name)
+	return host == DockerHubHost
+}
+
 // ParseDockerImage splits input into Docker org, image and tag portions. If no org is
 // provided the default library org is returned. If no tag is provided the latest tag is
 // returned.
@@ -32,3 +32,53 @@ func TestBadgesInstalled(t *testing.T) {
 		}
 	}
 }
+
+func TestParseImageName(t *testing.T) {
+	type test struct {
+		name       string
+		host       string
+		repository string
+	}
+
+	tests := []test{
+		{name: "alpine", host: "docker.io", repository: "library/alpine"},
+		{name: "library/alpine", host: "docker.io", repository: "library/alpine"},
+		{name: "lizrice/imagetest", host: "docker.io", repository: "lizrice/imagetest"},
+		{name: "docker.io/lizrice/imagetest", host: "docker.io", repository: "lizrice/imagetest"},
+		{name: "docker.io/alpine", host: "docker.io", repository: "library/alpine"},
+		{name: "index.docker.io/lizrice/imagetest", host: "docker.io", repository: "lizrice/imagetest"},
+		{name: "registry-1.docker.io/library/alpine", host: "docker.io", repository: "library/alpine"},
+		{name: "ghcr.io/microscaling/microbadger", host: "ghcr.io", repository: "microscaling/microbadger"},
+		{name: "registry.gitlab.com/group/subgroup/project", host: "registry.gitlab.com", repository: "group/subgroup/project"},
+		{name: "localhost/myimage", host: "localhost", repository: "myimage"},
+		{name: "registry:5000/myimage", host: "registry:5000", repository: "myimage"},
+	}
+
+	for id, tt := range tests {
+		host, repository := ParseImageName(tt.name)
+		if host != tt.host || repository != tt.repository {
+			t.Errorf("#%d Unexpected host %s and repository %s for %s", id, host, repository, tt.name)
+		}
+
+		if IsDockerHubImage(tt.name) != (tt.host == DockerHubHost) {
+			t.Errorf("#%d Unexpected IsDockerHubImage for %s", id, tt.name)
+		}
+	}
+}
+
+func TestNormalizeImageName(t *testing.T) {
+	tests := map[string]string{
+		"alpine":                            "library/alpine",
+		"docker.io/alpine":                  "library/alpine",
+		"index.docker.io/lizrice/imagetest": "lizrice/imagetest",
+		"lizrice/imagetest":                 "lizrice/imagetest",
+		"ghcr.io/microscaling/microbadger":  "ghcr.io/microscaling/microbadger",
+		"registry:5000/myimage":             "registry:5000/myimage",
+	}
+
+	for name, expected := range tests {
+		if n := NormalizeImageName(name); n != expected {
+			t.Errorf("Expected %s for %s, got %s", expected, name, n)
+		}
+	}
+}